@@ -0,0 +1,422 @@
+package bitbang
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestI2C(t *testing.T) {
+	dev := &i2cDevice{sda: 2, scl: 3, addr: 0x48, prevSDA: 1, prevSCL: 1}
+	dev.regs[1], dev.regs[2] = 0x12, 0x34
+	wire := newFakeWire(dev)
+
+	_, err := CreateI2C(wire, "1", I2CConfig{SDA: 2, SCL: 2})
+	assert.Equal(t, bus.ErrInvalidConfig, err)
+	_, err = CreateI2C(wire, "1", I2CConfig{SDA: 2, SCL: 3, Frequency: 1 << 31})
+	assert.Equal(t, bus.ErrInvalidConfig, err)
+
+	i2c, err := CreateI2C(wire, "1", I2CConfig{SDA: 2, SCL: 3, Frequency: 1000000})
+	require.NoError(t, err)
+
+	t.Run("claimed pins", func(t *testing.T) {
+		assert.Equal(t, map[int]gpio.Claim{
+			2: {Owners: []string{"i2c:1"}, Exclusive: true},
+			3: {Owners: []string{"i2c:1"}, Exclusive: true},
+		}, wire.ListClaims())
+		assert.Equal(t, gpio.ErrPinClaimed, wire.SetDirection(2, gpio.Output))
+		assert.Equal(t, gpio.ErrPinClaimed, wire.UnexportPin(3))
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		assert.Equal(t, bus.ErrInvalidValue, i2c.Tx(0x80, []byte{1}, nil))
+	})
+
+	t.Run("read registers", func(t *testing.T) {
+		r := make([]byte, 2)
+		require.NoError(t, i2c.Tx(0x48, []byte{1}, r))
+		assert.Equal(t, []byte{0x12, 0x34}, r)
+	})
+
+	t.Run("write registers", func(t *testing.T) {
+		require.NoError(t, i2c.Tx(0x48, []byte{0x10, 0xab, 0xcd}, nil))
+		assert.Equal(t, byte(0xab), dev.regs[0x10])
+		assert.Equal(t, byte(0xcd), dev.regs[0x11])
+	})
+
+	t.Run("missing device", func(t *testing.T) {
+		assert.Equal(t, bus.ErrNack, i2c.Tx(0x20, []byte{1}, nil))
+		r := make([]byte, 1)
+		assert.NoError(t, i2c.Tx(0x48, []byte{0x11}, r))
+		assert.Equal(t, []byte{0xcd}, r)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		assert.NoError(t, i2c.Close())
+		assert.Equal(t, bus.ErrClosed, i2c.Tx(0x48, []byte{1}, nil))
+		assert.Empty(t, wire.Exported())
+		assert.Empty(t, wire.ListClaims())
+	})
+}
+
+func TestSPI(t *testing.T) {
+	pins := SPIPins{Clock: 11, MOSI: 10, MISO: 9, ChipSelect: 8}
+
+	_, err := CreateSPI(newFakeWire(nil), "0.0", SPIPins{Clock: 11, MOSI: 10, MISO: 10, ChipSelect: NoPin}, bus.SPIConfig{})
+	assert.Equal(t, bus.ErrInvalidConfig, err)
+
+	for _, mode := range []bus.SPIMode{bus.Mode0, bus.Mode1, bus.Mode2, bus.Mode3} {
+		dev := &spiDevice{pins: pins, mode: mode, out: []byte{0xa5, 0x3c}, prevCS: -1}
+		wire := newFakeWire(dev)
+
+		spi, err := CreateSPI(wire, "0.0", pins, bus.SPIConfig{Mode: mode, SpeedHz: 1000000})
+		require.NoError(t, err)
+
+		r := make([]byte, 2)
+		require.NoError(t, spi.Tx([]byte{0x81, 0x7e}, r))
+		assert.Equal(t, []byte{0xa5, 0x3c}, r, "mode %d", mode)
+		assert.Equal(t, []byte{0x81, 0x7e}, dev.in, "mode %d", mode)
+		assert.Equal(t, 1, wire.Value(pins.ChipSelect))
+		assert.Equal(t, mode.CPOL(), wire.Value(pins.Clock))
+
+		assert.Equal(t, bus.ErrInvalidValue, spi.Tx([]byte{1, 2}, make([]byte, 1)))
+		assert.Equal(t, bus.ErrInvalidConfig, spi.Configure(bus.SPIConfig{BitsPerWord: 16}))
+		assert.Equal(t, bus.ErrInvalidConfig, spi.Configure(bus.SPIConfig{SpeedHz: 1 << 31}))
+		assert.NoError(t, spi.Close())
+	}
+}
+
+func TestAlreadyExportedPins(t *testing.T) {
+	wire := newFakeWire(nil)
+	require.NoError(t, wire.ExportPin(2, gpio.Output))
+	require.NoError(t, wire.ExportPin(4, gpio.Input))
+
+	_, err := CreateI2C(wire, "1", I2CConfig{SDA: 2, SCL: 3})
+	assert.Equal(t, gpio.ErrInvalidDirection, err)
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Output, 4: gpio.Input}, wire.Exported())
+
+	ow, err := CreateOneWire(wire, "4", OneWireConfig{Pin: 4, Timing: slowTiming})
+	require.NoError(t, err)
+	assert.NoError(t, ow.Close())
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Output, 4: gpio.Input}, wire.Exported())
+}
+
+func TestParse(t *testing.T) {
+	number, cfg, err := ParseI2C("3:23:24")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, number)
+	assert.Equal(t, I2CConfig{SDA: 23, SCL: 24}, cfg)
+	_, cfg, err = ParseI2C("3:23:24:400000")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(400000), cfg.Frequency)
+	for _, spec := range []string{"", "3:23", "3:23:-1", "3:a:24", "3:23:24:fast", "3:23:24:1:2"} {
+		_, _, err = ParseI2C(spec)
+		assert.Equal(t, bus.ErrInvalidConfig, err, spec)
+	}
+
+	name, pins, err := ParseSPI("2.0:11:10::8")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0", name)
+	assert.Equal(t, SPIPins{Clock: 11, MOSI: 10, MISO: NoPin, ChipSelect: 8}, pins)
+	for _, spec := range []string{"", "2.0:11:10:9", ":11:10:9:8", "2.0::10:9:8", "2.0:11:x:9:8"} {
+		_, _, err = ParseSPI(spec)
+		assert.Equal(t, bus.ErrInvalidConfig, err, spec)
+	}
+
+	ow, err := ParseOneWire("4")
+	assert.NoError(t, err)
+	assert.Equal(t, OneWireConfig{Pin: 4}, ow)
+	_, err = ParseOneWire("")
+	assert.Equal(t, bus.ErrInvalidConfig, err)
+}
+
+// slowTiming is used in tests to make slot decoding independent of scheduling latency
+var slowTiming = OneWireTiming{
+	ResetLow:      10 * DefaultOneWireTiming.ResetLow,
+	PresenceDelay: 10 * DefaultOneWireTiming.PresenceDelay,
+	ResetRecovery: 10 * DefaultOneWireTiming.ResetRecovery,
+	WriteOneLow:   10 * DefaultOneWireTiming.WriteOneLow,
+	WriteZeroLow:  10 * DefaultOneWireTiming.WriteZeroLow,
+	ReadDelay:     10 * DefaultOneWireTiming.ReadDelay,
+	Slot:          10 * DefaultOneWireTiming.Slot,
+}
+
+func TestOneWire(t *testing.T) {
+	t.Run("no device", func(t *testing.T) {
+		ow, err := CreateOneWire(newFakeWire(nil), "4", OneWireConfig{Pin: 4, Timing: slowTiming})
+		require.NoError(t, err)
+		assert.Equal(t, bus.ErrNoDevice, ow.Reset())
+		assert.NoError(t, ow.Close())
+	})
+
+	t.Run("reset, write and read", func(t *testing.T) {
+		dev := &oneWireDevice{pin: 4, timing: slowTiming, response: []byte{0x50, 0x05}}
+		ow, err := CreateOneWire(newFakeWire(dev), "4", OneWireConfig{Pin: 4, Timing: slowTiming})
+		require.NoError(t, err)
+
+		require.NoError(t, ow.Reset())
+		r := make([]byte, 2)
+		require.NoError(t, ow.Tx([]byte{0xcc, 0xbe}, r))
+		assert.Equal(t, []byte{0x50, 0x05}, r)
+		// read slots look like writing ones on the wire
+		assert.Equal(t, []byte{0xcc, 0xbe, 0xff, 0xff}, dev.written)
+		assert.NoError(t, ow.Close())
+	})
+}
+
+// device emulates slave reacting to line changes made by bus master
+type device interface {
+	changed(fw *fakeWire)
+	sample(fw *fakeWire, pin int) int
+}
+
+// fakeWire connects emulated device to pins of fake controller
+type fakeWire struct {
+	*gpiotest.Fake
+	dev device
+}
+
+func newFakeWire(dev device) *fakeWire {
+	fw := &fakeWire{Fake: gpiotest.NewFake(), dev: dev}
+	fw.OnWrite(func(pin, value int) { fw.changed() })
+	fw.OnDirection(func(pin int, mode gpio.Direction) { fw.changed() })
+	// released line is pulled up if there is no device driving it
+	fw.OnRead(func(pin int) int {
+		if fw.dev == nil {
+			return 1
+		}
+		return fw.dev.sample(fw, pin)
+	})
+	return fw
+}
+
+// drivenLow returns true if master drives pin low
+func (fw *fakeWire) drivenLow(pin int) bool {
+	return fw.Exported()[pin] == gpio.Output && fw.Value(pin) == 0
+}
+
+func (fw *fakeWire) changed() {
+	if fw.dev != nil {
+		fw.dev.changed(fw)
+	}
+}
+
+const (
+	i2cIdle = iota
+	i2cAddress
+	i2cWrite
+	i2cRead
+)
+
+// i2cDevice emulates register based I2C slave, first written byte sets register pointer
+type i2cDevice struct {
+	sda, scl int
+	addr     byte
+	regs     [256]byte
+	ptr      byte
+
+	prevSDA, prevSCL int
+	state            int
+	bit              int
+	rx, tx           byte
+	pointerSet       bool
+	masterAck        bool
+	clocked          bool
+	drive            bool
+}
+
+func (d *i2cDevice) level(fw *fakeWire, pin int) int {
+	if fw.drivenLow(pin) || (pin == d.sda && d.drive) {
+		return 0
+	}
+	return 1
+}
+
+func (d *i2cDevice) sample(fw *fakeWire, pin int) int {
+	return d.level(fw, pin)
+}
+
+func (d *i2cDevice) changed(fw *fakeWire) {
+	sda, scl := d.level(fw, d.sda), d.level(fw, d.scl)
+	switch {
+	case scl != d.prevSCL && scl == 1:
+		d.rising(sda)
+	case scl != d.prevSCL:
+		d.falling()
+	case scl == 1 && sda != d.prevSDA && sda == 0:
+		d.state, d.bit, d.rx, d.drive, d.clocked = i2cAddress, 0, 0, false, false
+	case scl == 1 && sda != d.prevSDA:
+		d.state, d.drive = i2cIdle, false
+	}
+	d.prevSDA, d.prevSCL = d.level(fw, d.sda), d.level(fw, d.scl)
+}
+
+func (d *i2cDevice) rising(sda int) {
+	if d.state == i2cIdle {
+		return
+	}
+	d.clocked = true
+	if d.bit < 8 && d.state != i2cRead {
+		d.rx = d.rx<<1 | byte(sda)
+	} else if d.bit == 8 && d.state == i2cRead {
+		d.masterAck = sda == 0
+	}
+}
+
+// falling handles end of clock pulse, SCL falling right after start condition is ignored
+func (d *i2cDevice) falling() {
+	if d.state == i2cIdle || !d.clocked {
+		return
+	}
+	d.clocked = false
+	switch {
+	case d.bit < 7:
+		d.bit++
+		if d.state == i2cRead {
+			d.drive = d.tx>>uint(7-d.bit)&1 == 0
+		}
+	case d.bit == 7:
+		d.bit = 8
+		switch d.state {
+		case i2cAddress:
+			if d.rx>>1 != d.addr {
+				d.state, d.drive = i2cIdle, false
+				return
+			}
+			d.state, d.masterAck, d.pointerSet = i2cWrite, true, false
+			if d.rx&1 == 1 {
+				d.state = i2cRead
+			}
+			d.drive = true
+		case i2cWrite:
+			if d.pointerSet {
+				d.regs[d.ptr] = d.rx
+				d.ptr++
+			} else {
+				d.ptr, d.pointerSet = d.rx, true
+			}
+			d.drive = true
+		case i2cRead:
+			d.drive = false
+		}
+	default:
+		d.bit, d.rx, d.drive = 0, 0, false
+		if d.state != i2cRead {
+			return
+		}
+		if !d.masterAck {
+			d.state = i2cIdle
+			return
+		}
+		d.tx = d.regs[d.ptr]
+		d.ptr++
+		d.drive = d.tx&0x80 == 0
+	}
+}
+
+// spiDevice emulates SPI slave sending out bytes and storing received ones
+type spiDevice struct {
+	pins SPIPins
+	mode bus.SPIMode
+	out  []byte
+	in   []byte
+
+	bits      int
+	misoLevel int
+	prevCS    int
+	prevClock int
+}
+
+func (d *spiDevice) bitAt(n int) int {
+	if n/8 >= len(d.out) {
+		return 0
+	}
+	return int(d.out[n/8]>>uint(7-n%8)) & 1
+}
+
+func (d *spiDevice) sample(fw *fakeWire, pin int) int {
+	return d.misoLevel
+}
+
+func (d *spiDevice) changed(fw *fakeWire) {
+	cs, clock := fw.Value(d.pins.ChipSelect), fw.Value(d.pins.Clock)
+	if cs != d.prevCS {
+		if cs == 0 {
+			d.bits = 0
+			if d.mode.CPHA() == 0 {
+				d.misoLevel = d.bitAt(0)
+			}
+		}
+	} else if cs == 0 && clock != d.prevClock {
+		leading := clock != d.mode.CPOL()
+		if leading == (d.mode.CPHA() == 0) {
+			if d.bits%8 == 0 {
+				d.in = append(d.in, 0)
+			}
+			d.in[len(d.in)-1] |= byte(fw.Value(d.pins.MOSI)) << uint(7-d.bits%8)
+			d.bits++
+		} else {
+			d.misoLevel = d.bitAt(d.bits)
+		}
+	}
+	d.prevCS, d.prevClock = cs, clock
+}
+
+// oneWireDevice emulates 1-Wire slave decoding slots by low pulse length
+type oneWireDevice struct {
+	pin      int
+	timing   OneWireTiming
+	response []byte
+	written  []byte
+
+	low      bool
+	lowSince time.Time
+	presence bool
+	bits     int
+	sent     int
+}
+
+func (d *oneWireDevice) sample(fw *fakeWire, pin int) int {
+	if d.presence {
+		d.presence = false
+		return 0
+	}
+	if d.sent >= 8*len(d.response) {
+		return 1
+	}
+	bit := int(d.response[d.sent/8]>>uint(d.sent%8)) & 1
+	d.sent++
+	return bit
+}
+
+func (d *oneWireDevice) changed(fw *fakeWire) {
+	low := fw.drivenLow(d.pin)
+	if low == d.low {
+		return
+	}
+	d.low = low
+	if low {
+		d.lowSince = time.Now()
+		return
+	}
+
+	width := time.Since(d.lowSince)
+	if width > (d.timing.WriteZeroLow+d.timing.ResetLow)/2 {
+		d.presence, d.bits, d.sent, d.written = true, 0, 0, nil
+		return
+	}
+	bit := byte(0)
+	if width < (d.timing.WriteOneLow+d.timing.WriteZeroLow)/2 {
+		bit = 1
+	}
+	if d.bits%8 == 0 {
+		d.written = append(d.written, 0)
+	}
+	d.written[len(d.written)-1] |= bit << uint(d.bits%8)
+	d.bits++
+}
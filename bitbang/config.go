@@ -0,0 +1,69 @@
+package bitbang
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/markamdev/repico/bus"
+)
+
+// ParseI2C parses software I2C bus description in "number:sda:scl[:frequency]" format
+func ParseI2C(spec string) (int, I2CConfig, error) {
+	fields := strings.Split(spec, ":")
+	if len(fields) != 3 && len(fields) != 4 {
+		return 0, I2CConfig{}, bus.ErrInvalidConfig
+	}
+	pins, err := parsePins(fields[:3], false)
+	if err != nil {
+		return 0, I2CConfig{}, err
+	}
+	cfg := I2CConfig{SDA: pins[1], SCL: pins[2]}
+	if len(fields) == 4 {
+		frequency, err := strconv.ParseUint(fields[3], 10, 32)
+		if err != nil {
+			return 0, I2CConfig{}, bus.ErrInvalidConfig
+		}
+		cfg.Frequency = uint32(frequency)
+	}
+	return pins[0], cfg, nil
+}
+
+// ParseSPI parses software SPI device description in "bus.chipselect:clock:mosi:miso:cs" format,
+// device name is returned as is and unused optional pins are left empty (ex. "2.0:11:10::8")
+func ParseSPI(spec string) (string, SPIPins, error) {
+	fields := strings.Split(spec, ":")
+	if len(fields) != 5 || fields[0] == "" || fields[1] == "" {
+		return "", SPIPins{}, bus.ErrInvalidConfig
+	}
+	pins, err := parsePins(fields[1:], true)
+	if err != nil {
+		return "", SPIPins{}, err
+	}
+	return fields[0], SPIPins{Clock: pins[0], MOSI: pins[1], MISO: pins[2], ChipSelect: pins[3]}, nil
+}
+
+// ParseOneWire parses software 1-Wire bus description (pin number), default timings are used
+func ParseOneWire(spec string) (OneWireConfig, error) {
+	pins, err := parsePins([]string{spec}, false)
+	if err != nil {
+		return OneWireConfig{}, err
+	}
+	return OneWireConfig{Pin: pins[0]}, nil
+}
+
+// parsePins converts fields to numbers, empty fields are converted to NoPin if optional is set
+func parsePins(fields []string, optional bool) ([]int, error) {
+	result := make([]int, len(fields))
+	for i, field := range fields {
+		if field == "" && optional {
+			result[i] = NoPin
+			continue
+		}
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 {
+			return nil, bus.ErrInvalidConfig
+		}
+		result[i] = number
+	}
+	return result, nil
+}
@@ -0,0 +1,227 @@
+package bitbang
+
+import (
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+type i2c struct {
+	ctrl    gpio.Owned
+	sda     openDrain
+	scl     openDrain
+	half    time.Duration
+	claimed map[int]bool
+
+	mtx    sync.Mutex
+	closed bool
+}
+
+func (b *i2c) Tx(addr uint16, w, r []byte) error {
+	logrus.Traceln("bitbang.i2c.Tx()")
+	if addr > 0x7f || (len(w) == 0 && len(r) == 0) {
+		return bus.ErrInvalidValue
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+
+	err := b.transfer(byte(addr), w, r)
+	stopErr := b.stop()
+	if err != nil {
+		return err
+	}
+	return stopErr
+}
+
+func (b *i2c) transfer(addr byte, w, r []byte) error {
+	if len(w) > 0 {
+		err := b.start()
+		if err != nil {
+			return err
+		}
+		err = b.writeByte(addr << 1)
+		for i := 0; err == nil && i < len(w); i++ {
+			err = b.writeByte(w[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(r) > 0 {
+		err := b.start()
+		if err != nil {
+			return err
+		}
+		err = b.writeByte(addr<<1 | 1)
+		if err != nil {
+			return err
+		}
+		for i := range r {
+			r[i], err = b.readByte(i < len(r)-1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *i2c) Close() error {
+	logrus.Traceln("bitbang.i2c.Close()")
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+	b.closed = true
+	return gpio.ReleasePins(b.ctrl, b.claimed)
+}
+
+// start generates (repeated) start condition leaving SCL low
+func (b *i2c) start() error {
+	err := b.sda.release()
+	if err == nil {
+		err = b.releaseClock()
+	}
+	if err != nil {
+		return err
+	}
+	wait(b.half)
+	err = b.sda.low()
+	if err != nil {
+		return err
+	}
+	wait(b.half)
+	return b.scl.low()
+}
+
+// stop generates stop condition leaving both lines released
+func (b *i2c) stop() error {
+	err := b.sda.low()
+	if err != nil {
+		return err
+	}
+	wait(b.half)
+	err = b.releaseClock()
+	if err != nil {
+		return err
+	}
+	wait(b.half)
+	return b.sda.release()
+}
+
+// releaseClock releases SCL and waits until slave stops stretching it
+func (b *i2c) releaseClock() error {
+	err := b.scl.release()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(stretchTimeout)
+	for {
+		value, err := b.scl.read()
+		if err != nil {
+			return err
+		}
+		if value == 1 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return bus.ErrTimeout
+		}
+	}
+}
+
+func (b *i2c) writeBit(bit int) error {
+	err := b.sda.set(bit)
+	if err != nil {
+		return err
+	}
+	wait(b.half)
+	err = b.releaseClock()
+	if err != nil {
+		return err
+	}
+	wait(b.half)
+	return b.scl.low()
+}
+
+func (b *i2c) readBit() (int, error) {
+	err := b.sda.release()
+	if err != nil {
+		return 0, err
+	}
+	wait(b.half)
+	err = b.releaseClock()
+	if err != nil {
+		return 0, err
+	}
+	wait(b.half)
+	bit, err := b.sda.read()
+	if err != nil {
+		return 0, err
+	}
+	return bit, b.scl.low()
+}
+
+// writeByte sends byte MSB first and returns ErrNack if slave did not acknowledge it
+func (b *i2c) writeByte(value byte) error {
+	for i := 7; i >= 0; i-- {
+		err := b.writeBit(int(value>>uint(i)) & 1)
+		if err != nil {
+			return err
+		}
+	}
+	nack, err := b.readBit()
+	if err != nil {
+		return err
+	}
+	if nack == 1 {
+		return bus.ErrNack
+	}
+	return nil
+}
+
+// readByte receives byte MSB first and acknowledges it if more bytes are expected
+func (b *i2c) readByte(ack bool) (byte, error) {
+	value := byte(0)
+	for i := 0; i < 8; i++ {
+		bit, err := b.readBit()
+		if err != nil {
+			return 0, err
+		}
+		value = value<<1 | byte(bit)
+	}
+	nack := 1
+	if ack {
+		nack = 0
+	}
+	return value, b.writeBit(nack)
+}
+
+// CreateI2C creates software I2C master on given pins claimed as "i2c:{name}" owner,
+// both lines are released on start
+func CreateI2C(ctrl gpio.Claims, name string, cfg I2CConfig) (bus.I2C, error) {
+	logrus.Traceln("bitbang.CreateI2C()")
+	if cfg.Frequency == 0 {
+		cfg.Frequency = DefaultI2CFrequency
+	}
+	if cfg.SDA < 0 || cfg.SCL < 0 || cfg.SDA == cfg.SCL || cfg.Frequency > MaxFrequency {
+		return nil, bus.ErrInvalidConfig
+	}
+
+	owned := ctrl.WithOwner(gpio.DriverOwner(kindI2C, name))
+	claimed, err := gpio.ClaimPins(owned, map[int]gpio.Direction{cfg.SDA: gpio.Input, cfg.SCL: gpio.Input})
+	if err != nil {
+		return nil, err
+	}
+	return &i2c{ctrl: owned, sda: openDrain{ctrl: owned, pin: cfg.SDA}, scl: openDrain{ctrl: owned, pin: cfg.SCL},
+		half: halfPeriod(cfg.Frequency), claimed: claimed}, nil
+}
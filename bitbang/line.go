@@ -0,0 +1,47 @@
+package bitbang
+
+import (
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+)
+
+// wait busy-waits given time as sleeping has too coarse resolution for bus timings
+func wait(d time.Duration) {
+	deadline := time.Now().Add(d)
+	for time.Now().Before(deadline) {
+	}
+}
+
+// halfPeriod returns half of clock period for given frequency,
+// computed as time.Duration so doubling frequency can't overflow
+func halfPeriod(frequency uint32) time.Duration {
+	return time.Second / (2 * time.Duration(frequency))
+}
+
+// openDrain emulates open-drain output with pull-up on top of GPIO pin:
+// line is driven low by switching pin to output and released by switching it to input
+type openDrain struct {
+	ctrl gpio.Controller
+	pin  int
+}
+
+func (od openDrain) low() error {
+	return od.ctrl.SetDirection(od.pin, gpio.Output)
+}
+
+func (od openDrain) release() error {
+	return od.ctrl.SetDirection(od.pin, gpio.Input)
+}
+
+func (od openDrain) read() (int, error) {
+	return od.ctrl.GetValue(od.pin)
+}
+
+// set drives line low for 0 and releases it for 1
+func (od openDrain) set(bit int) error {
+	if bit == 0 {
+		return od.low()
+	}
+	return od.release()
+}
@@ -0,0 +1,147 @@
+package bitbang
+
+import (
+	"sync"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+type oneWire struct {
+	ctrl    gpio.Owned
+	line    openDrain
+	timing  OneWireTiming
+	claimed map[int]bool
+
+	mtx    sync.Mutex
+	closed bool
+}
+
+func (b *oneWire) Reset() error {
+	logrus.Traceln("bitbang.oneWire.Reset()")
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+
+	err := b.line.low()
+	if err != nil {
+		return err
+	}
+	wait(b.timing.ResetLow)
+	err = b.line.release()
+	if err != nil {
+		return err
+	}
+	wait(b.timing.PresenceDelay)
+	presence, err := b.line.read()
+	if err != nil {
+		return err
+	}
+	wait(b.timing.ResetRecovery)
+	if presence != 0 {
+		return bus.ErrNoDevice
+	}
+	return nil
+}
+
+func (b *oneWire) Tx(w, r []byte) error {
+	logrus.Traceln("bitbang.oneWire.Tx()")
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+
+	for _, value := range w {
+		for i := 0; i < 8; i++ {
+			err := b.writeBit(int(value>>uint(i)) & 1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for n := range r {
+		value := byte(0)
+		for i := 0; i < 8; i++ {
+			bit, err := b.readBit()
+			if err != nil {
+				return err
+			}
+			value |= byte(bit) << uint(i)
+		}
+		r[n] = value
+	}
+	return nil
+}
+
+func (b *oneWire) Close() error {
+	logrus.Traceln("bitbang.oneWire.Close()")
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+	b.closed = true
+	return gpio.ReleasePins(b.ctrl, b.claimed)
+}
+
+// writeBit sends single bit (1-Wire sends LSB first) as short (1) or long (0) low pulse
+func (b *oneWire) writeBit(bit int) error {
+	low := b.timing.WriteZeroLow
+	if bit == 1 {
+		low = b.timing.WriteOneLow
+	}
+	err := b.line.low()
+	if err != nil {
+		return err
+	}
+	wait(low)
+	err = b.line.release()
+	if err != nil {
+		return err
+	}
+	wait(b.timing.Slot - low)
+	return nil
+}
+
+// readBit starts read slot with short low pulse and samples line held low by device sending 0
+func (b *oneWire) readBit() (int, error) {
+	err := b.line.low()
+	if err != nil {
+		return 0, err
+	}
+	wait(b.timing.WriteOneLow)
+	err = b.line.release()
+	if err != nil {
+		return 0, err
+	}
+	wait(b.timing.ReadDelay)
+	bit, err := b.line.read()
+	if err != nil {
+		return 0, err
+	}
+	wait(b.timing.Slot - b.timing.WriteOneLow - b.timing.ReadDelay)
+	return bit, nil
+}
+
+// CreateOneWire creates software 1-Wire master on given pin claimed as "onewire:{name}" owner
+func CreateOneWire(ctrl gpio.Claims, name string, cfg OneWireConfig) (bus.OneWire, error) {
+	logrus.Traceln("bitbang.CreateOneWire()")
+	if cfg.Timing == (OneWireTiming{}) {
+		cfg.Timing = DefaultOneWireTiming
+	}
+	if cfg.Pin < 0 || cfg.Timing.WriteOneLow >= cfg.Timing.WriteZeroLow || cfg.Timing.WriteZeroLow > cfg.Timing.Slot ||
+		cfg.Timing.WriteOneLow+cfg.Timing.ReadDelay > cfg.Timing.Slot {
+		return nil, bus.ErrInvalidConfig
+	}
+
+	owned := ctrl.WithOwner(gpio.DriverOwner(kindOneWire, name))
+	claimed, err := gpio.ClaimPins(owned, map[int]gpio.Direction{cfg.Pin: gpio.Input})
+	if err != nil {
+		return nil, err
+	}
+	return &oneWire{ctrl: owned, line: openDrain{ctrl: owned, pin: cfg.Pin}, timing: cfg.Timing, claimed: claimed}, nil
+}
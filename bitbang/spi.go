@@ -0,0 +1,197 @@
+package bitbang
+
+import (
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+type spi struct {
+	ctrl    gpio.Owned
+	pins    SPIPins
+	claimed map[int]bool
+
+	mtx    sync.Mutex
+	cfg    bus.SPIConfig
+	half   time.Duration
+	closed bool
+}
+
+// Configure sets SPI mode and speed, only 8 bits per word is supported
+func (b *spi) Configure(cfg bus.SPIConfig) error {
+	logrus.Traceln("bitbang.spi.Configure()")
+	if cfg.BitsPerWord == 0 {
+		cfg.BitsPerWord = 8
+	}
+	if cfg.SpeedHz == 0 {
+		cfg.SpeedHz = DefaultSPISpeed
+	}
+	if cfg.Mode < bus.Mode0 || cfg.Mode > bus.Mode3 || cfg.BitsPerWord != 8 || cfg.SpeedHz > MaxFrequency {
+		return bus.ErrInvalidConfig
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+	err := b.ctrl.SetValue(b.pins.Clock, cfg.Mode.CPOL())
+	if err != nil {
+		return err
+	}
+	b.cfg = cfg
+	b.half = halfPeriod(cfg.SpeedHz)
+	return nil
+}
+
+func (b *spi) Tx(w, r []byte) error {
+	logrus.Traceln("bitbang.spi.Tx()")
+	if r != nil && len(r) != len(w) {
+		return bus.ErrInvalidValue
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+
+	err := b.selectChip(0)
+	if err != nil {
+		return err
+	}
+	for i := range w {
+		var value byte
+		value, err = b.transferByte(w[i])
+		if err != nil {
+			break
+		}
+		if r != nil {
+			r[i] = value
+		}
+	}
+	deselectErr := b.selectChip(1)
+	if err != nil {
+		return err
+	}
+	return deselectErr
+}
+
+func (b *spi) Close() error {
+	logrus.Traceln("bitbang.spi.Close()")
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+	b.closed = true
+	return gpio.ReleasePins(b.ctrl, b.claimed)
+}
+
+func (b *spi) selectChip(value int) error {
+	if b.pins.ChipSelect == NoPin {
+		return nil
+	}
+	return b.ctrl.SetValue(b.pins.ChipSelect, value)
+}
+
+// transferByte shifts out and in single byte MSB first
+// With CPHA 0 data is set before leading clock edge and sampled on it,
+// with CPHA 1 data is set on leading edge and sampled on trailing one.
+func (b *spi) transferByte(out byte) (byte, error) {
+	idle := b.cfg.Mode.CPOL()
+	active := idle ^ 1
+	in := byte(0)
+	for i := 7; i >= 0; i-- {
+		bit := int(out>>uint(i)) & 1
+		var sampled int
+		var err error
+		if b.cfg.Mode.CPHA() == 0 {
+			err = b.setMOSI(bit)
+			if err == nil {
+				wait(b.half)
+				err = b.ctrl.SetValue(b.pins.Clock, active)
+			}
+			if err == nil {
+				sampled, err = b.readMISO()
+			}
+			if err == nil {
+				wait(b.half)
+				err = b.ctrl.SetValue(b.pins.Clock, idle)
+			}
+		} else {
+			err = b.ctrl.SetValue(b.pins.Clock, active)
+			if err == nil {
+				err = b.setMOSI(bit)
+			}
+			if err == nil {
+				wait(b.half)
+				err = b.ctrl.SetValue(b.pins.Clock, idle)
+			}
+			if err == nil {
+				sampled, err = b.readMISO()
+				wait(b.half)
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		in = in<<1 | byte(sampled)
+	}
+	return in, nil
+}
+
+func (b *spi) setMOSI(bit int) error {
+	if b.pins.MOSI == NoPin {
+		return nil
+	}
+	return b.ctrl.SetValue(b.pins.MOSI, bit)
+}
+
+func (b *spi) readMISO() (int, error) {
+	if b.pins.MISO == NoPin {
+		return 0, nil
+	}
+	return b.ctrl.GetValue(b.pins.MISO)
+}
+
+// CreateSPI creates software SPI master on given pins claimed as "spi:{name}" owner, chip select is active low
+func CreateSPI(ctrl gpio.Claims, name string, pins SPIPins, cfg bus.SPIConfig) (bus.SPI, error) {
+	logrus.Traceln("bitbang.CreateSPI()")
+	if pins.Clock < 0 {
+		return nil, bus.ErrInvalidConfig
+	}
+	used := map[int]gpio.Direction{pins.Clock: gpio.Output}
+	optional := []struct {
+		pin  int
+		mode gpio.Direction
+	}{{pins.MOSI, gpio.Output}, {pins.MISO, gpio.Input}, {pins.ChipSelect, gpio.Output}}
+	for _, opt := range optional {
+		if opt.pin == NoPin {
+			continue
+		}
+		if _, exists := used[opt.pin]; exists || opt.pin < 0 {
+			return nil, bus.ErrInvalidConfig
+		}
+		used[opt.pin] = opt.mode
+	}
+
+	owned := ctrl.WithOwner(gpio.DriverOwner(kindSPI, name))
+	claimed, err := gpio.ClaimPins(owned, used)
+	if err != nil {
+		return nil, err
+	}
+	result := &spi{ctrl: owned, pins: pins, claimed: claimed}
+	err = result.selectChip(1)
+	if err == nil {
+		err = result.Configure(cfg)
+	}
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+	return result, nil
+}
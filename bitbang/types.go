@@ -0,0 +1,68 @@
+package bitbang
+
+import "time"
+
+// kinds of driver identities used by buses to claim pins
+const (
+	kindI2C     = "i2c"
+	kindSPI     = "spi"
+	kindOneWire = "onewire"
+)
+
+// NoPin - marks optional pin as not used
+const NoPin = -1
+
+const (
+	// DefaultI2CFrequency - I2C clock frequency used if not given (standard mode)
+	DefaultI2CFrequency = 100000
+	// DefaultSPISpeed - SPI clock frequency used if not given
+	DefaultSPISpeed = 100000
+	// MaxFrequency - highest I2C and SPI clock frequency accepted, sysfs access can't toggle lines faster
+	MaxFrequency = 1000000
+	// stretchTimeout - limits time of I2C clock stretching by slave device
+	stretchTimeout = 10 * time.Millisecond
+)
+
+// I2CConfig describes pins of software I2C bus, both need external pull-up resistors
+type I2CConfig struct {
+	SDA       int
+	SCL       int
+	Frequency uint32
+}
+
+// SPIPins describes pins of software SPI bus, MOSI, MISO and ChipSelect are optional
+type SPIPins struct {
+	Clock      int
+	MOSI       int
+	MISO       int
+	ChipSelect int
+}
+
+// OneWireTiming describes 1-Wire slot timings, zero value means DefaultOneWireTiming
+type OneWireTiming struct {
+	ResetLow      time.Duration
+	PresenceDelay time.Duration
+	ResetRecovery time.Duration
+	WriteOneLow   time.Duration
+	WriteZeroLow  time.Duration
+	ReadDelay     time.Duration
+	Slot          time.Duration
+}
+
+// DefaultOneWireTiming - standard speed 1-Wire timings, sysfs access makes real slots longer
+// (see Software buses in README) so write 1 and read slots may be out of spec
+var DefaultOneWireTiming = OneWireTiming{
+	ResetLow:      480 * time.Microsecond,
+	PresenceDelay: 70 * time.Microsecond,
+	ResetRecovery: 410 * time.Microsecond,
+	WriteOneLow:   6 * time.Microsecond,
+	WriteZeroLow:  60 * time.Microsecond,
+	ReadDelay:     9 * time.Microsecond,
+	Slot:          70 * time.Microsecond,
+}
+
+// OneWireConfig describes pin of software 1-Wire bus (needs external pull-up resistor)
+type OneWireConfig struct {
+	Pin    int
+	Timing OneWireTiming
+}
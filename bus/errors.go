@@ -0,0 +1,12 @@
+package bus
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid bus configuration")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrNack          = errors.New("not acknowledged")
+	ErrNoDevice      = errors.New("no device present")
+	ErrTimeout       = errors.New("bus timeout")
+	ErrClosed        = errors.New("bus closed")
+)
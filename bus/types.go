@@ -0,0 +1,59 @@
+package bus
+
+// I2C is an interface of I2C bus master
+type I2C interface {
+	// Tx writes w (if not empty) and then reads len(r) bytes (if r not empty)
+	// from device with given 7-bit address, using repeated start between both parts
+	Tx(addr uint16, w, r []byte) error
+	Close() error
+}
+
+// SPIMode defines clock polarity (CPOL) and phase (CPHA) of SPI bus
+// Possible values are Mode0, Mode1, Mode2 and Mode3
+type SPIMode int
+
+const (
+	// Mode0 - clock idle low, data sampled on rising edge
+	Mode0 SPIMode = iota
+	// Mode1 - clock idle low, data sampled on falling edge
+	Mode1
+	// Mode2 - clock idle high, data sampled on falling edge
+	Mode2
+	// Mode3 - clock idle high, data sampled on rising edge
+	Mode3
+)
+
+// CPOL returns clock idle level of mode
+func (md SPIMode) CPOL() int {
+	return int(md) >> 1 & 1
+}
+
+// CPHA returns clock phase of mode, with 0 data is sampled on leading clock edge, with 1 on trailing one
+func (md SPIMode) CPHA() int {
+	return int(md) & 1
+}
+
+// SPIConfig describes SPI transfer parameters, zero BitsPerWord means 8 bits
+type SPIConfig struct {
+	Mode        SPIMode
+	BitsPerWord int
+	SpeedHz     uint32
+}
+
+// SPI is an interface of SPI bus master talking to single device (chip select)
+type SPI interface {
+	Configure(cfg SPIConfig) error
+	// Tx sends w and simultaneously receives the same number of bytes into r
+	// (r can be nil if received data is not needed)
+	Tx(w, r []byte) error
+	Close() error
+}
+
+// OneWire is an interface of 1-Wire bus master
+type OneWire interface {
+	// Reset sends reset pulse and returns ErrNoDevice if no device answered with presence pulse
+	Reset() error
+	// Tx writes w and then reads len(r) bytes, Reset should be issued before each command
+	Tx(w, r []byte) error
+	Close() error
+}
@@ -0,0 +1,14 @@
+package devices
+
+import "errors"
+
+var (
+	ErrInvalidName    = errors.New("invalid name")
+	ErrInvalidType    = errors.New("invalid device type")
+	ErrInvalidConfig  = errors.New("invalid device configuration")
+	ErrInvalidCommand = errors.New("invalid command")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrNotFound       = errors.New("not found")
+	ErrPinClaimed     = errors.New("pin already claimed")
+	ErrBusy           = errors.New("device busy")
+)
@@ -0,0 +1,263 @@
+package devices
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+// ownerKind is a kind of driver identity used by devices to claim pins
+const ownerKind = "device"
+
+type device struct {
+	name       string
+	deviceType string
+	config     json.RawMessage
+	driver     Driver
+	// ctrl acts on behalf of device so only device changes its pins
+	ctrl gpio.Owned
+	pins []int
+	// mtx serializes calls of driver so drivers do not need own locking
+	mtx sync.Mutex
+}
+
+func (d *device) info() Info {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return Info{
+		Name:  d.name,
+		Type:  d.deviceType,
+		Pins:  append([]int{}, d.pins...),
+		State: d.driver.Describe(),
+	}
+}
+
+type registry struct {
+	ctrl      gpio.Claims
+	mtx       sync.Mutex
+	factories map[string]Factory
+	devices   map[string]*device
+}
+
+func (r *registry) Register(deviceType string, factory Factory) error {
+	logrus.Traceln("devices.registry.Register()")
+	if deviceType == "" || factory == nil {
+		return ErrInvalidType
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, exists := r.factories[deviceType]; exists {
+		return ErrAlreadyExists
+	}
+	r.factories[deviceType] = factory
+	return nil
+}
+
+func (r *registry) Types() []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	result := make([]string, 0, len(r.factories))
+	for deviceType := range r.factories {
+		result = append(result, deviceType)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (r *registry) Add(name, deviceType string, config json.RawMessage) error {
+	logrus.Traceln("devices.registry.Add()")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	factory, exists := r.factories[deviceType]
+	if !exists {
+		return ErrInvalidType
+	}
+	if _, exists = r.devices[name]; exists {
+		return ErrAlreadyExists
+	}
+
+	dev := &device{name: name, deviceType: deviceType, config: config, driver: factory(name),
+		ctrl: r.ctrl.WithOwner(Owner(name))}
+	err := dev.driver.Configure(config)
+	if err != nil {
+		return err
+	}
+	err = r.start(dev)
+	if err != nil {
+		return err
+	}
+	r.devices[name] = dev
+	return nil
+}
+
+func (r *registry) Configure(name string, config json.RawMessage) error {
+	logrus.Traceln("devices.registry.Configure()")
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	dev, exists := r.devices[name]
+	if !exists {
+		return ErrNotFound
+	}
+
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+	r.stop(dev)
+	err := dev.driver.Configure(config)
+	if err == nil {
+		err = r.start(dev)
+		if err == nil {
+			dev.config = config
+			return nil
+		}
+		// previous configuration was correct so it is restored
+		dev.driver.Configure(dev.config)
+	}
+
+	startErr := r.start(dev)
+	if startErr != nil {
+		logrus.Errorf("Failed to restart device '%s' with previous configuration: %v\n", name, startErr)
+	}
+	return err
+}
+
+func (r *registry) Remove(name string) error {
+	logrus.Traceln("devices.registry.Remove()")
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	dev, exists := r.devices[name]
+	if !exists {
+		return ErrNotFound
+	}
+	delete(r.devices, name)
+
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+	return r.stop(dev)
+}
+
+func (r *registry) Get(name string) (Info, error) {
+	r.mtx.Lock()
+	dev, exists := r.devices[name]
+	r.mtx.Unlock()
+	if !exists {
+		return Info{}, ErrNotFound
+	}
+	return dev.info(), nil
+}
+
+func (r *registry) List() []Info {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	result := make([]Info, 0, len(r.devices))
+	for _, dev := range r.devices {
+		result = append(result, dev.info())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (r *registry) Command(name, command string, args json.RawMessage) (interface{}, error) {
+	logrus.Traceln("devices.registry.Command()")
+	r.mtx.Lock()
+	dev, exists := r.devices[name]
+	r.mtx.Unlock()
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+	return dev.driver.Handle(command, args)
+}
+
+// start claims pins of configured driver and starts it, must be called with registry mutex locked
+func (r *registry) start(dev *device) error {
+	pins := dev.driver.Pins()
+	dev.pins = make([]int, 0, len(pins))
+	for pin := range pins {
+		dev.pins = append(dev.pins, pin)
+	}
+	sort.Ints(dev.pins)
+
+	for i, pin := range dev.pins {
+		err := claim(dev.ctrl, pin, pins[pin])
+		if err != nil {
+			dev.pins = dev.pins[:i]
+			r.release(dev)
+			return err
+		}
+	}
+
+	err := dev.driver.Start(dev.ctrl)
+	if err != nil {
+		logrus.Warnf("Failed to start device '%s': %v\n", dev.name, err)
+		r.release(dev)
+		return err
+	}
+	return nil
+}
+
+// stop stops driver and releases its pins, must be called with registry mutex locked
+func (r *registry) stop(dev *device) error {
+	err := dev.driver.Stop()
+	if err != nil {
+		logrus.Warnf("Failed to stop device '%s': %v\n", dev.name, err)
+	}
+	r.release(dev)
+	return err
+}
+
+// release unexports pins of device removing its claims
+func (r *registry) release(dev *device) {
+	for _, pin := range dev.pins {
+		err := dev.ctrl.UnexportPin(pin)
+		if err != nil {
+			logrus.Warnf("Failed to unexport pin '%d' of device '%s': %v\n", pin, dev.name, err)
+		}
+	}
+	dev.pins = []int{}
+}
+
+// claim exports pin and claims it exclusively, pins already exported by anyone else can not be claimed
+func claim(ctrl gpio.Owned, pin int, dir gpio.Direction) error {
+	err := ctrl.ExportPin(pin, dir)
+	if err == gpio.ErrAlreadyExported || err == gpio.ErrExternalPin {
+		logrus.Debugf("Pin '%d' already exported by other user\n", pin)
+		return ErrPinClaimed
+	}
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.Claim(pin, true)
+	if err != nil {
+		ctrl.UnexportPin(pin)
+		if err == gpio.ErrPinClaimed {
+			return ErrPinClaimed
+		}
+		return err
+	}
+	return nil
+}
+
+// Owner returns identity used by device with given name to claim pins
+func Owner(name string) string {
+	return gpio.DriverOwner(ownerKind, name)
+}
+
+func CreateRegistry(ctrl gpio.Claims) Registry {
+	logrus.Traceln("devices.CreateRegistry()")
+	return &registry{
+		ctrl:      ctrl,
+		factories: map[string]Factory{},
+		devices:   map[string]*device{},
+	}
+}
@@ -0,0 +1,125 @@
+package devices
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	require.NoError(t, ctrl.ExportPin(9, gpio.Output))
+	reg := CreateRegistry(ctrl)
+
+	t.Run("register types", func(t *testing.T) {
+		assert.NoError(t, reg.Register(RelayType, NewRelay))
+		assert.NoError(t, reg.Register(pairType, newPair))
+		assert.Equal(t, ErrAlreadyExists, reg.Register(RelayType, NewRelay))
+		assert.Equal(t, ErrInvalidType, reg.Register("", NewRelay))
+		assert.Equal(t, []string{pairType, RelayType}, reg.Types())
+	})
+
+	t.Run("add - invalid", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidName, reg.Add("", RelayType, json.RawMessage(`{"pin":5}`)))
+		assert.Equal(t, ErrInvalidType, reg.Add("pump", "valve", json.RawMessage(`{"pin":5}`)))
+		assert.Equal(t, ErrInvalidConfig, reg.Add("pump", RelayType, json.RawMessage(`{"on":true}`)))
+		assert.Equal(t, ErrInvalidConfig, reg.Add("pump", RelayType, nil))
+		assert.Empty(t, reg.List())
+	})
+
+	require.NoError(t, reg.Add("pump", RelayType, json.RawMessage(`{"pin":5,"active_low":true}`)))
+
+	t.Run("relay commands", func(t *testing.T) {
+		assert.Equal(t, 1, ctrl.Value(5))
+		result, err := reg.Command("pump", "toggle", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, relayState{Pin: 5, ActiveLow: true, On: true}, result)
+		assert.Equal(t, 0, ctrl.Value(5))
+
+		_, err = reg.Command("pump", "blink", nil)
+		assert.Equal(t, ErrInvalidCommand, err)
+		_, err = reg.Command("fan", "on", nil)
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("pin claims", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, reg.Add("pump", RelayType, json.RawMessage(`{"pin":6}`)))
+		assert.Equal(t, ErrPinClaimed, reg.Add("fan", RelayType, json.RawMessage(`{"pin":5}`)))
+		// pin exported by other user
+		assert.Equal(t, ErrPinClaimed, reg.Add("fan", RelayType, json.RawMessage(`{"pin":9}`)))
+		assert.Equal(t, ErrPinClaimed, reg.Add("x", pairType, json.RawMessage(`{"pins":[1,5]}`)))
+		assert.NotContains(t, ctrl.Exported(), 1, "partially claimed pins should be released")
+	})
+
+	t.Run("reconfigure", func(t *testing.T) {
+		require.NoError(t, reg.Add("fan", RelayType, json.RawMessage(`{"pin":7}`)))
+
+		assert.NoError(t, reg.Configure("pump", json.RawMessage(`{"pin":6,"on":true}`)))
+		assert.NotContains(t, ctrl.Exported(), 5)
+		assert.Equal(t, 1, ctrl.Value(6))
+
+		assert.Equal(t, ErrInvalidConfig, reg.Configure("pump", json.RawMessage(`{"pin":-1}`)))
+		assert.Equal(t, ErrPinClaimed, reg.Configure("pump", json.RawMessage(`{"pin":7}`)))
+		info, err := reg.Get("pump")
+		assert.NoError(t, err)
+		assert.Equal(t, Info{Name: "pump", Type: RelayType, Pins: []int{6}, State: relayState{Pin: 6, On: true}}, info)
+		assert.Contains(t, ctrl.Exported(), 6)
+
+		assert.Equal(t, ErrNotFound, reg.Configure("heater", json.RawMessage(`{"pin":8}`)))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		for _, info := range reg.List() {
+			assert.NoError(t, reg.Remove(info.Name))
+		}
+		assert.Equal(t, ErrNotFound, reg.Remove("pump"))
+		assert.Equal(t, map[int]gpio.Direction{9: gpio.Output}, ctrl.Exported())
+	})
+}
+
+// pairType - test device using two output pins
+const pairType = "pair"
+
+type pair struct {
+	pins []int
+}
+
+func newPair(name string) Driver {
+	return &pair{}
+}
+
+func (p *pair) Configure(config json.RawMessage) error {
+	var cfg struct {
+		Pins []int `json:"pins"`
+	}
+	err := json.Unmarshal(config, &cfg)
+	if err != nil || len(cfg.Pins) != 2 || cfg.Pins[0] == cfg.Pins[1] {
+		return ErrInvalidConfig
+	}
+	p.pins = cfg.Pins
+	return nil
+}
+
+func (p *pair) Pins() map[int]gpio.Direction {
+	return map[int]gpio.Direction{p.pins[0]: gpio.Output, p.pins[1]: gpio.Output}
+}
+
+func (p *pair) Start(ctrl gpio.Controller) error {
+	return nil
+}
+
+func (p *pair) Stop() error {
+	return nil
+}
+
+func (p *pair) Describe() interface{} {
+	return p.pins
+}
+
+func (p *pair) Handle(command string, args json.RawMessage) (interface{}, error) {
+	return nil, ErrInvalidCommand
+}
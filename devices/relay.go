@@ -0,0 +1,100 @@
+package devices
+
+import (
+	"encoding/json"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+// RelayType - on/off output (ex. relay, LED or buzzer) controlled with single pin
+const RelayType = "relay"
+
+// relayConfig describes relay, active low relay is switched on with low pin state
+type relayConfig struct {
+	Pin       *int `json:"pin"`
+	ActiveLow bool `json:"active_low"`
+	On        bool `json:"on"`
+}
+
+type relayState struct {
+	Pin       int  `json:"pin"`
+	ActiveLow bool `json:"active_low"`
+	On        bool `json:"on"`
+}
+
+type relay struct {
+	ctrl  gpio.Controller
+	state relayState
+}
+
+func (r *relay) Configure(config json.RawMessage) error {
+	logrus.Traceln("devices.relay.Configure()")
+	var cfg relayConfig
+	err := json.Unmarshal(config, &cfg)
+	if err != nil || cfg.Pin == nil || *cfg.Pin < 0 {
+		return ErrInvalidConfig
+	}
+	r.state = relayState{Pin: *cfg.Pin, ActiveLow: cfg.ActiveLow, On: cfg.On}
+	return nil
+}
+
+func (r *relay) Pins() map[int]gpio.Direction {
+	return map[int]gpio.Direction{r.state.Pin: gpio.Output}
+}
+
+func (r *relay) Start(ctrl gpio.Controller) error {
+	logrus.Traceln("devices.relay.Start()")
+	r.ctrl = ctrl
+	return r.set(r.state.On)
+}
+
+// Stop switches relay off, configured state is applied again on start
+func (r *relay) Stop() error {
+	logrus.Traceln("devices.relay.Stop()")
+	on := r.state.On
+	err := r.set(false)
+	r.state.On = on
+	return err
+}
+
+func (r *relay) Describe() interface{} {
+	return r.state
+}
+
+func (r *relay) Handle(command string, args json.RawMessage) (interface{}, error) {
+	logrus.Traceln("devices.relay.Handle()")
+	var err error
+	switch command {
+	case "on":
+		err = r.set(true)
+	case "off":
+		err = r.set(false)
+	case "toggle":
+		err = r.set(!r.state.On)
+	default:
+		return nil, ErrInvalidCommand
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.state, nil
+}
+
+func (r *relay) set(on bool) error {
+	value := 0
+	if on != r.state.ActiveLow {
+		value = 1
+	}
+	err := r.ctrl.SetValue(r.state.Pin, value)
+	if err != nil {
+		return err
+	}
+	r.state.On = on
+	return nil
+}
+
+// NewRelay is a factory of relay drivers
+func NewRelay(name string) Driver {
+	return &relay{}
+}
@@ -0,0 +1,55 @@
+// Package devices keeps peripherals built on GPIO pins in registry giving them common lifecycle and claimed pins.
+// Only relay driver is provided so far, servos, steppers, encoders, displays and groups keep their own managers
+// until they are moved onto registry (tracked separately)
+package devices
+
+import (
+	"encoding/json"
+
+	"github.com/markamdev/repico/gpio"
+)
+
+// Driver is an interface of peripheral driver managed by registry
+//
+// Registry calls Configure first, then exports and claims exclusively all pins returned by Pins and calls Start
+// with controller acting on behalf of device (see Owner).
+// Stop is called before pins are released, on device removal or before reconfiguration.
+// Configuration and command arguments are raw JSON objects defined by driver.
+type Driver interface {
+	// Configure validates given configuration and applies it only if it is correct
+	Configure(config json.RawMessage) error
+	// Pins returns pins needed by configured driver with their directions
+	Pins() map[int]gpio.Direction
+	Start(ctrl gpio.Controller) error
+	Stop() error
+	// Describe returns current configuration and state of device (ready to be marshalled)
+	Describe() interface{}
+	// Handle executes driver specific command and returns its result (nil if there is nothing to return)
+	Handle(command string, args json.RawMessage) (interface{}, error)
+}
+
+// Factory creates driver for device with given name
+type Factory func(name string) Driver
+
+// Info describes device kept in registry
+type Info struct {
+	Name  string
+	Type  string
+	Pins  []int
+	State interface{}
+}
+
+// Registry is an interface of object keeping named devices and pins claimed by them
+type Registry interface {
+	// Register adds device type handled by drivers created with given factory
+	Register(deviceType string, factory Factory) error
+	Types() []string
+	// Add creates, configures and starts device of given type
+	Add(name, deviceType string, config json.RawMessage) error
+	// Configure stops device, applies new configuration and starts device again
+	Configure(name string, config json.RawMessage) error
+	Remove(name string) error
+	Get(name string) (Info, error)
+	List() []Info
+	Command(name, command string, args json.RawMessage) (interface{}, error)
+}
@@ -0,0 +1,350 @@
+package display
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/markamdev/repico/i2c"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latch is a value latched by LCD controller on falling edge of E line
+type latch struct {
+	rs    int
+	value byte
+}
+
+// bytes joins latched nibbles into bytes skipping initialization sequence
+func bytes(latches []latch, eightBit bool) []latch {
+	if eightBit {
+		return latches[3:]
+	}
+	result := []latch{}
+	for i := 4; i+1 < len(latches); i += 2 {
+		result = append(result, latch{rs: latches[i].rs, value: latches[i].value<<4 | latches[i+1].value})
+	}
+	return result
+}
+
+func commands(values ...byte) []latch {
+	result := []latch{}
+	for _, value := range values {
+		result = append(result, latch{rs: 0, value: value})
+	}
+	return result
+}
+
+func characters(text string) []latch {
+	result := []latch{}
+	for _, value := range []byte(text) {
+		result = append(result, latch{rs: 1, value: value})
+	}
+	return result
+}
+
+func TestLCD(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	lcd := attachLCD(ctrl, 1, 2, []int{3, 4, 5, 6})
+	mgr := CreateManager(ctrl, nil)
+
+	t.Run("add - invalid config", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 16, Rows: 2, RSPin: 1, EnablePin: 2,
+			DataPins: []int{3, 4, 5}, BacklightPin: NoPin}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 16, Rows: 2, RSPin: 1, EnablePin: 2,
+			DataPins: []int{3, 4, 5, 1}, BacklightPin: NoPin}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 40, Rows: 4, RSPin: 1, EnablePin: 2,
+			DataPins: []int{3, 4, 5, 6}, BacklightPin: NoPin}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 16, Rows: 2, I2CAddress: 0x80}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Columns: 16, Rows: 2}))
+	})
+
+	require.NoError(t, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 16, Rows: 2, RSPin: 1, EnablePin: 2,
+		DataPins: []int{3, 4, 5, 6}, BacklightPin: 7}))
+	dsp, _ := mgr.Get("x")
+
+	t.Run("add - pin already used", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, mgr.Add("y", Config{Kind: SevenSegment, SegmentPins: []int{7, 8, 9, 10, 11, 12, 13}}))
+	})
+
+	t.Run("initialization", func(t *testing.T) {
+		latches := lcd.take()
+		assert.Equal(t, []latch{{0, 3}, {0, 3}, {0, 3}, {0, 2}}, latches[:4])
+		assert.Equal(t, commands(0x28, 0x0c, 0x06, 0x01), bytes(latches, false))
+		assert.Equal(t, 1, ctrl.Value(7), "backlight should be on")
+	})
+
+	t.Run("write and wrap text", func(t *testing.T) {
+		require.NoError(t, dsp.Write("Hi\n°C"))
+		require.NoError(t, dsp.SetCursor(14, 1))
+		require.NoError(t, dsp.Write("abc"))
+
+		expected := append(characters("Hi"), commands(0xc0)...)
+		expected = append(append(expected, latch{1, 0xdf}), characters("C")...)
+		expected = append(append(expected, commands(0xce)...), characters("ab")...)
+		expected = append(append(expected, commands(0x80)...), characters("c")...)
+		assert.Equal(t, expected, bytes(append(make([]latch, 4), lcd.take()...), false))
+
+		status := dsp.Status()
+		assert.Equal(t, []string{"ci              ", "°C            ab"}, status.Lines)
+		assert.Equal(t, 1, status.Column)
+		assert.Equal(t, 0, status.Row)
+
+		assert.Equal(t, ErrInvalidValue, dsp.Write("é"))
+		assert.Equal(t, ErrInvalidValue, dsp.SetCursor(16, 0))
+		assert.Equal(t, ErrInvalidValue, dsp.SetCursor(0, 2))
+	})
+
+	t.Run("custom characters", func(t *testing.T) {
+		pattern := []byte{0x00, 0x0a, 0x1f, 0x1f, 0x0e, 0x04, 0x00, 0x00}
+		require.NoError(t, dsp.DefineChar(1, pattern))
+		require.NoError(t, dsp.Write("\x01"))
+
+		expected := append(commands(0x48), characters(string(pattern))...)
+		expected = append(append(expected, commands(0x81)...), latch{1, 0x01})
+		assert.Equal(t, expected, bytes(append(make([]latch, 4), lcd.take()...), false))
+
+		assert.Equal(t, ErrInvalidValue, dsp.DefineChar(8, pattern))
+		assert.Equal(t, ErrInvalidValue, dsp.DefineChar(0, pattern[:7]))
+		assert.Equal(t, ErrInvalidValue, dsp.DefineChar(0, []byte{0x20, 0, 0, 0, 0, 0, 0, 0}))
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		require.NoError(t, dsp.Clear())
+		assert.Equal(t, commands(0x01), bytes(append(make([]latch, 4), lcd.take()...), false))
+		status := dsp.Status()
+		assert.Equal(t, []string{"                ", "                "}, status.Lines)
+		assert.Equal(t, 0, status.Column)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.NoError(t, mgr.Remove("x"))
+		assert.Empty(t, ctrl.Exported())
+		assert.Equal(t, ErrNotFound, mgr.Remove("x"))
+	})
+}
+
+func TestLCD8Bit(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	lcd := attachLCD(ctrl, 1, 2, []int{3, 4, 5, 6, 7, 8, 9, 10})
+	dsp, err := CreateDisplay(ctrl, nil, "x", Config{Kind: CharacterLCD, Columns: 20, Rows: 4, RSPin: 1, EnablePin: 2,
+		DataPins: []int{3, 4, 5, 6, 7, 8, 9, 10}, BacklightPin: NoPin})
+	require.NoError(t, err)
+
+	latches := lcd.take()
+	assert.Equal(t, commands(0x30, 0x30, 0x30), latches[:3])
+	assert.Equal(t, commands(0x38, 0x0c, 0x06, 0x01), bytes(latches, true))
+
+	require.NoError(t, dsp.SetCursor(0, 2))
+	require.NoError(t, dsp.SetCursor(19, 3))
+	assert.Equal(t, commands(0x94, 0xe7), lcd.take())
+}
+
+func TestLCDBackpack(t *testing.T) {
+	backpack := &fakeBackpack{}
+	i2cCtrl := i2c.CreateController(t.TempDir())
+	require.NoError(t, i2cCtrl.AddBus(1, i2c.CreateFakeBus(map[uint16]i2c.Device{0x27: backpack})))
+
+	mgr := CreateManager(gpiotest.NewFake(), i2cCtrl)
+	assert.Equal(t, bus.ErrNack, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 16, Rows: 1, I2CBus: 1, I2CAddress: 0x26}))
+	require.NoError(t, mgr.Add("x", Config{Kind: CharacterLCD, Columns: 16, Rows: 1, I2CBus: 1, I2CAddress: 0x27}))
+	assert.Equal(t, ErrAlreadyExists, mgr.Add("y", Config{Kind: CharacterLCD, Columns: 16, Rows: 1, I2CBus: 1, I2CAddress: 0x27}))
+
+	dsp, _ := mgr.Get("x")
+	require.NoError(t, dsp.Write("ok"))
+	assert.Equal(t, append(commands(0x20, 0x0c, 0x06, 0x01), characters("ok")...), bytes(backpack.take(), false))
+}
+
+func TestSevenSegment(t *testing.T) {
+	t.Run("static digit", func(t *testing.T) {
+		ctrl := gpiotest.NewFake()
+		dsp, err := CreateDisplay(ctrl, nil, "x", Config{Kind: SevenSegment, SegmentPins: []int{1, 2, 3, 4, 5, 6, 7, 8}})
+		require.NoError(t, err)
+
+		require.NoError(t, dsp.Write("7."))
+		assert.Equal(t, segmentFont['7']|segmentDP, segments(ctrl, []int{1, 2, 3, 4, 5, 6, 7, 8}, false))
+		assert.Equal(t, []string{"7."}, dsp.Status().Lines)
+
+		require.NoError(t, dsp.Write("e"))
+		assert.Equal(t, segmentFont['E'], segments(ctrl, []int{1, 2, 3, 4, 5, 6, 7, 8}, false))
+
+		assert.Equal(t, ErrInvalidValue, dsp.Write("K"))
+		assert.Equal(t, ErrInvalidValue, dsp.SetCursor(0, 1))
+		assert.Equal(t, ErrNotSupported, dsp.DefineChar(0, make([]byte, CharHeight)))
+
+		require.NoError(t, dsp.Close())
+		assert.Equal(t, byte(0), segments(ctrl, []int{1, 2, 3, 4, 5, 6, 7, 8}, false))
+		assert.Empty(t, ctrl.Exported())
+	})
+
+	t.Run("already exported pins", func(t *testing.T) {
+		ctrl := gpiotest.NewFake()
+		require.NoError(t, ctrl.ExportPin(2, gpio.Input))
+		require.NoError(t, ctrl.ExportPin(9, gpio.Output))
+
+		_, err := CreateDisplay(ctrl, nil, "x", Config{Kind: SevenSegment, SegmentPins: []int{1, 2, 3, 4, 5, 6, 7}})
+		assert.Equal(t, gpio.ErrInvalidDirection, err)
+		assert.Equal(t, map[int]gpio.Direction{2: gpio.Input, 9: gpio.Output}, ctrl.Exported(), "input should not be taken over")
+
+		dsp, err := CreateDisplay(ctrl, nil, "x", Config{Kind: SevenSegment, SegmentPins: []int{3, 4, 5, 6, 7, 8, 9}})
+		require.NoError(t, err)
+		require.NoError(t, dsp.Close())
+		assert.Equal(t, map[int]gpio.Direction{2: gpio.Input, 9: gpio.Output}, ctrl.Exported(), "reused pin should stay exported")
+	})
+
+	t.Run("claimed pins", func(t *testing.T) {
+		ctrl := gpiotest.NewFake()
+		dsp, err := CreateDisplay(ctrl, nil, "x", Config{Kind: SevenSegment, SegmentPins: []int{1, 2, 3, 4, 5, 6, 7}})
+		require.NoError(t, err)
+		assert.Equal(t, gpio.Claim{Owners: []string{"display:x"}, Exclusive: true}, ctrl.ListClaims()[4])
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.SetValue(4, 1))
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.UnexportPin(7))
+
+		_, err = CreateDisplay(ctrl, nil, "y", Config{Kind: SevenSegment, SegmentPins: []int{7, 8, 9, 10, 11, 12, 13}})
+		assert.Equal(t, gpio.ErrPinClaimed, err)
+
+		require.NoError(t, dsp.Close())
+		assert.Empty(t, ctrl.ListClaims())
+		assert.Empty(t, ctrl.Exported())
+	})
+
+	t.Run("multiplexed digits", func(t *testing.T) {
+		ctrl := gpiotest.NewFake()
+		digits := attachDigits(ctrl, []int{1, 2, 3, 4, 5, 6, 7, 8}, []int{11, 12, 13, 14}, true)
+		dsp, err := CreateDisplay(ctrl, nil, "x", Config{Kind: SevenSegment, SegmentPins: digits.segmentPins,
+			DigitPins: digits.digitPins, CommonAnode: true})
+		require.NoError(t, err)
+		defer dsp.Close()
+
+		require.NoError(t, dsp.Write("12.5"))
+		status := dsp.Status()
+		assert.Equal(t, []string{"12.5 "}, status.Lines)
+		assert.Equal(t, 3, status.Column)
+
+		expected := map[int]byte{11: segmentFont['1'], 12: segmentFont['2'] | segmentDP, 13: segmentFont['5'], 14: 0}
+		assert.Eventually(t, func() bool {
+			digits.mtx.Lock()
+			defer digits.mtx.Unlock()
+			for pin, segments := range expected {
+				if digits.lit[pin] != segments {
+					return false
+				}
+			}
+			return true
+		}, time.Second, refreshPeriod)
+
+		require.NoError(t, dsp.SetCursor(3, 0))
+		require.NoError(t, dsp.Write("-4"))
+		// text wraps to the first digit
+		assert.Equal(t, []string{"42.5-"}, dsp.Status().Lines)
+		assert.Equal(t, 1, dsp.Status().Column)
+	})
+}
+
+// fakeLCD records values latched by LCD controller connected to pins of fake controller
+type fakeLCD struct {
+	ctrl    *gpiotest.Fake
+	rs      int
+	enable  int
+	data    []int
+	mtx     sync.Mutex
+	enabled int
+	latches []latch
+}
+
+func attachLCD(ctrl *gpiotest.Fake, rs, enable int, data []int) *fakeLCD {
+	result := &fakeLCD{ctrl: ctrl, rs: rs, enable: enable, data: data}
+	ctrl.OnWrite(result.written)
+	return result
+}
+
+func (fl *fakeLCD) written(pin, value int) {
+	if pin != fl.enable {
+		return
+	}
+	fl.mtx.Lock()
+	defer fl.mtx.Unlock()
+	if value == 0 && fl.enabled == 1 {
+		latched := latch{rs: fl.ctrl.Value(fl.rs)}
+		for i, dataPin := range fl.data {
+			latched.value |= byte(fl.ctrl.Value(dataPin) << i)
+		}
+		fl.latches = append(fl.latches, latched)
+	}
+	fl.enabled = value
+}
+
+// take returns and forgets latched values
+func (fl *fakeLCD) take() []latch {
+	fl.mtx.Lock()
+	defer fl.mtx.Unlock()
+	result := fl.latches
+	fl.latches = nil
+	return result
+}
+
+// fakeDigits records patterns shown by multiplexed digits connected to pins of fake controller
+type fakeDigits struct {
+	ctrl        *gpiotest.Fake
+	segmentPins []int
+	digitPins   []int
+	commonAnode bool
+	mtx         sync.Mutex
+	lit         map[int]byte
+}
+
+func attachDigits(ctrl *gpiotest.Fake, segmentPins, digitPins []int, commonAnode bool) *fakeDigits {
+	result := &fakeDigits{ctrl: ctrl, segmentPins: segmentPins, digitPins: digitPins, commonAnode: commonAnode,
+		lit: map[int]byte{}}
+	ctrl.OnWrite(result.written)
+	return result
+}
+
+func (fd *fakeDigits) written(pin, value int) {
+	for _, digitPin := range fd.digitPins {
+		if pin == digitPin && (value == 1) == fd.commonAnode {
+			fd.mtx.Lock()
+			fd.lit[pin] = segments(fd.ctrl, fd.segmentPins, fd.commonAnode)
+			fd.mtx.Unlock()
+		}
+	}
+}
+
+// segments returns pattern shown by segment pins
+func segments(ctrl *gpiotest.Fake, pins []int, commonAnode bool) byte {
+	result := byte(0)
+	for i, pin := range pins {
+		if (ctrl.Value(pin) == 1) != commonAnode {
+			result |= 1 << i
+		}
+	}
+	return result
+}
+
+type fakeBackpack struct {
+	mtx     sync.Mutex
+	latches []latch
+}
+
+func (fb *fakeBackpack) Tx(w, r []byte) error {
+	fb.mtx.Lock()
+	defer fb.mtx.Unlock()
+	for i := 1; i < len(w); i++ {
+		if w[i-1]&backpackEnable != 0 && w[i]&backpackEnable == 0 && w[i]&backpackBacklight != 0 {
+			fb.latches = append(fb.latches, latch{rs: int(w[i] & backpackRS), value: w[i] >> 4})
+		}
+	}
+	return nil
+}
+
+func (fb *fakeBackpack) take() []latch {
+	fb.mtx.Lock()
+	defer fb.mtx.Unlock()
+	result := fb.latches
+	fb.latches = nil
+	return result
+}
@@ -0,0 +1,12 @@
+package display
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid display configuration")
+	ErrInvalidName   = errors.New("invalid name")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound      = errors.New("not found")
+	ErrNotSupported  = errors.New("not supported by display")
+)
@@ -0,0 +1,138 @@
+package display
+
+import (
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/i2c"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	// commandDelay - execution time of regular LCD command (37us in datasheet)
+	commandDelay = 50 * time.Microsecond
+	// clearDelay - execution time of clear and home LCD commands (1.52ms in datasheet)
+	clearDelay = 2 * time.Millisecond
+	// initDelay - wait after first function set during LCD initialization (4.1ms in datasheet)
+	initDelay = 5 * time.Millisecond
+)
+
+const (
+	backpackRS        = 0x01
+	backpackEnable    = 0x04
+	backpackBacklight = 0x08
+)
+
+// lcdPort latches values in LCD controller
+type lcdPort interface {
+	// send latches value with given RS line state, only 4 lowest bits are used in 4-bit mode
+	send(value byte, rs int) error
+	eightBit() bool
+}
+
+type gpioPort struct {
+	ctrl   gpio.BulkController
+	rs     int
+	enable int
+	data   []int
+}
+
+func (gp *gpioPort) send(value byte, rs int) error {
+	values := map[int]int{gp.rs: rs}
+	for i, pin := range gp.data {
+		values[pin] = int(value>>i) & 1
+	}
+	err := gp.ctrl.SetValues(values)
+	if err != nil {
+		return err
+	}
+	err = gp.ctrl.SetValue(gp.enable, 1)
+	if err != nil {
+		return err
+	}
+	return gp.ctrl.SetValue(gp.enable, 0)
+}
+
+func (gp *gpioPort) eightBit() bool {
+	return len(gp.data) == 8
+}
+
+type backpackPort struct {
+	ctrl    i2c.Controller
+	bus     int
+	address uint16
+}
+
+func (bp *backpackPort) send(value byte, rs int) error {
+	state := value<<4 | backpackBacklight
+	if rs == 1 {
+		state |= backpackRS
+	}
+	// PCF8574 outputs follow consecutive bytes so single transfer pulses E line
+	return bp.ctrl.Tx(bp.bus, bp.address, []byte{state | backpackEnable, state}, nil)
+}
+
+func (bp *backpackPort) eightBit() bool {
+	return false
+}
+
+// screen keeps displayed characters and cursor position
+type screen struct {
+	lines  [][]byte
+	column int
+	row    int
+}
+
+func newScreen(columns, rows int) screen {
+	result := screen{lines: make([][]byte, rows)}
+	for i := range result.lines {
+		result.lines[i] = make([]byte, columns)
+	}
+	result.clear()
+	return result
+}
+
+func (sc *screen) clear() {
+	for _, line := range sc.lines {
+		for i := range line {
+			line[i] = ' '
+		}
+	}
+	sc.column, sc.row = 0, 0
+}
+
+// nextRow moves cursor to the beginning of next row (or first one after the last row)
+func (sc *screen) nextRow() {
+	sc.column = 0
+	sc.row = (sc.row + 1) % len(sc.lines)
+}
+
+func (sc *screen) text() []string {
+	result := make([]string, 0, len(sc.lines))
+	for _, line := range sc.lines {
+		result = append(result, string(line))
+	}
+	return result
+}
+
+// claimPins claims all pins as outputs and returns which of them were exported by this call
+func claimPins(ctrl gpio.Owned, pins []int) (map[int]bool, error) {
+	modes := make(map[int]gpio.Direction, len(pins))
+	for _, pin := range pins {
+		modes[pin] = gpio.Output
+	}
+	return gpio.ClaimPins(ctrl, modes)
+}
+
+// distinctPins checks if all pins are valid and used only once
+func distinctPins(pins []int) bool {
+	used := map[int]bool{}
+	for _, pin := range pins {
+		if pin < 0 || used[pin] {
+			return false
+		}
+		used[pin] = true
+	}
+	return true
+}
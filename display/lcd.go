@@ -0,0 +1,291 @@
+package display
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/i2c"
+	"github.com/sirupsen/logrus"
+)
+
+// HD44780 commands and their flags
+const (
+	lcdClear         = 0x01
+	lcdEntryMode     = 0x04
+	lcdEntryIncrease = 0x02
+	lcdControl       = 0x08
+	lcdControlOn     = 0x04
+	lcdFunction      = 0x20
+	lcdFunction8Bit  = 0x10
+	lcdFunction2Line = 0x08
+	lcdSetCGRAM      = 0x40
+	lcdSetDDRAM      = 0x80
+)
+
+const (
+	lcdMaxColumns = 40
+	lcdMaxRows    = 4
+	// lcdMaxChars - size of display data RAM
+	lcdMaxChars = 80
+	// lcdDegree - degree sign in standard (A00) character ROM
+	lcdDegree = 0xdf
+)
+
+type lcd struct {
+	name    string
+	cfg     Config
+	ctrl    gpio.Owned
+	port    lcdPort
+	claimed map[int]bool
+
+	mtx    sync.Mutex
+	screen screen
+}
+
+func (l *lcd) Write(text string) error {
+	logrus.Traceln("display.lcd.Write()")
+	data, err := lcdEncode(text)
+	if err != nil {
+		return err
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for _, char := range data {
+		if char == '\n' || l.screen.column == l.cfg.Columns {
+			l.screen.nextRow()
+			err = l.command(lcdSetDDRAM | l.address())
+			if err != nil {
+				return err
+			}
+			if char == '\n' {
+				continue
+			}
+		}
+		err = l.write(char, 1)
+		if err != nil {
+			return err
+		}
+		l.screen.lines[l.screen.row][l.screen.column] = char
+		l.screen.column++
+	}
+	return nil
+}
+
+func (l *lcd) SetCursor(column, row int) error {
+	logrus.Traceln("display.lcd.SetCursor()")
+	if column < 0 || column >= l.cfg.Columns || row < 0 || row >= l.cfg.Rows {
+		return ErrInvalidValue
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.screen.column, l.screen.row = column, row
+	return l.command(lcdSetDDRAM | l.address())
+}
+
+func (l *lcd) Clear() error {
+	logrus.Traceln("display.lcd.Clear()")
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.screen.clear()
+	err := l.command(lcdClear)
+	time.Sleep(clearDelay)
+	return err
+}
+
+func (l *lcd) DefineChar(index int, pattern []byte) error {
+	logrus.Traceln("display.lcd.DefineChar()")
+	if index < 0 || index >= CustomChars || len(pattern) != CharHeight {
+		return ErrInvalidValue
+	}
+	for _, row := range pattern {
+		if row >= 0x20 {
+			return ErrInvalidValue
+		}
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	err := l.command(lcdSetCGRAM | byte(index)<<3)
+	if err != nil {
+		return err
+	}
+	for _, row := range pattern {
+		err = l.write(row, 1)
+		if err != nil {
+			return err
+		}
+	}
+	// following characters have to go to display data RAM again
+	return l.command(lcdSetDDRAM | l.address())
+}
+
+func (l *lcd) Status() Status {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return Status{
+		Name:   l.name,
+		Config: l.cfg,
+		Lines:  lcdDecode(l.screen.text()),
+		Column: l.screen.column,
+		Row:    l.screen.row,
+	}
+}
+
+func (l *lcd) Close() error {
+	logrus.Traceln("display.lcd.Close()")
+	return gpio.ReleasePins(l.ctrl, l.claimed)
+}
+
+// init switches controller to 4-bit or 8-bit mode (whatever its current mode is) and clears display
+func (l *lcd) init() error {
+	// 8-bit function set is sent three times, in 4-bit mode as single upper nibble
+	reset := byte(lcdFunction | lcdFunction8Bit)
+	if !l.port.eightBit() {
+		reset >>= 4
+	}
+	for i := 0; i < 3; i++ {
+		err := l.port.send(reset, 0)
+		if err != nil {
+			return err
+		}
+		time.Sleep(initDelay)
+	}
+
+	function := byte(lcdFunction)
+	if l.port.eightBit() {
+		function |= lcdFunction8Bit
+	} else {
+		err := l.port.send(lcdFunction>>4, 0)
+		if err != nil {
+			return err
+		}
+		time.Sleep(commandDelay)
+	}
+	if l.cfg.Rows > 1 {
+		function |= lcdFunction2Line
+	}
+
+	for _, cmd := range []byte{function, lcdControl | lcdControlOn, lcdEntryMode | lcdEntryIncrease, lcdClear} {
+		err := l.command(cmd)
+		if err != nil {
+			return err
+		}
+	}
+	time.Sleep(clearDelay)
+	return nil
+}
+
+func (l *lcd) command(cmd byte) error {
+	return l.write(cmd, 0)
+}
+
+// write sends byte to controller (in two nibbles in 4-bit mode) and waits for its execution
+func (l *lcd) write(value byte, rs int) error {
+	var err error
+	if l.port.eightBit() {
+		err = l.port.send(value, rs)
+	} else {
+		err = l.port.send(value>>4, rs)
+		if err == nil {
+			err = l.port.send(value&0x0f, rs)
+		}
+	}
+	time.Sleep(commandDelay)
+	return err
+}
+
+// address returns data RAM address of cursor, rows 2 and 3 continue rows 0 and 1
+func (l *lcd) address() byte {
+	return byte(l.screen.row%2*0x40 + l.screen.row/2*l.cfg.Columns + l.screen.column)
+}
+
+// lcdEncode converts text to character codes, characters 0-7 are custom ones
+func lcdEncode(text string) ([]byte, error) {
+	result := make([]byte, 0, len(text))
+	for _, char := range text {
+		switch {
+		case char < CustomChars || char == '\n' || (char >= ' ' && char <= '}'):
+			result = append(result, byte(char))
+		case char == '°':
+			result = append(result, lcdDegree)
+		default:
+			return nil, ErrInvalidValue
+		}
+	}
+	return result, nil
+}
+
+// lcdDecode converts character codes of displayed lines back to text
+func lcdDecode(lines []string) []string {
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, string([]byte{lcdDegree}), "°")
+	}
+	return lines
+}
+
+func validateLCD(cfg Config) error {
+	if cfg.Columns < 1 || cfg.Columns > lcdMaxColumns || cfg.Rows < 1 || cfg.Rows > lcdMaxRows ||
+		cfg.Columns*cfg.Rows > lcdMaxChars {
+		return ErrInvalidConfig
+	}
+	if cfg.I2CAddress != 0 {
+		if cfg.I2CBus < 0 || cfg.I2CAddress < i2c.MinAddress || cfg.I2CAddress > i2c.MaxAddress {
+			return ErrInvalidConfig
+		}
+		return nil
+	}
+	if len(cfg.DataPins) != 4 && len(cfg.DataPins) != 8 {
+		return ErrInvalidConfig
+	}
+	if cfg.BacklightPin < NoPin || !distinctPins(lcdPins(cfg)) {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// lcdPins returns all pins used by LCD connected directly
+func lcdPins(cfg Config) []int {
+	if cfg.I2CAddress != 0 {
+		return []int{}
+	}
+	result := append([]int{cfg.RSPin, cfg.EnablePin}, cfg.DataPins...)
+	if cfg.BacklightPin != NoPin {
+		result = append(result, cfg.BacklightPin)
+	}
+	return result
+}
+
+// createLCD claims pins (or uses I2C backpack) and initializes LCD
+func createLCD(ctrl gpio.Owned, i2cCtrl i2c.Controller, name string, cfg Config) (Display, error) {
+	err := validateLCD(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &lcd{name: name, cfg: cfg, ctrl: ctrl, screen: newScreen(cfg.Columns, cfg.Rows)}
+	if cfg.I2CAddress != 0 {
+		result.port = &backpackPort{ctrl: i2cCtrl, bus: cfg.I2CBus, address: cfg.I2CAddress}
+	} else {
+		result.claimed, err = claimPins(ctrl, lcdPins(cfg))
+		if err != nil {
+			return nil, err
+		}
+		result.port = &gpioPort{ctrl: ctrl, rs: cfg.RSPin, enable: cfg.EnablePin, data: cfg.DataPins}
+		if cfg.BacklightPin != NoPin {
+			err = ctrl.SetValue(cfg.BacklightPin, 1)
+		}
+	}
+	if err == nil {
+		err = result.init()
+	}
+	if err != nil {
+		logrus.Errorf("Failed to initialize LCD '%s': %v\n", name, err)
+		result.Close()
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package display
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/i2c"
+	"github.com/sirupsen/logrus"
+)
+
+type manager struct {
+	ctrl     gpio.Claims
+	i2cCtrl  i2c.Controller
+	mtx      sync.Mutex
+	displays map[string]Display
+}
+
+func (m *manager) Add(name string, cfg Config) error {
+	logrus.Traceln("display.manager.Add()")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, exists := m.displays[name]; exists {
+		return ErrAlreadyExists
+	}
+	for _, dsp := range m.displays {
+		if conflicts(cfg, dsp.Status().Config) {
+			return ErrAlreadyExists
+		}
+	}
+
+	dsp, err := CreateDisplay(m.ctrl, m.i2cCtrl, name, cfg)
+	if err != nil {
+		return err
+	}
+	m.displays[name] = dsp
+	return nil
+}
+
+func (m *manager) Remove(name string) error {
+	logrus.Traceln("display.manager.Remove()")
+	m.mtx.Lock()
+	dsp, exists := m.displays[name]
+	delete(m.displays, name)
+	m.mtx.Unlock()
+
+	if !exists {
+		return ErrNotFound
+	}
+	return dsp.Close()
+}
+
+func (m *manager) Get(name string) (Display, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	dsp, exists := m.displays[name]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return dsp, nil
+}
+
+func (m *manager) List() []Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	result := make([]Status, 0, len(m.displays))
+	for _, dsp := range m.displays {
+		result = append(result, dsp.Status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// conflicts checks if displays share any pin or I2C backpack
+func conflicts(first, second Config) bool {
+	if first.I2CAddress != 0 && first.I2CAddress == second.I2CAddress && first.I2CBus == second.I2CBus {
+		return true
+	}
+	used := map[int]bool{}
+	for _, pin := range usedPins(first) {
+		used[pin] = true
+	}
+	for _, pin := range usedPins(second) {
+		if used[pin] {
+			return true
+		}
+	}
+	return false
+}
+
+func usedPins(cfg Config) []int {
+	switch cfg.Kind {
+	case CharacterLCD:
+		return lcdPins(cfg)
+	case SevenSegment:
+		return segmentPins(cfg)
+	default:
+		return []int{}
+	}
+}
+
+// CreateDisplay claims all pins used by display (as "display:{name}" owner), initializes it and returns
+// object controlling it, I2C controller is used only by character LCD with I2C backpack
+func CreateDisplay(ctrl gpio.Claims, i2cCtrl i2c.Controller, name string, cfg Config) (Display, error) {
+	logrus.Traceln("display.CreateDisplay()")
+	owned := ctrl.WithOwner(gpio.DriverOwner(ownerKind, name))
+	switch cfg.Kind {
+	case CharacterLCD:
+		return createLCD(owned, i2cCtrl, name, cfg)
+	case SevenSegment:
+		return createSevenSegment(owned, name, cfg)
+	default:
+		return nil, ErrInvalidConfig
+	}
+}
+
+func CreateManager(ctrl gpio.Claims, i2cCtrl i2c.Controller) Manager {
+	logrus.Traceln("display.CreateManager()")
+	return &manager{ctrl: ctrl, i2cCtrl: i2cCtrl, displays: map[string]Display{}}
+}
@@ -0,0 +1,245 @@
+package display
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// refreshPeriod - time for which each digit of multiplexed display is lit
+	refreshPeriod = 2 * time.Millisecond
+	// segmentDP - decimal point bit in segment pattern, bits 0-6 are segments a-g
+	segmentDP = 0x80
+)
+
+// segmentFont contains patterns of characters possible to show on 7-segment display
+var segmentFont = map[rune]byte{
+	'0': 0x3f, '1': 0x06, '2': 0x5b, '3': 0x4f, '4': 0x66, '5': 0x6d, '6': 0x7d, '7': 0x07, '8': 0x7f, '9': 0x6f,
+	'A': 0x77, 'B': 0x7c, 'C': 0x39, 'D': 0x5e, 'E': 0x79, 'F': 0x71, 'G': 0x3d, 'H': 0x76, 'I': 0x06, 'J': 0x1e,
+	'L': 0x38, 'N': 0x54, 'O': 0x3f, 'P': 0x73, 'R': 0x50, 'S': 0x6d, 'T': 0x78, 'U': 0x3e, 'Y': 0x6e,
+	'-': 0x40, '_': 0x08, ' ': 0x00, '°': 0x63,
+}
+
+type sevenSegment struct {
+	name    string
+	cfg     Config
+	ctrl    gpio.Owned
+	claimed map[int]bool
+
+	mtx      sync.Mutex
+	screen   screen
+	segments []byte
+	cancel   chan struct{}
+	done     chan struct{}
+}
+
+func (s *sevenSegment) Write(text string) error {
+	logrus.Traceln("display.sevenSegment.Write()")
+	for _, char := range text {
+		if _, exists := segmentFont[unicode.ToUpper(char)]; !exists && char != '.' && char != '\n' {
+			return ErrInvalidValue
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, char := range text {
+		column := s.screen.column
+		switch {
+		case char == '\n':
+			s.screen.nextRow()
+			continue
+		case char == '.' && column > 0 && s.segments[column-1]&segmentDP == 0:
+			// decimal point belongs to previous digit
+			s.segments[column-1] |= segmentDP
+			continue
+		case column == s.cfg.Columns:
+			s.screen.nextRow()
+			column = 0
+		}
+
+		if char == '.' {
+			s.screen.lines[0][column] = ' '
+			s.segments[column] = segmentDP
+		} else {
+			s.screen.lines[0][column] = byte(unicode.ToUpper(char))
+			s.segments[column] = segmentFont[unicode.ToUpper(char)]
+		}
+		s.screen.column++
+	}
+	return s.show()
+}
+
+func (s *sevenSegment) SetCursor(column, row int) error {
+	logrus.Traceln("display.sevenSegment.SetCursor()")
+	if column < 0 || column >= s.cfg.Columns || row != 0 {
+		return ErrInvalidValue
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.screen.column = column
+	return nil
+}
+
+func (s *sevenSegment) Clear() error {
+	logrus.Traceln("display.sevenSegment.Clear()")
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.screen.clear()
+	for i := range s.segments {
+		s.segments[i] = 0
+	}
+	return s.show()
+}
+
+func (s *sevenSegment) DefineChar(index int, pattern []byte) error {
+	return ErrNotSupported
+}
+
+func (s *sevenSegment) Status() Status {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var text strings.Builder
+	for i, char := range s.screen.lines[0] {
+		text.WriteByte(char)
+		if s.segments[i]&segmentDP != 0 {
+			text.WriteByte('.')
+		}
+	}
+	return Status{
+		Name:   s.name,
+		Config: s.cfg,
+		Lines:  []string{text.String()},
+		Column: s.screen.column,
+	}
+}
+
+func (s *sevenSegment) Close() error {
+	logrus.Traceln("display.sevenSegment.Close()")
+	if s.cancel != nil {
+		close(s.cancel)
+		<-s.done
+	}
+	s.setSegments(0)
+	return gpio.ReleasePins(s.ctrl, s.claimed)
+}
+
+// show updates static display, multiplexed one is updated by refresh loop
+// Must be called with mutex locked
+func (s *sevenSegment) show() error {
+	if len(s.cfg.DigitPins) > 0 {
+		return nil
+	}
+	return s.setSegments(s.segments[0])
+}
+
+// refresh lights consecutive digits of multiplexed display until cancelled
+func (s *sevenSegment) refresh() {
+	defer close(s.done)
+	for {
+		for digit, pin := range s.cfg.DigitPins {
+			s.mtx.Lock()
+			segments := s.segments[digit]
+			s.mtx.Unlock()
+
+			err := s.setSegments(segments)
+			if err == nil {
+				err = s.ctrl.SetValue(pin, s.digitLevel(1))
+			}
+			if err != nil {
+				logrus.Errorf("Failed to refresh display '%s': %v\n", s.name, err)
+			}
+
+			select {
+			case <-s.cancel:
+				s.ctrl.SetValue(pin, s.digitLevel(0))
+				return
+			case <-time.After(refreshPeriod):
+			}
+			s.ctrl.SetValue(pin, s.digitLevel(0))
+		}
+	}
+}
+
+func (s *sevenSegment) setSegments(segments byte) error {
+	values := map[int]int{}
+	for i, pin := range s.cfg.SegmentPins {
+		values[pin] = s.segmentLevel(int(segments>>i) & 1)
+	}
+	return s.ctrl.SetValues(values)
+}
+
+// segmentLevel returns pin value lighting (1) or blanking (0) segment, segments of common anode
+// display are lit with low state
+func (s *sevenSegment) segmentLevel(state int) int {
+	if s.cfg.CommonAnode {
+		return 1 - state
+	}
+	return state
+}
+
+// digitLevel returns pin value enabling (1) or disabling (0) digit common line
+func (s *sevenSegment) digitLevel(state int) int {
+	if s.cfg.CommonAnode {
+		return state
+	}
+	return 1 - state
+}
+
+func validateSevenSegment(cfg Config) error {
+	if len(cfg.SegmentPins) != 7 && len(cfg.SegmentPins) != 8 {
+		return ErrInvalidConfig
+	}
+	if !distinctPins(segmentPins(cfg)) {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// segmentPins returns all pins used by 7-segment display
+func segmentPins(cfg Config) []int {
+	return append(append([]int{}, cfg.SegmentPins...), cfg.DigitPins...)
+}
+
+// createSevenSegment claims pins and starts refresh loop of multiplexed display
+func createSevenSegment(ctrl gpio.Owned, name string, cfg Config) (Display, error) {
+	err := validateSevenSegment(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Columns, cfg.Rows = len(cfg.DigitPins), 1
+	if cfg.Columns == 0 {
+		cfg.Columns = 1
+	}
+
+	result := &sevenSegment{name: name, cfg: cfg, ctrl: ctrl, screen: newScreen(cfg.Columns, 1),
+		segments: make([]byte, cfg.Columns)}
+	result.claimed, err = claimPins(ctrl, segmentPins(cfg))
+	if err != nil {
+		return nil, err
+	}
+	for _, pin := range cfg.DigitPins {
+		err = ctrl.SetValue(pin, result.digitLevel(0))
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+	}
+	err = result.setSegments(0)
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	if len(cfg.DigitPins) > 0 {
+		result.cancel, result.done = make(chan struct{}), make(chan struct{})
+		go result.refresh()
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package display
+
+// Kind defines display type
+// Possible values are InvalidKind, CharacterLCD and SevenSegment
+type Kind int
+
+const (
+	// InvalidKind - default value, not set
+	InvalidKind Kind = iota
+	// CharacterLCD - HD44780 compatible character LCD
+	CharacterLCD
+	// SevenSegment - LED display with one or more (multiplexed) 7-segment digits
+	SevenSegment
+)
+
+const (
+	kindCharacterLCD = "hd44780"
+	kindSevenSegment = "7-segment"
+)
+
+// ownerKind is a kind of driver identity used by displays to claim pins
+const ownerKind = "display"
+
+// NoPin marks optional pin as not connected
+const NoPin = -1
+
+const (
+	// CustomChars - number of user defined LCD characters, available as characters 0-7 in written text
+	CustomChars = 8
+	// CharHeight - number of rows in custom character pattern (5 lowest bits of each row are used)
+	CharHeight = 8
+)
+
+func KindToString(kd Kind) string {
+	switch kd {
+	case CharacterLCD:
+		return kindCharacterLCD
+	case SevenSegment:
+		return kindSevenSegment
+	default:
+		return "-"
+	}
+}
+
+func StringToKind(kd string) Kind {
+	switch kd {
+	case kindCharacterLCD:
+		return CharacterLCD
+	case kindSevenSegment:
+		return SevenSegment
+	default:
+		return InvalidKind
+	}
+}
+
+// Config describes display connection
+//
+// Character LCD (R/W line tied to ground) is connected with RS and E pins and 4 (D4-D7) or 8 (D0-D7) data pins,
+// optional backlight pin is switched on at start. When I2CAddress is set PCF8574 based I2C backpack is used
+// instead of pins (RS, E and backlight on P0, P2 and P3, D4-D7 on P4-P7).
+//
+// Seven segment display is connected with 7 (a-g) or 8 (a-g and decimal point) segment pins and one common
+// pin per digit (digits are multiplexed). Without digit pins single digit with common line tied permanently is used.
+type Config struct {
+	Kind         Kind
+	Columns      int
+	Rows         int
+	RSPin        int
+	EnablePin    int
+	DataPins     []int
+	BacklightPin int
+	I2CBus       int
+	I2CAddress   uint16
+	SegmentPins  []int
+	DigitPins    []int
+	CommonAnode  bool
+}
+
+// Status describes current display content, lines contain custom LCD characters as characters 0-7
+// and decimal points of 7-segment display as separate '.' characters
+type Status struct {
+	Name   string
+	Config Config
+	Lines  []string
+	Column int
+	Row    int
+}
+
+// Display is an interface of single display controlling object
+type Display interface {
+	// Write puts text at cursor position and moves cursor, text wraps at the end of row
+	// and new line character moves cursor to the beginning of next row
+	Write(text string) error
+	SetCursor(column, row int) error
+	// Clear removes whole content and moves cursor to top left position
+	Clear() error
+	// DefineChar sets pattern of custom character (character LCD only)
+	DefineChar(index int, pattern []byte) error
+	Status() Status
+	Close() error
+}
+
+// Manager is an interface of object keeping named displays
+type Manager interface {
+	Add(name string, cfg Config) error
+	Remove(name string) error
+	Get(name string) (Display, error)
+	List() []Status
+}
@@ -0,0 +1,267 @@
+package encoder
+
+import (
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+// transitions maps previous and current AB state (prev<<2 | cur) to quarter step direction
+var transitions = [16]int{
+	0, 1, -1, 0,
+	-1, 0, 0, 1,
+	1, 0, 0, -1,
+	0, -1, 1, 0,
+}
+
+type encoder struct {
+	name    string
+	cfg     Config
+	ctrl    Controller
+	owned   gpio.Owned
+	claimed map[int]bool
+
+	eventsA      <-chan gpio.Event
+	eventsB      <-chan gpio.Event
+	eventsButton <-chan gpio.Event
+	done         chan struct{}
+
+	mtx      sync.Mutex
+	state    int
+	steps    int
+	position int
+	pressed  bool
+}
+
+func (e *encoder) SetPosition(position int) error {
+	logrus.Traceln("encoder.encoder.SetPosition()")
+	if e.cfg.Limited && (position < e.cfg.Min || position > e.cfg.Max) {
+		return ErrInvalidPosition
+	}
+
+	e.mtx.Lock()
+	e.position = position
+	e.steps = 0
+	e.mtx.Unlock()
+
+	e.publish(e.name, position)
+	return nil
+}
+
+func (e *encoder) Status() Status {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return Status{Name: e.name, Config: e.cfg, Position: e.position, Pressed: e.pressed}
+}
+
+func (e *encoder) Close() error {
+	logrus.Traceln("encoder.encoder.Close()")
+	e.ctrl.Unsubscribe(e.cfg.PinA, e.eventsA)
+	e.ctrl.Unsubscribe(e.cfg.PinB, e.eventsB)
+	if e.eventsButton != nil {
+		e.ctrl.Unsubscribe(e.cfg.ButtonPin, e.eventsButton)
+	}
+	<-e.done
+	return gpio.ReleasePins(e.owned, e.claimed)
+}
+
+// run decodes pin events until subscriptions get closed
+func (e *encoder) run() {
+	defer close(e.done)
+	eventsA, eventsB, eventsButton := e.eventsA, e.eventsB, e.eventsButton
+	for eventsA != nil || eventsB != nil {
+		select {
+		case event, ok := <-eventsA:
+			if !ok {
+				eventsA = nil
+				continue
+			}
+			e.quadrature(1, event.Value)
+		case event, ok := <-eventsB:
+			if !ok {
+				eventsB = nil
+				continue
+			}
+			e.quadrature(0, event.Value)
+		case event, ok := <-eventsButton:
+			if !ok {
+				eventsButton = nil
+				continue
+			}
+			e.button(event.Value)
+		}
+	}
+}
+
+// quadrature updates AB state with new value of one input and moves position on full detent
+func (e *encoder) quadrature(bit uint, value int) {
+	e.mtx.Lock()
+	prev := e.state
+	e.state = (e.state &^ (1 << bit)) | (value << bit)
+	e.steps += transitions[prev<<2|e.state]
+
+	moved := false
+	for e.steps >= e.cfg.StepsPerDetent || e.steps <= -e.cfg.StepsPerDetent {
+		direction := 1
+		if e.steps < 0 {
+			direction = -1
+		}
+		e.steps -= direction * e.cfg.StepsPerDetent
+		moved = e.move(direction) || moved
+	}
+	position := e.position
+	e.mtx.Unlock()
+
+	if moved {
+		e.publish(e.name, position)
+	}
+}
+
+// move changes position by one detent respecting limits, must be called with mutex locked
+func (e *encoder) move(direction int) bool {
+	next := e.position + direction
+	if e.cfg.Limited {
+		if next > e.cfg.Max {
+			if !e.cfg.Wrap {
+				return false
+			}
+			next = e.cfg.Min
+		} else if next < e.cfg.Min {
+			if !e.cfg.Wrap {
+				return false
+			}
+			next = e.cfg.Max
+		}
+	}
+	e.position = next
+	return true
+}
+
+func (e *encoder) button(value int) {
+	e.mtx.Lock()
+	e.pressed = value == e.cfg.ButtonActive
+	pressed := e.pressed
+	e.mtx.Unlock()
+
+	buttonValue := 0
+	if pressed {
+		buttonValue = 1
+	}
+	e.publish(e.name+ButtonSuffix, buttonValue)
+}
+
+func (e *encoder) publish(device string, value int) {
+	e.ctrl.Publish(gpio.Event{Pin: gpio.NoPin, Value: value, Time: time.Now(), Device: device})
+}
+
+func applyDefaults(cfg Config) Config {
+	if cfg.StepsPerDetent == 0 {
+		cfg.StepsPerDetent = DefaultStepsPerDetent
+	}
+	return cfg
+}
+
+func validateConfig(cfg Config) error {
+	if cfg.PinA < 0 || cfg.PinB < 0 || cfg.PinA == cfg.PinB {
+		return ErrInvalidConfig
+	}
+	if cfg.ButtonPin < NoPin || cfg.ButtonPin == cfg.PinA || cfg.ButtonPin == cfg.PinB {
+		return ErrInvalidConfig
+	}
+	if (cfg.ButtonActive != 0 && cfg.ButtonActive != 1) || cfg.ButtonDebounce < 0 {
+		return ErrInvalidConfig
+	}
+	if cfg.StepsPerDetent != 1 && cfg.StepsPerDetent != 2 && cfg.StepsPerDetent != 4 {
+		return ErrInvalidConfig
+	}
+	if cfg.Limited && cfg.Min >= cfg.Max {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// CreateEncoder claims encoder pins as inputs (as "encoder:{name}" owner) and starts decoding their changes
+// Initial position is 0 or Min if it is above 0
+func CreateEncoder(ctrl Controller, name string, cfg Config) (Encoder, error) {
+	logrus.Traceln("encoder.CreateEncoder()")
+	cfg = applyDefaults(cfg)
+	err := validateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &encoder{name: name, cfg: cfg, ctrl: ctrl, owned: ctrl.WithOwner(gpio.DriverOwner(ownerKind, name)),
+		done: make(chan struct{})}
+	if cfg.Limited && cfg.Min > 0 {
+		result.position = cfg.Min
+	}
+
+	err = result.setup()
+	if err != nil {
+		result.cleanup()
+		return nil, err
+	}
+
+	go result.run()
+	return result, nil
+}
+
+func (e *encoder) setup() error {
+	pins := map[int]gpio.Direction{e.cfg.PinA: gpio.Input, e.cfg.PinB: gpio.Input}
+	if e.cfg.ButtonPin != NoPin {
+		pins[e.cfg.ButtonPin] = gpio.Input
+	}
+	var err error
+	e.claimed, err = gpio.ClaimPins(e.owned, pins)
+	if err != nil {
+		return err
+	}
+
+	if e.cfg.ButtonPin != NoPin && e.cfg.ButtonDebounce > 0 {
+		err = e.ctrl.SetFilter(e.cfg.ButtonPin, gpio.Filter{Debounce: e.cfg.ButtonDebounce})
+		if err != nil {
+			return err
+		}
+	}
+
+	valueA, err := e.owned.GetValue(e.cfg.PinA)
+	if err != nil {
+		return err
+	}
+	valueB, err := e.owned.GetValue(e.cfg.PinB)
+	if err != nil {
+		return err
+	}
+	e.state = valueA<<1 | valueB
+
+	e.eventsA, err = e.ctrl.Subscribe(e.cfg.PinA)
+	if err != nil {
+		return err
+	}
+	e.eventsB, err = e.ctrl.Subscribe(e.cfg.PinB)
+	if err != nil {
+		return err
+	}
+	if e.cfg.ButtonPin != NoPin {
+		e.eventsButton, err = e.ctrl.Subscribe(e.cfg.ButtonPin)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanup releases resources acquired by partially successful setup
+func (e *encoder) cleanup() {
+	if e.eventsA != nil {
+		e.ctrl.Unsubscribe(e.cfg.PinA, e.eventsA)
+	}
+	if e.eventsB != nil {
+		e.ctrl.Unsubscribe(e.cfg.PinB, e.eventsB)
+	}
+	if e.claimed != nil {
+		gpio.ReleasePins(e.owned, e.claimed)
+	}
+}
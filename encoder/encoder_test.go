@@ -0,0 +1,135 @@
+package encoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clockwise quadrature sequence of (A, B) values starting from idle state 00
+var clockwise = [][2]int{{0, 1}, {1, 1}, {1, 0}, {0, 0}}
+
+func rotate(ctrl *gpiotest.Fake, cfg Config, detents int) {
+	for d := 0; d < detents; d++ {
+		for _, ab := range clockwise {
+			ctrl.Drive(cfg.PinA, ab[0])
+			ctrl.Drive(cfg.PinB, ab[1])
+		}
+	}
+}
+
+func rotateBack(ctrl *gpiotest.Fake, cfg Config, detents int) {
+	for d := 0; d < detents; d++ {
+		for i := len(clockwise) - 2; i >= -1; i-- {
+			ab := clockwise[(i+len(clockwise))%len(clockwise)]
+			ctrl.Drive(cfg.PinB, ab[1])
+			ctrl.Drive(cfg.PinA, ab[0])
+		}
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	mgr := CreateManager(ctrl)
+	all := ctrl.SubscribeAll()
+	// button is pulled up
+	ctrl.Drive(3, 1)
+
+	t.Run("add - invalid config", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("knob", Config{PinA: 1, PinB: 1, ButtonPin: NoPin}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("knob", Config{PinA: 1, PinB: 2, ButtonPin: NoPin,
+			Limited: true, Min: 5, Max: 5}))
+	})
+
+	cfg := Config{PinA: 1, PinB: 2, ButtonPin: 3, Limited: true, Min: 0, Max: 9, Wrap: true}
+	require.NoError(t, mgr.Add("knob", cfg))
+	enc, _ := mgr.Get("knob")
+
+	position := func() int { return enc.Status().Position }
+
+	t.Run("clockwise rotation", func(t *testing.T) {
+		rotate(ctrl, cfg, 3)
+		assert.Eventually(t, func() bool { return position() == 3 }, time.Second, time.Millisecond)
+
+		event := <-all
+		for event.Device == "" {
+			event = <-all
+		}
+		assert.Equal(t, gpio.Event{Pin: gpio.NoPin, Value: 1, Time: event.Time, Device: "knob"}, event)
+	})
+
+	t.Run("counter-clockwise rotation with wrap", func(t *testing.T) {
+		rotateBack(ctrl, cfg, 5)
+		assert.Eventually(t, func() bool { return position() == 8 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("set position", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidPosition, enc.SetPosition(10))
+		assert.NoError(t, enc.SetPosition(9))
+		rotate(ctrl, cfg, 1)
+		assert.Eventually(t, func() bool { return position() == 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("button", func(t *testing.T) {
+		ctrl.Drive(3, 0)
+		assert.Eventually(t, func() bool { return enc.Status().Pressed }, time.Second, time.Millisecond)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.NoError(t, mgr.Remove("knob"))
+		assert.Empty(t, ctrl.Exported())
+	})
+}
+
+func TestEncoderLimits(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	cfg := Config{PinA: 1, PinB: 2, ButtonPin: NoPin, StepsPerDetent: 2, Limited: true, Min: 0, Max: 2}
+	enc, err := CreateEncoder(ctrl, "knob", cfg)
+	require.NoError(t, err)
+	defer enc.Close()
+
+	rotate(ctrl, cfg, 3)
+	assert.Eventually(t, func() bool { return enc.Status().Position == 2 }, time.Second, time.Millisecond)
+	rotateBack(ctrl, cfg, 1)
+	assert.Eventually(t, func() bool { return enc.Status().Position == 0 }, time.Second, time.Millisecond)
+}
+
+func TestEncoderAlreadyExportedPin(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	require.NoError(t, ctrl.ExportPin(2, gpio.Output))
+	require.NoError(t, ctrl.ExportPin(3, gpio.Input))
+
+	_, err := CreateEncoder(ctrl, "knob", Config{PinA: 1, PinB: 2, ButtonPin: NoPin})
+	assert.Equal(t, gpio.ErrInvalidDirection, err)
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Output, 3: gpio.Input}, ctrl.Exported(), "output should not be taken over")
+
+	enc, err := CreateEncoder(ctrl, "knob", Config{PinA: 1, PinB: 3, ButtonPin: NoPin})
+	require.NoError(t, err)
+	require.NoError(t, enc.Close())
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Output, 3: gpio.Input}, ctrl.Exported(), "reused pin should stay exported")
+}
+
+func TestEncoderClaimedPins(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	enc, err := CreateEncoder(ctrl, "knob", Config{PinA: 1, PinB: 2, ButtonPin: 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]gpio.Claim{
+		1: {Owners: []string{"encoder:knob"}, Exclusive: true},
+		2: {Owners: []string{"encoder:knob"}, Exclusive: true},
+		3: {Owners: []string{"encoder:knob"}, Exclusive: true},
+	}, ctrl.ListClaims())
+	assert.Equal(t, gpio.ErrPinClaimed, ctrl.SetDirection(1, gpio.Output))
+	assert.Equal(t, gpio.ErrPinClaimed, ctrl.UnexportPin(3))
+
+	_, err = CreateEncoder(ctrl, "volume", Config{PinA: 4, PinB: 2, ButtonPin: NoPin})
+	assert.Equal(t, gpio.ErrPinClaimed, err)
+	assert.NotContains(t, ctrl.Exported(), 4)
+
+	require.NoError(t, enc.Close())
+	assert.Empty(t, ctrl.ListClaims())
+	assert.Empty(t, ctrl.Exported())
+}
@@ -0,0 +1,11 @@
+package encoder
+
+import "errors"
+
+var (
+	ErrInvalidConfig   = errors.New("invalid encoder configuration")
+	ErrInvalidName     = errors.New("invalid name")
+	ErrInvalidPosition = errors.New("invalid position")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrNotFound        = errors.New("not found")
+)
@@ -0,0 +1,89 @@
+package encoder
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type manager struct {
+	ctrl     Controller
+	mtx      sync.Mutex
+	encoders map[string]Encoder
+}
+
+func (m *manager) Add(name string, cfg Config) error {
+	logrus.Traceln("encoder.manager.Add()")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, exists := m.encoders[name]; exists {
+		return ErrAlreadyExists
+	}
+	requested := usedPins(cfg)
+	for _, enc := range m.encoders {
+		for pin := range usedPins(enc.Status().Config) {
+			if requested[pin] {
+				return ErrAlreadyExists
+			}
+		}
+	}
+
+	enc, err := CreateEncoder(m.ctrl, name, cfg)
+	if err != nil {
+		return err
+	}
+	m.encoders[name] = enc
+	return nil
+}
+
+func (m *manager) Remove(name string) error {
+	logrus.Traceln("encoder.manager.Remove()")
+	m.mtx.Lock()
+	enc, exists := m.encoders[name]
+	delete(m.encoders, name)
+	m.mtx.Unlock()
+
+	if !exists {
+		return ErrNotFound
+	}
+	return enc.Close()
+}
+
+func (m *manager) Get(name string) (Encoder, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	enc, exists := m.encoders[name]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return enc, nil
+}
+
+func (m *manager) List() []Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	result := make([]Status, 0, len(m.encoders))
+	for _, enc := range m.encoders {
+		result = append(result, enc.Status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func usedPins(cfg Config) map[int]bool {
+	result := map[int]bool{cfg.PinA: true, cfg.PinB: true}
+	if cfg.ButtonPin != NoPin {
+		result[cfg.ButtonPin] = true
+	}
+	return result
+}
+
+func CreateManager(ctrl Controller) Manager {
+	logrus.Traceln("encoder.CreateManager()")
+	return &manager{ctrl: ctrl, encoders: map[string]Encoder{}}
+}
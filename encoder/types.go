@@ -0,0 +1,65 @@
+package encoder
+
+import (
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+)
+
+// ownerKind is a kind of driver identity used by encoders to claim pins
+const ownerKind = "encoder"
+
+// NoPin marks optional pin as not connected
+const NoPin = -1
+
+// DefaultStepsPerDetent - most of knob encoders make full quadrature cycle per detent
+const DefaultStepsPerDetent = 4
+
+// ButtonSuffix is added to encoder name in events published for push-button changes
+const ButtonSuffix = "/button"
+
+// Config describes encoder connection
+// Position is kept within Min and Max if Limited is set, it is wrapped around
+// (Max+1 becomes Min) if Wrap is set as well
+type Config struct {
+	PinA           int
+	PinB           int
+	ButtonPin      int
+	ButtonActive   int
+	ButtonDebounce time.Duration
+	StepsPerDetent int
+	Limited        bool
+	Min            int
+	Max            int
+	Wrap           bool
+}
+
+// Status describes current encoder state
+type Status struct {
+	Name     string
+	Config   Config
+	Position int
+	Pressed  bool
+}
+
+// Controller is a GPIO controller used by encoders, pins are claimed on behalf of encoder
+// and their changes are received from shared event source
+type Controller interface {
+	gpio.EventSource
+	gpio.Claims
+}
+
+// Encoder is an interface of single rotary encoder object
+type Encoder interface {
+	SetPosition(position int) error
+	Status() Status
+	Close() error
+}
+
+// Manager is an interface of object keeping named encoders
+type Manager interface {
+	Add(name string, cfg Config) error
+	Remove(name string) error
+	Get(name string) (Encoder, error)
+	List() []Status
+}
@@ -4,6 +4,7 @@ go 1.15
 
 require (
 	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/namsral/flag v1.7.4-pre
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
@@ -0,0 +1,71 @@
+package gpio
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// SetValues sets values of many output pins at once
+// All pins are validated first and nothing is written if any of them is incorrect.
+// Sysfs interface has no multi-line requests so values are written one by one
+// right after validation to keep skew between pins as small as possible.
+// Returned error (if any) is of PinErrors type.
+func (c *controller) SetValues(values map[int]int) error {
+	return c.setValues(noOwner, values)
+}
+
+func (c *controller) setValues(owner string, values map[int]int) error {
+	logrus.Traceln("gpio.controller.SetValues()")
+	pins := make([]int, 0, len(values))
+	for pin := range values {
+		pins = append(pins, pin)
+	}
+	unlock := c.lockPins(pins...)
+	defer unlock()
+
+	failed := PinErrors{}
+	files := make(map[int]*pinFile, len(values))
+	for pin, value := range values {
+		pf, err := c.checkOutput(owner, pin, value)
+		if err != nil {
+			failed[pin] = err
+			continue
+		}
+		files[pin] = pf
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+
+	for pin, value := range values {
+		err := c.writePin(pin, files[pin], value)
+		if err != nil {
+			logrus.Errorf("Failed to set pin '%d' value: %v\n", pin, err)
+			failed[pin] = err
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// GetValues reads values of many pins at once (with filters applied)
+// If some pins can not be read returned error is of PinErrors type and
+// values of all other pins are still returned.
+func (c *controller) GetValues(pins []int) (map[int]int, error) {
+	logrus.Traceln("gpio.controller.GetValues()")
+	result := make(map[int]int, len(pins))
+	failed := PinErrors{}
+	for _, pin := range pins {
+		value, err := c.GetValue(pin)
+		if err != nil {
+			failed[pin] = err
+			continue
+		}
+		result[pin] = value
+	}
+	if len(failed) > 0 {
+		return result, failed
+	}
+	return result, nil
+}
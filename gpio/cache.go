@@ -0,0 +1,93 @@
+package gpio
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// pinFile keeps value file of exported pin opened together with pin direction
+// so that value access needs single pread/pwrite call instead of stat, open,
+// direction check and close done for each operation
+type pinFile struct {
+	file     *os.File
+	output   bool
+	writable bool
+}
+
+// openPin returns cached pin file opening it if needed, must be called with pin lock held
+func (c *controller) openPin(pin int) (*pinFile, error) {
+	c.mtx.Lock()
+	pf, exists := c.files[pin]
+	c.mtx.Unlock()
+	if exists {
+		return pf, nil
+	}
+
+	pinString := strconv.Itoa(pin)
+	if !isExported(c.basePath, pinString) {
+		return nil, ErrNotExported
+	}
+	out, err := isOutput(c.basePath, pinString)
+	if err != nil {
+		logrus.Errorln("Failed to check mode:", err)
+		return nil, err
+	}
+	// value file of input is opened for writing too (if possible) so that
+	// direction change does not require reopening it
+	file, err := openValue(c.basePath, pinString, os.O_RDWR)
+	writable := err == nil
+	if err != nil && !out {
+		file, err = openValue(c.basePath, pinString, os.O_RDONLY)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pf = &pinFile{file: file, output: out, writable: writable}
+	c.mtx.Lock()
+	c.files[pin] = pf
+	c.mtx.Unlock()
+	return pf, nil
+}
+
+// closePin drops cached pin file, must be called with pin lock held
+func (c *controller) closePin(pin int) {
+	c.mtx.Lock()
+	pf, exists := c.files[pin]
+	delete(c.files, pin)
+	c.mtx.Unlock()
+	if exists {
+		pf.file.Close()
+	}
+}
+
+// readPin reads value using cached file, must be called with pin lock held
+func (c *controller) readPin(pin int, pf *pinFile) (int, error) {
+	value, err := readOpenedValue(pf.file)
+	if err != nil {
+		return -1, c.pinFileError(pin, err)
+	}
+	return value, nil
+}
+
+// writePin writes value using cached file, must be called with pin lock held
+func (c *controller) writePin(pin int, pf *pinFile, value int) error {
+	err := writeOpenedValue(pf.file, value)
+	if err != nil {
+		return c.pinFileError(pin, err)
+	}
+	return nil
+}
+
+// pinFileError drops cached file after failed access as pin could have been
+// unexported by someone else (file is no longer valid in such case)
+func (c *controller) pinFileError(pin int, err error) error {
+	logrus.Warnf("Access to value of pin '%d' failed: %v\n", pin, err)
+	c.closePin(pin)
+	if !isExported(c.basePath, strconv.Itoa(pin)) {
+		return ErrNotExported
+	}
+	return err
+}
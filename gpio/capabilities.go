@@ -0,0 +1,90 @@
+package gpio
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Capabilities describes all lines of GPIO chips registered in sysfs
+// Data of lines not exported in sysfs comes from chip character device (if any)
+func (c *controller) Capabilities() ([]LineInfo, error) {
+	logrus.Traceln("gpio.controller.Capabilities()")
+	chips, err := listChips(c.basePath)
+	if err != nil {
+		return []LineInfo{}, err
+	}
+
+	c.exportMtx.Lock()
+	defer c.exportMtx.Unlock()
+	exported, err := listExported(c.basePath)
+	if err != nil {
+		return []LineInfo{}, err
+	}
+	isExported := make(map[int]bool, len(exported))
+	for _, pin := range exported {
+		pinInt, _ := strconv.Atoi(pin)
+		isExported[pinInt] = true
+	}
+
+	result := []LineInfo{}
+	for _, ch := range chips {
+		result = append(result, c.chipLines(ch, isExported)...)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Pin < result[j].Pin })
+	return result, nil
+}
+
+// chipLines must be called with export mutex locked
+func (c *controller) chipLines(ch chip, isExported map[int]bool) []LineInfo {
+	var lineSource func(line *LineInfo)
+	if device := chipDevice(ch.path); device != "" {
+		fChip, err := openChip(c.devPath, device)
+		if err == nil {
+			defer fChip.Close()
+			lineSource = func(line *LineInfo) {
+				info, err := readLineInfo(fChip, line.Offset)
+				if err == nil {
+					applyLineInfo(line, info)
+				}
+			}
+		}
+	}
+
+	result := make([]LineInfo, 0, ch.ngpio)
+	for offset := 0; offset < ch.ngpio; offset++ {
+		line := LineInfo{Pin: ch.base + offset, Chip: ch.label, Offset: offset}
+		if lineSource != nil {
+			lineSource(&line)
+		}
+		if isExported[line.Pin] {
+			c.applyExported(&line)
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// applyExported fills line description with data of sysfs pin directory
+func (c *controller) applyExported(line *LineInfo) {
+	pinString := strconv.Itoa(line.Pin)
+	c.mtx.Lock()
+	owned := c.owned[line.Pin]
+	c.mtx.Unlock()
+	if owned {
+		line.Usage = Exported
+	} else {
+		line.Usage = External
+	}
+
+	isOut, err := isOutput(c.basePath, pinString)
+	if err == nil {
+		line.Direction = Input
+		if isOut {
+			line.Direction = Output
+		}
+	}
+	line.ActiveLow = isActiveLow(c.basePath, pinString)
+	line.Features.EdgeDetection = hasEdge(c.basePath, pinString)
+}
@@ -0,0 +1,75 @@
+package gpio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	base := newFakeSysfs(t)
+	ctrl := CreateController(base, t.TempDir())
+
+	t.Run("no chips", func(t *testing.T) {
+		lines, err := ctrl.Capabilities()
+		assert.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+
+	chipPath := filepath.Join(base, "gpiochip2")
+	require.NoError(t, os.Mkdir(chipPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(chipPath, "base"), []byte("2\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(chipPath, "ngpio"), []byte("3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(chipPath, "label"), []byte("pinctrl-test\n"), 0644))
+
+	require.NoError(t, ctrl.ExportPin(3, Output))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "export"), []byte("4"), 0600))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(base, "gpio4"))
+		return err == nil
+	}, time.Second, time.Millisecond)
+	require.NoError(t, os.Remove(filepath.Join(base, "gpio4", "edge")))
+
+	t.Run("sysfs lines", func(t *testing.T) {
+		lines, err := ctrl.Capabilities()
+		require.NoError(t, err)
+		assert.Equal(t, []LineInfo{
+			{Pin: 2, Chip: "pinctrl-test", Offset: 0, Usage: Free},
+			{Pin: 3, Chip: "pinctrl-test", Offset: 1, Usage: Exported, Direction: Output,
+				Features: Features{EdgeDetection: true}},
+			{Pin: 4, Chip: "pinctrl-test", Offset: 2, Usage: External, Direction: Input},
+		}, lines)
+	})
+
+	t.Run("invalid chip", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(chipPath, "ngpio"), []byte("x\n"), 0644))
+		_, err := ctrl.Capabilities()
+		assert.Equal(t, ErrUnknown, err)
+	})
+}
+
+func TestApplyLineInfo(t *testing.T) {
+	info := lineInfo{offset: 18, flags: lineFlagKernel | lineFlagIsOut | lineFlagActiveLow | lineFlagOpenDrain | lineFlagPullUp}
+	copy(info.name[:], "GPIO18")
+	copy(info.consumer[:], "led0")
+
+	line := LineInfo{Pin: 530, Offset: 18}
+	applyLineInfo(&line, info)
+	assert.Equal(t, LineInfo{Pin: 530, Offset: 18, Name: "GPIO18", Consumer: "led0", Usage: Used,
+		Direction: Output, ActiveLow: true, Features: Features{Bias: PullUp, Drive: OpenDrain}}, line)
+
+	applyLineInfo(&line, lineInfo{flags: lineFlagIsOut | lineFlagBiasOff})
+	assert.Equal(t, Features{Bias: BiasDisabled, Drive: PushPull}, line.Features)
+
+	applyLineInfo(&line, lineInfo{flags: lineFlagOpenDrain | lineFlagPullDown})
+	assert.Equal(t, "", line.Name)
+	assert.Equal(t, Input, line.Direction)
+	assert.Equal(t, Features{Bias: PullDown}, line.Features, "drive should be reported only for outputs")
+
+	applyLineInfo(&line, lineInfo{flags: lineFlagIsOut | lineFlagOpenSrc})
+	assert.Equal(t, Features{Drive: OpenSource}, line.Features)
+}
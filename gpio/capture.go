@@ -0,0 +1,124 @@
+package gpio
+
+import (
+	"sync"
+	"time"
+)
+
+// captureSamples - number of last pulses of each level used for statistics
+const captureSamples = 256
+
+// pulseBuffer keeps widths of last captured pulses of single level
+type pulseBuffer struct {
+	widths []time.Duration
+	next   int
+}
+
+func (pb *pulseBuffer) add(width time.Duration) {
+	if len(pb.widths) < captureSamples {
+		pb.widths = append(pb.widths, width)
+	} else {
+		pb.widths[pb.next] = width
+	}
+	pb.next = (pb.next + 1) % captureSamples
+}
+
+func (pb *pulseBuffer) stats() PulseStats {
+	if len(pb.widths) == 0 {
+		return PulseStats{}
+	}
+	result := PulseStats{Count: len(pb.widths), Min: pb.widths[0], Max: pb.widths[0]}
+	sum := time.Duration(0)
+	for _, width := range pb.widths {
+		sum += width
+		if width < result.Min {
+			result.Min = width
+		}
+		if width > result.Max {
+			result.Max = width
+		}
+	}
+	result.Average = sum / time.Duration(len(pb.widths))
+	last := pb.next - 1
+	if last < 0 {
+		last = len(pb.widths) - 1
+	}
+	result.Last = pb.widths[last]
+	return result
+}
+
+// capture timestamps edges delivered by event subscription and measures pulse widths
+type capture struct {
+	pin    int
+	events <-chan Event
+
+	mtx       sync.Mutex
+	high      pulseBuffer
+	low       pulseBuffer
+	lastValue int
+	lastTime  time.Time
+}
+
+func newCapture(pin int, events <-chan Event) *capture {
+	result := &capture{pin: pin, events: events, lastValue: -1}
+	go result.run()
+	return result
+}
+
+// run consumes events until subscription gets closed
+func (cp *capture) run() {
+	for event := range cp.events {
+		cp.mtx.Lock()
+		cp.edge(event)
+		cp.mtx.Unlock()
+	}
+}
+
+// edge processes single event, must be called with mutex locked
+func (cp *capture) edge(event Event) {
+	if cp.lastValue >= 0 && cp.lastValue != event.Value {
+		width := event.Time.Sub(cp.lastTime)
+		if cp.lastValue == 1 {
+			cp.high.add(width)
+		} else {
+			cp.low.add(width)
+		}
+	}
+	cp.lastValue = event.Value
+	cp.lastTime = event.Time
+}
+
+func (cp *capture) state() CaptureState {
+	cp.mtx.Lock()
+	defer cp.mtx.Unlock()
+
+	result := CaptureState{Pin: cp.pin, High: cp.high.stats(), Low: cp.low.stats()}
+	period := result.High.Average + result.Low.Average
+	if result.High.Count > 0 && result.Low.Count > 0 && period > 0 {
+		result.DutyCycle = float64(result.High.Average) / float64(period)
+	}
+	return result
+}
+
+// measureEcho waits for full high pulse on subscribed events and returns its width
+func measureEcho(events <-chan Event, timeout time.Duration) (time.Duration, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var rise time.Time
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return 0, ErrNotExported
+			}
+			if event.Value == 1 {
+				rise = event.Time
+			} else if !rise.IsZero() {
+				return event.Time.Sub(rise), nil
+			}
+		case <-deadline.C:
+			return 0, ErrTimeout
+		}
+	}
+}
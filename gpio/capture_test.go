@@ -0,0 +1,80 @@
+package gpio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapture(t *testing.T) {
+	events := make(chan Event)
+	cp := newCapture(4, events)
+	start := time.Now()
+
+	// 1ms high and 3ms low pulses, with the last high pulse longer
+	at := start
+	for i := 0; i < 5; i++ {
+		high := time.Millisecond
+		if i == 4 {
+			high = 2 * time.Millisecond
+		}
+		events <- Event{Pin: 4, Value: 1, Time: at}
+		events <- Event{Pin: 4, Value: 0, Time: at.Add(high)}
+		at = at.Add(4 * time.Millisecond)
+	}
+	close(events)
+
+	assert.Eventually(t, func() bool { return cp.state().High.Count == 5 }, time.Second, time.Millisecond)
+
+	state := cp.state()
+	assert.Equal(t, PulseStats{Count: 5, Last: 2 * time.Millisecond, Min: time.Millisecond,
+		Max: 2 * time.Millisecond, Average: 1200 * time.Microsecond}, state.High)
+	assert.Equal(t, 4, state.Low.Count)
+	assert.Equal(t, 3*time.Millisecond, state.Low.Min)
+	assert.InDelta(t, 1.2/4.2, state.DutyCycle, 0.001)
+}
+
+func TestMeasureEcho(t *testing.T) {
+	start := time.Now()
+
+	t.Run("echo received", func(t *testing.T) {
+		events := make(chan Event, 3)
+		events <- Event{Value: 0, Time: start}
+		events <- Event{Value: 1, Time: start.Add(time.Millisecond)}
+		events <- Event{Value: 0, Time: start.Add(3 * time.Millisecond)}
+
+		width, err := measureEcho(events, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, 2*time.Millisecond, width)
+	})
+
+	t.Run("no echo", func(t *testing.T) {
+		events := make(chan Event, 1)
+		events <- Event{Value: 1, Time: start}
+
+		_, err := measureEcho(events, 10*time.Millisecond)
+		assert.Equal(t, ErrTimeout, err)
+	})
+}
+
+func TestStopCounterKeepsCapture(t *testing.T) {
+	ctrl := CreateController(t.TempDir(), "/dev").(*controller)
+	counterEvents := make(chan Event)
+	captureEvents := make(chan Event)
+	ctrl.counters[4] = newCounter(4, Rising, time.Second, counterEvents)
+	ctrl.captures[4] = newCapture(4, captureEvents)
+
+	assert.NoError(t, ctrl.StopCounter(4))
+	_, err := ctrl.GetCounter(4)
+	assert.Equal(t, ErrNotRunning, err)
+
+	start := time.Now()
+	captureEvents <- Event{Pin: 4, Value: 1, Time: start}
+	captureEvents <- Event{Pin: 4, Value: 0, Time: start.Add(time.Millisecond)}
+	assert.Eventually(t, func() bool {
+		state, err := ctrl.GetCapture(4)
+		return err == nil && state.High.Count == 1
+	}, time.Second, time.Millisecond, "capture should keep working after counter is stopped")
+	assert.NoError(t, ctrl.StopCapture(4))
+}
@@ -0,0 +1,107 @@
+package gpio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	// ioctl request and line flags from linux/gpio.h (uAPI v1)
+	ioctlGetLineInfo = 0xc048b402
+
+	lineFlagKernel    = 1 << 0
+	lineFlagIsOut     = 1 << 1
+	lineFlagActiveLow = 1 << 2
+	lineFlagOpenDrain = 1 << 3
+	lineFlagOpenSrc   = 1 << 4
+	lineFlagPullUp    = 1 << 5
+	lineFlagPullDown  = 1 << 6
+	lineFlagBiasOff   = 1 << 7
+)
+
+// lineInfo mirrors struct gpioline_info
+type lineInfo struct {
+	offset   uint32
+	flags    uint32
+	name     [32]byte
+	consumer [32]byte
+}
+
+// chipDevice returns name of character device (ex. "gpiochip0") of chip given by its sysfs directory
+// or empty string if kernel does not provide one
+func chipDevice(chipPath string) string {
+	matches, err := filepath.Glob(chipPath + "/device/gpiochip*")
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	return filepath.Base(matches[0])
+}
+
+func openChip(devPath, device string) (*os.File, error) {
+	fChip, err := os.OpenFile(devPath+"/"+device, os.O_RDWR, 0)
+	if err != nil {
+		logrus.Traceln("openChip() opening failed:", err)
+		return nil, err
+	}
+	return fChip, nil
+}
+
+func readLineInfo(fChip *os.File, offset int) (lineInfo, error) {
+	info := lineInfo{offset: uint32(offset)}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fChip.Fd(), ioctlGetLineInfo, uintptr(unsafe.Pointer(&info)))
+	if errno != 0 {
+		logrus.Traceln("readLineInfo() ioctl failed:", errno)
+		return lineInfo{}, errno
+	}
+	return info, nil
+}
+
+// applyLineInfo fills line description with data reported by character device
+func applyLineInfo(line *LineInfo, info lineInfo) {
+	line.Name = cString(info.name[:])
+	line.Consumer = cString(info.consumer[:])
+	if info.flags&lineFlagKernel != 0 {
+		line.Usage = Used
+	}
+	if info.flags&lineFlagIsOut != 0 {
+		line.Direction = Output
+	} else {
+		line.Direction = Input
+	}
+	line.ActiveLow = info.flags&lineFlagActiveLow != 0
+
+	switch {
+	case info.flags&lineFlagPullUp != 0:
+		line.Features.Bias = PullUp
+	case info.flags&lineFlagPullDown != 0:
+		line.Features.Bias = PullDown
+	case info.flags&lineFlagBiasOff != 0:
+		line.Features.Bias = BiasDisabled
+	default:
+		line.Features.Bias = BiasUnknown
+	}
+	switch {
+	case line.Direction != Output:
+		line.Features.Drive = DriveUnknown
+	case info.flags&lineFlagOpenDrain != 0:
+		line.Features.Drive = OpenDrain
+	case info.flags&lineFlagOpenSrc != 0:
+		line.Features.Drive = OpenSource
+	default:
+		line.Features.Drive = PushPull
+	}
+}
+
+func cString(data []byte) string {
+	if end := strings.IndexByte(string(data), 0); end >= 0 {
+		return string(data[:end])
+	}
+	return string(data)
+}
@@ -0,0 +1,191 @@
+package gpio
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// noOwner is an identity of controller used without owner, it can not claim pins
+const noOwner = ""
+
+// driverSeparator separates kind and name in identities of drivers running in daemon (ex. "device:relay1")
+const driverSeparator = ":"
+
+type claim struct {
+	owners    map[string]bool
+	exclusive bool
+}
+
+// ownedController performs operations on behalf of owner, all other methods are inherited
+// from controller so both share pins, claims and events
+type ownedController struct {
+	*controller
+	owner string
+}
+
+func (oc *ownedController) SetValue(pin, value int) error {
+	return oc.setValue(oc.owner, pin, value)
+}
+
+func (oc *ownedController) SetValues(values map[int]int) error {
+	return oc.setValues(oc.owner, values)
+}
+
+func (oc *ownedController) Toggle(pin int) (int, error) {
+	return oc.toggle(oc.owner, pin)
+}
+
+func (oc *ownedController) SetDirection(pin int, mode Direction) error {
+	return oc.setDirection(oc.owner, pin, mode)
+}
+
+func (oc *ownedController) UnexportPin(pin int) error {
+	return oc.unexportPin(oc.owner, pin)
+}
+
+func (oc *ownedController) StartCounter(pin int, edge Edge, window time.Duration) error {
+	return oc.startCounter(oc.owner, pin, edge, window)
+}
+
+func (oc *ownedController) StopCounter(pin int) error {
+	return oc.stopCounter(oc.owner, pin)
+}
+
+func (oc *ownedController) ResetCounter(pin int) (CounterState, error) {
+	return oc.resetCounter(oc.owner, pin)
+}
+
+func (oc *ownedController) StartCapture(pin int) error {
+	return oc.startCapture(oc.owner, pin)
+}
+
+func (oc *ownedController) StopCapture(pin int) error {
+	return oc.stopCapture(oc.owner, pin)
+}
+
+func (oc *ownedController) TriggerAndMeasure(trigger, echo int, pulse, timeout time.Duration) (time.Duration, error) {
+	return oc.triggerAndMeasure(oc.owner, trigger, echo, pulse, timeout)
+}
+
+func (oc *ownedController) Claim(pin int, exclusive bool) error {
+	return oc.claim(oc.owner, pin, exclusive)
+}
+
+func (oc *ownedController) Release(pin int) error {
+	return oc.release(oc.owner, pin)
+}
+
+// DriverOwner returns identity used to claim pins by driver of given kind (ex. "stepper") and name
+func DriverOwner(kind, name string) string {
+	return kind + driverSeparator + name
+}
+
+// IsDriverOwner checks if owner is an identity reserved for drivers, such identities must not be accepted
+// from clients as they would allow changing pins claimed by drivers
+func IsDriverOwner(owner string) bool {
+	return strings.Contains(owner, driverSeparator)
+}
+
+// WithOwner returns controller performing value, direction and unexport operations on behalf of owner
+// (ex. client token or device driver), owner can claim pins to protect them from other users
+func (c *controller) WithOwner(owner string) Owned {
+	logrus.Traceln("gpio.controller.WithOwner()")
+	return &ownedController{controller: c, owner: owner}
+}
+
+func (c *controller) ListClaims() map[int]Claim {
+	logrus.Traceln("gpio.controller.ListClaims()")
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	result := make(map[int]Claim, len(c.claims))
+	for pin, cl := range c.claims {
+		owners := make([]string, 0, len(cl.owners))
+		for owner := range cl.owners {
+			owners = append(owners, owner)
+		}
+		sort.Strings(owners)
+		result[pin] = Claim{Owners: owners, Exclusive: cl.exclusive}
+	}
+	return result
+}
+
+// claim adds owner to pin claim, exclusive claim is possible only if nobody else claims pin
+// and shared claim only if pin is not claimed exclusively by someone else
+// Owner can change type of own claim as long as nobody else claims pin
+func (c *controller) claim(owner string, pin int, exclusive bool) error {
+	logrus.Traceln("gpio.controller.Claim()")
+	if owner == noOwner {
+		return ErrNoOwner
+	}
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	_, err := c.openPin(pin)
+	if err != nil {
+		return err
+	}
+	err = c.checkExternal(pin)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cl, exists := c.claims[pin]
+	if !exists || (len(cl.owners) == 1 && cl.owners[owner]) {
+		c.claims[pin] = &claim{owners: map[string]bool{owner: true}, exclusive: exclusive}
+		return nil
+	}
+	if exclusive || cl.exclusive {
+		return ErrPinClaimed
+	}
+	cl.owners[owner] = true
+	return nil
+}
+
+func (c *controller) release(owner string, pin int) error {
+	logrus.Traceln("gpio.controller.Release()")
+	if owner == noOwner {
+		return ErrNoOwner
+	}
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cl, exists := c.claims[pin]
+	if !exists || !cl.owners[owner] {
+		return ErrNotClaimed
+	}
+	delete(cl.owners, owner)
+	if len(cl.owners) == 0 {
+		delete(c.claims, pin)
+	}
+	return nil
+}
+
+// checkClaim refuses changing pin claimed exclusively by someone else, must be called with pin lock held
+func (c *controller) checkClaim(owner string, pin int) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cl, exists := c.claims[pin]
+	if exists && cl.exclusive && !cl.owners[owner] {
+		logrus.Debugf("Pin '%d' claimed exclusively by other owner\n", pin)
+		return ErrPinClaimed
+	}
+	return nil
+}
+
+// claimedByOthers checks if anyone else than owner claims pin, must be called with pin lock held
+func (c *controller) claimedByOthers(owner string, pin int) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cl, exists := c.claims[pin]
+	if !exists {
+		return false
+	}
+	return len(cl.owners) > 1 || !cl.owners[owner]
+}
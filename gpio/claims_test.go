@@ -0,0 +1,96 @@
+package gpio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaims(t *testing.T) {
+	ctrl := CreateController(newFakeSysfs(t), "/dev")
+	alice := ctrl.WithOwner("alice")
+	bob := ctrl.WithOwner("bob")
+
+	require.NoError(t, ctrl.ExportPin(4, Output))
+	require.NoError(t, ctrl.ExportPin(5, Output))
+
+	t.Run("claim - invalid", func(t *testing.T) {
+		assert.Equal(t, ErrNoOwner, ctrl.WithOwner("").Claim(4, true))
+		assert.Equal(t, ErrNotExported, alice.Claim(6, true))
+		assert.Equal(t, ErrNotClaimed, alice.Release(4))
+	})
+
+	t.Run("exclusive claim", func(t *testing.T) {
+		require.NoError(t, alice.Claim(4, true))
+		assert.Equal(t, ErrPinClaimed, bob.Claim(4, false))
+		assert.Equal(t, map[int]Claim{4: {Owners: []string{"alice"}, Exclusive: true}}, ctrl.ListClaims())
+
+		assert.NoError(t, alice.SetValue(4, 1))
+		assert.Equal(t, ErrPinClaimed, bob.SetValue(4, 0))
+		assert.Equal(t, ErrPinClaimed, ctrl.SetValue(4, 0))
+		_, err := bob.Toggle(4)
+		assert.Equal(t, ErrPinClaimed, err)
+		assert.Equal(t, PinErrors{4: ErrPinClaimed}, bob.SetValues(map[int]int{4: 0, 5: 1}))
+		assert.Equal(t, ErrPinClaimed, bob.SetDirection(4, Input))
+		assert.Equal(t, ErrPinClaimed, ctrl.UnexportPin(4))
+
+		value, err := bob.GetValue(4)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value, "reading should be allowed and value unchanged")
+	})
+
+	t.Run("counters on claimed pins", func(t *testing.T) {
+		require.NoError(t, ctrl.ExportPin(6, Input))
+		require.NoError(t, alice.Claim(6, true))
+
+		assert.Equal(t, ErrPinClaimed, ctrl.StartCounter(6, Rising, 0))
+		assert.Equal(t, ErrPinClaimed, bob.StartCounter(6, Rising, 0))
+		assert.Equal(t, ErrPinClaimed, bob.StartCapture(6))
+		_, err := bob.TriggerAndMeasure(4, 6, time.Millisecond, time.Millisecond)
+		assert.Equal(t, ErrPinClaimed, err)
+
+		assert.Equal(t, ErrPinClaimed, ctrl.StopCounter(6))
+		assert.Equal(t, ErrPinClaimed, bob.StopCounter(6))
+		_, err = bob.ResetCounter(6)
+		assert.Equal(t, ErrPinClaimed, err)
+		assert.Equal(t, ErrPinClaimed, bob.StopCapture(6))
+		assert.Equal(t, ErrNotRunning, alice.StopCounter(6))
+		_, err = alice.ResetCounter(6)
+		assert.Equal(t, ErrNotRunning, err)
+		assert.Equal(t, ErrNotRunning, alice.StopCapture(6))
+		require.NoError(t, alice.UnexportPin(6))
+	})
+
+	t.Run("shared claim", func(t *testing.T) {
+		require.NoError(t, alice.Claim(5, false))
+		require.NoError(t, bob.Claim(5, false))
+		assert.Equal(t, ErrPinClaimed, alice.Claim(5, true))
+		assert.Equal(t, Claim{Owners: []string{"alice", "bob"}}, ctrl.ListClaims()[5])
+
+		assert.NoError(t, ctrl.SetValue(5, 1))
+		assert.Equal(t, ErrPinClaimed, alice.UnexportPin(5))
+
+		require.NoError(t, bob.Release(5))
+		require.NoError(t, alice.Claim(5, true), "owner should be able to change own claim")
+		assert.Equal(t, Claim{Owners: []string{"alice"}, Exclusive: true}, ctrl.ListClaims()[5])
+	})
+
+	t.Run("unexport by owner removes claims", func(t *testing.T) {
+		assert.NoError(t, alice.UnexportPin(4))
+		assert.NoError(t, alice.UnexportPin(5))
+		assert.Empty(t, ctrl.ListClaims())
+
+		require.NoError(t, bob.ExportPin(4, Output))
+		assert.NoError(t, ctrl.SetValue(4, 1))
+		assert.NoError(t, ctrl.UnexportPin(4))
+	})
+}
+
+func TestDriverOwner(t *testing.T) {
+	assert.Equal(t, "device:relay1", DriverOwner("device", "relay1"))
+	assert.True(t, IsDriverOwner(DriverOwner("stepper", "x")))
+	assert.False(t, IsDriverOwner("alice"))
+	assert.False(t, IsDriverOwner(""))
+}
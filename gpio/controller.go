@@ -1,61 +1,198 @@
 package gpio
 
 import (
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// controller serializes export, unexport and listing of pins with exportMtx
+// while operations on single pin are serialized with per-pin locks (see lockPins),
+// exportMtx is always taken before pin locks
 type controller struct {
 	basePath string
+	devPath  string
+	events   *dispatcher
+
+	exportMtx sync.Mutex
+
+	mtx      sync.Mutex
+	pinLocks map[int]*sync.Mutex
+	files    map[int]*pinFile
+	filters  map[int]Filter
+	counters map[int]*counter
+	captures map[int]*capture
+	claims   map[int]*claim
+	owned    map[int]bool
 }
 
 func (c *controller) SetValue(pin, value int) error {
+	return c.setValue(noOwner, pin, value)
+}
+
+func (c *controller) setValue(owner string, pin, value int) error {
 	logrus.Traceln("gpio.controller.SetValue()")
-	if value != 0 && value != 1 {
-		return ErrInvalidValue
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	pf, err := c.checkOutput(owner, pin, value)
+	if err != nil {
+		return err
 	}
 
-	pinString := strconv.Itoa(pin)
+	return c.writePin(pin, pf, value)
+}
 
-	if !isExported(pinString) {
-		return ErrNotExported
+// Toggle inverts output pin value and returns the new one
+// Read and write are done under pin lock so concurrent toggles never get lost
+func (c *controller) Toggle(pin int) (int, error) {
+	return c.toggle(noOwner, pin)
+}
+
+func (c *controller) toggle(owner string, pin int) (int, error) {
+	logrus.Traceln("gpio.controller.Toggle()")
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	pf, err := c.checkOutput(owner, pin, 0)
+	if err != nil {
+		return -1, err
 	}
 
-	out, err := isOutput(pinString)
+	value, err := c.readPin(pin, pf)
 	if err != nil {
-		logrus.Errorln("Failed to check mode:", err)
-		return err
+		return -1, err
+	}
+
+	value ^= 1
+	err = c.writePin(pin, pf, value)
+	if err != nil {
+		logrus.Errorln("Failed to set value:", err)
+		return -1, err
 	}
-	if !out {
+	return value, nil
+}
+
+// SetDirection changes direction of already exported pin
+// Switching input to output drives pin low (as "out" written to sysfs direction file does)
+// so together with switching back to input it can emulate open-drain line (ex. for I2C)
+func (c *controller) SetDirection(pin int, mode Direction) error {
+	return c.setDirection(noOwner, pin, mode)
+}
+
+func (c *controller) setDirection(owner string, pin int, mode Direction) error {
+	logrus.Traceln("gpio.controller.SetDirection()")
+	if mode != Input && mode != Output {
 		return ErrInvalidDirection
 	}
+	unlock := c.lockPins(pin)
+	defer unlock()
 
-	valueString := strconv.Itoa(value)
-	return setValue(pinString, valueString)
+	pf, err := c.openPin(pin)
+	if err != nil {
+		return err
+	}
+	err = c.checkExternal(pin)
+	if err != nil {
+		return err
+	}
+	err = c.checkClaim(owner, pin)
+	if err != nil {
+		return err
+	}
+	if pf.output == (mode == Output) {
+		return nil
+	}
+
+	err = setDirection(c.basePath, strconv.Itoa(pin), DirectionToString(mode))
+	if err != nil {
+		return c.pinFileError(pin, err)
+	}
+	pf.output = mode == Output
+	if pf.output && !pf.writable {
+		c.closePin(pin)
+	}
+	return nil
 }
 
-func (c *controller) GetValue(pin int) (int, error) {
-	logrus.Traceln("gpio.controller.GetValue()")
-	pinString := strconv.Itoa(pin)
+// lockPins locks given pins in ascending order (to avoid deadlocks between
+// overlapping bulk operations) and returns function unlocking them
+func (c *controller) lockPins(pins ...int) func() {
+	sorted := append([]int{}, pins...)
+	sort.Ints(sorted)
+
+	locks := make([]*sync.Mutex, 0, len(sorted))
+	c.mtx.Lock()
+	for i, pin := range sorted {
+		if i > 0 && sorted[i-1] == pin {
+			continue
+		}
+		lock, exists := c.pinLocks[pin]
+		if !exists {
+			lock = &sync.Mutex{}
+			c.pinLocks[pin] = lock
+		}
+		locks = append(locks, lock)
+	}
+	c.mtx.Unlock()
 
-	if !isExported(pinString) {
-		return -1, ErrNotExported
+	for _, lock := range locks {
+		lock.Lock()
+	}
+	return func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+	}
+}
+
+// checkOutput validates value, pin direction, ownership and claim returning pin file to write value to,
+// must be called with pin lock held
+func (c *controller) checkOutput(owner string, pin, value int) (*pinFile, error) {
+	if value != 0 && value != 1 {
+		return nil, ErrInvalidValue
 	}
 
-	valString, err := getValue(pinString)
+	pf, err := c.openPin(pin)
 	if err != nil {
-		logrus.Errorln("Failed to get value:", err)
-		return -1, err
+		return nil, err
+	}
+	err = c.checkExternal(pin)
+	if err != nil {
+		return nil, err
 	}
+	err = c.checkClaim(owner, pin)
+	if err != nil {
+		return nil, err
+	}
+	if !pf.output {
+		return nil, ErrInvalidDirection
+	}
+	return pf, nil
+}
 
-	valInt, err := strconv.Atoi(valString)
+func (c *controller) GetValue(pin int) (int, error) {
+	logrus.Traceln("gpio.controller.GetValue()")
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	pf, err := c.openPin(pin)
 	if err != nil {
-		logrus.Errorln("Failed to convert value:", err)
-		return -1, ErrUnknown
+		return -1, err
 	}
 
-	return valInt, nil
+	if value, watched := c.events.stableValue(pin); watched {
+		return value, nil
+	}
+
+	filter, filtered := c.filter(pin)
+	if filtered {
+		return readSettled(func() (int, error) { return c.readPin(pin, pf) }, filter)
+	}
+	return c.readPin(pin, pf)
 }
 
 func (c *controller) ExportPin(pin int, mode Direction) error {
@@ -68,45 +205,108 @@ func (c *controller) ExportPin(pin int, mode Direction) error {
 	}
 	pinString := strconv.Itoa(pin)
 
-	if isExported(pinString) {
+	c.exportMtx.Lock()
+	defer c.exportMtx.Unlock()
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	if isExported(c.basePath, pinString) {
+		// pin exported by other process can not be reused by drivers until adopted
+		if c.checkExternal(pin) != nil {
+			return ErrExternalPin
+		}
 		return ErrAlreadyExported
 	}
+	// file (and claims) could be left if pin was unexported by someone else
+	c.closePin(pin)
+	c.mtx.Lock()
+	delete(c.claims, pin)
+	delete(c.owned, pin)
+	c.mtx.Unlock()
 
-	ErrInvalidValue := exportPin(pinString)
-	if ErrInvalidValue != nil {
+	err := exportPin(c.basePath, pinString)
+	if err == nil {
+		err = waitForPin(c.basePath, pinString, true)
+	}
+	if err != nil {
 		return ErrUnknown
 	}
 
-	ErrInvalidValue = setDirection(pinString, DirectionToString(mode))
-	if ErrInvalidValue != nil {
-		unexportPin(pinString)
+	err = setDirection(c.basePath, pinString, DirectionToString(mode))
+	if err != nil {
+		c.unexport(pinString)
+		return err
 	}
 
-	return ErrInvalidValue
+	c.mtx.Lock()
+	c.owned[pin] = true
+	c.mtx.Unlock()
+	return nil
 }
 
 func (c *controller) UnexportPin(pin int) error {
+	return c.unexportPin(noOwner, pin)
+}
+
+// unexportPin removes pin with all its claims, pin claimed by anyone else than owner
+// or exported by other process (and not adopted) is refused
+func (c *controller) unexportPin(owner string, pin int) error {
 	logrus.Traceln("gpio.controller.UnexportPin()")
 	pinString := strconv.Itoa(pin)
-	if !isExported(pinString) {
+
+	c.exportMtx.Lock()
+	defer c.exportMtx.Unlock()
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	if !isExported(c.basePath, pinString) {
 		return ErrNotExported
 	}
+	err := c.checkExternal(pin)
+	if err != nil {
+		return err
+	}
+	if c.claimedByOthers(owner, pin) {
+		return ErrPinClaimed
+	}
+
+	c.events.remove(pin)
+	c.closePin(pin)
+	c.mtx.Lock()
+	delete(c.filters, pin)
+	delete(c.counters, pin)
+	delete(c.captures, pin)
+	delete(c.claims, pin)
+	delete(c.owned, pin)
+	c.mtx.Unlock()
+
+	return c.unexport(pinString)
+}
 
-	return unexportPin(pinString)
+// unexport removes pin directory, must be called with export mutex locked
+func (c *controller) unexport(pinString string) error {
+	err := unexportPin(c.basePath, pinString)
+	if err != nil {
+		return err
+	}
+	return waitForPin(c.basePath, pinString, false)
 }
 
 func (c *controller) ListExportedPins() (map[int]Direction, error) {
 	logrus.Traceln("gpio.controller.ListExportedPins()")
 	result := map[int]Direction{}
 
-	pins, err := listExported()
+	c.exportMtx.Lock()
+	defer c.exportMtx.Unlock()
+
+	pins, err := listExported(c.basePath)
 	if err != nil {
 		return map[int]Direction{}, ErrUnknown
 	}
 	logrus.Debug("Currently detected pins:", pins)
 
 	for _, pin := range pins {
-		isOut, err := isOutput(pin)
+		isOut, err := isOutput(c.basePath, pin)
 		if err != nil {
 			logrus.Warn("Error while checking direction for one of pins:", err)
 			return map[int]Direction{}, ErrUnknown
@@ -121,7 +321,322 @@ func (c *controller) ListExportedPins() (map[int]Direction, error) {
 	return result, nil
 }
 
-func CreateController(gpioPath string) Controller {
+// SetFilter configures debouncing of input pin used both for events and GetValue
+// Zero filter disables filtering
+func (c *controller) SetFilter(pin int, filter Filter) error {
+	logrus.Traceln("gpio.controller.SetFilter()")
+	if filter.Debounce < 0 || filter.MinStable < 0 {
+		return ErrInvalidValue
+	}
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	err := c.checkInput(pin)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	if filter == (Filter{}) {
+		delete(c.filters, pin)
+	} else {
+		c.filters[pin] = filter
+	}
+	c.mtx.Unlock()
+
+	c.events.setFilter(pin, filter)
+	return nil
+}
+
+// Subscribe starts delivering filtered value changes of input pin
+// Returned channel is closed on Unsubscribe or when pin gets unexported
+func (c *controller) Subscribe(pin int) (<-chan Event, error) {
+	logrus.Traceln("gpio.controller.Subscribe()")
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	err := c.checkInput(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, _ := c.filter(pin)
+	return c.events.subscribe(pin, filter)
+}
+
+// subscribe starts watching pin on behalf of owner (ex. for counter), pin claimed exclusively
+// by anyone else or exported by other process is refused
+func (c *controller) subscribe(owner string, pin int) (<-chan Event, error) {
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	err := c.checkInput(pin)
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkExternal(pin)
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkClaim(owner, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, _ := c.filter(pin)
+	return c.events.subscribe(pin, filter)
+}
+
+func (c *controller) Unsubscribe(pin int, events <-chan Event) {
+	logrus.Traceln("gpio.controller.Unsubscribe()")
+	c.events.unsubscribe(pin, events)
+}
+
+// SubscribeAll starts delivering events of all watched pins and all published device events
+// It does not start watching any pin on its own
+func (c *controller) SubscribeAll() <-chan Event {
+	logrus.Traceln("gpio.controller.SubscribeAll()")
+	return c.events.subscribeAll()
+}
+
+func (c *controller) UnsubscribeAll(events <-chan Event) {
+	logrus.Traceln("gpio.controller.UnsubscribeAll()")
+	c.events.unsubscribeAll(events)
+}
+
+// Publish delivers event generated by device built on top of pins to all SubscribeAll receivers
+func (c *controller) Publish(event Event) {
+	logrus.Traceln("gpio.controller.Publish()")
+	c.events.publish(event)
+}
+
+// StartCounter starts counting given edges on input pin, zero window means default one
+func (c *controller) StartCounter(pin int, edge Edge, window time.Duration) error {
+	return c.startCounter(noOwner, pin, edge, window)
+}
+
+func (c *controller) startCounter(owner string, pin int, edge Edge, window time.Duration) error {
+	logrus.Traceln("gpio.controller.StartCounter()")
+	if edge != Rising && edge != Falling && edge != Both {
+		return ErrInvalidEdge
+	}
+	if window < 0 {
+		return ErrInvalidValue
+	}
+	if window == 0 {
+		window = DefaultCounterWindow
+	}
+
+	c.mtx.Lock()
+	_, running := c.counters[pin]
+	c.mtx.Unlock()
+	if running {
+		return ErrAlreadyRunning
+	}
+
+	events, err := c.subscribe(owner, pin)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, running = c.counters[pin]; running {
+		c.events.unsubscribe(pin, events)
+		return ErrAlreadyRunning
+	}
+	c.counters[pin] = newCounter(pin, edge, window, events)
+	return nil
+}
+
+func (c *controller) StopCounter(pin int) error {
+	return c.stopCounter(noOwner, pin)
+}
+
+func (c *controller) stopCounter(owner string, pin int) error {
+	logrus.Traceln("gpio.controller.StopCounter()")
+	err := c.checkOwner(owner, pin)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	cn, running := c.counters[pin]
+	delete(c.counters, pin)
+	c.mtx.Unlock()
+
+	if !running {
+		return ErrNotRunning
+	}
+	c.events.unsubscribe(pin, cn.events)
+	return nil
+}
+
+// ResetCounter clears counter and returns its state from before reset
+func (c *controller) ResetCounter(pin int) (CounterState, error) {
+	return c.resetCounter(noOwner, pin)
+}
+
+func (c *controller) resetCounter(owner string, pin int) (CounterState, error) {
+	logrus.Traceln("gpio.controller.ResetCounter()")
+	err := c.checkOwner(owner, pin)
+	if err != nil {
+		return CounterState{}, err
+	}
+
+	cn, err := c.counter(pin)
+	if err != nil {
+		return CounterState{}, err
+	}
+	return cn.reset(time.Now()), nil
+}
+
+func (c *controller) GetCounter(pin int) (CounterState, error) {
+	logrus.Traceln("gpio.controller.GetCounter()")
+	cn, err := c.counter(pin)
+	if err != nil {
+		return CounterState{}, err
+	}
+	return cn.state(time.Now()), nil
+}
+
+func (c *controller) counter(pin int) (*counter, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cn, running := c.counters[pin]
+	if !running {
+		return nil, ErrNotRunning
+	}
+	return cn, nil
+}
+
+// StartCapture starts measuring high and low pulse widths on input pin
+// Timestamps come from sysfs edge notifications so resolution is limited by system latency
+func (c *controller) StartCapture(pin int) error {
+	return c.startCapture(noOwner, pin)
+}
+
+func (c *controller) startCapture(owner string, pin int) error {
+	logrus.Traceln("gpio.controller.StartCapture()")
+	c.mtx.Lock()
+	_, running := c.captures[pin]
+	c.mtx.Unlock()
+	if running {
+		return ErrAlreadyRunning
+	}
+
+	events, err := c.subscribe(owner, pin)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, running = c.captures[pin]; running {
+		c.events.unsubscribe(pin, events)
+		return ErrAlreadyRunning
+	}
+	c.captures[pin] = newCapture(pin, events)
+	return nil
+}
+
+func (c *controller) StopCapture(pin int) error {
+	return c.stopCapture(noOwner, pin)
+}
+
+func (c *controller) stopCapture(owner string, pin int) error {
+	logrus.Traceln("gpio.controller.StopCapture()")
+	err := c.checkOwner(owner, pin)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	cp, running := c.captures[pin]
+	delete(c.captures, pin)
+	c.mtx.Unlock()
+
+	if !running {
+		return ErrNotRunning
+	}
+	c.events.unsubscribe(pin, cp.events)
+	return nil
+}
+
+func (c *controller) GetCapture(pin int) (CaptureState, error) {
+	logrus.Traceln("gpio.controller.GetCapture()")
+	c.mtx.Lock()
+	cp, running := c.captures[pin]
+	c.mtx.Unlock()
+
+	if !running {
+		return CaptureState{}, ErrNotRunning
+	}
+	return cp.state(), nil
+}
+
+// TriggerAndMeasure sends pulse on trigger output and returns width of high pulse on echo input
+// (ex. HC-SR04 range finder), timeout limits waiting for the whole echo pulse
+func (c *controller) TriggerAndMeasure(trigger, echo int, pulse, timeout time.Duration) (time.Duration, error) {
+	return c.triggerAndMeasure(noOwner, trigger, echo, pulse, timeout)
+}
+
+func (c *controller) triggerAndMeasure(owner string, trigger, echo int, pulse, timeout time.Duration) (time.Duration, error) {
+	logrus.Traceln("gpio.controller.TriggerAndMeasure()")
+	if pulse <= 0 || timeout <= 0 {
+		return 0, ErrInvalidValue
+	}
+
+	events, err := c.subscribe(owner, echo)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Unsubscribe(echo, events)
+
+	err = c.setValue(owner, trigger, 1)
+	if err != nil {
+		return 0, err
+	}
+	time.Sleep(pulse)
+	err = c.setValue(owner, trigger, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	return measureEcho(events, timeout)
+}
+
+// checkOwner refuses stopping or resetting measurements on pin claimed exclusively by anyone else than owner
+func (c *controller) checkOwner(owner string, pin int) error {
+	unlock := c.lockPins(pin)
+	defer unlock()
+	return c.checkClaim(owner, pin)
+}
+
+// checkInput must be called with pin lock held
+func (c *controller) checkInput(pin int) error {
+	pf, err := c.openPin(pin)
+	if err != nil {
+		return err
+	}
+	if pf.output {
+		return ErrInvalidDirection
+	}
+	return nil
+}
+
+func (c *controller) filter(pin int) (Filter, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	filter, exists := c.filters[pin]
+	return filter, exists
+}
+
+// CreateController creates controller of pins exported in gpioPath, character devices of GPIO chips
+// (used only for describing lines) are looked up in devPath
+func CreateController(gpioPath, devPath string) FullController {
 	logrus.Traceln("gpio.CreateController()")
-	return &controller{basePath: gpioPath}
+	return &controller{basePath: gpioPath, devPath: devPath, events: newDispatcher(gpioPath), pinLocks: map[int]*sync.Mutex{},
+		files: map[int]*pinFile{}, filters: map[int]Filter{}, counters: map[int]*counter{}, captures: map[int]*capture{},
+		claims: map[int]*claim{}, owned: map[int]bool{}}
 }
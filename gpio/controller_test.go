@@ -0,0 +1,290 @@
+package gpio
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stressWorkers = 32
+
+// newFakeSysfs creates temporary GPIO sysfs tree with export and unexport files
+// being FIFOs served by goroutines emulating kernel behaviour
+func newFakeSysfs(tb testing.TB) string {
+	base := tb.TempDir()
+	serveFifo(tb, filepath.Join(base, "export"), func(pin string) {
+		pinDir := filepath.Join(base, "gpio"+pin)
+		tmpDir := pinDir + ".tmp"
+		os.Mkdir(tmpDir, 0755)
+		os.WriteFile(filepath.Join(tmpDir, "direction"), []byte("in\n"), 0644)
+		os.WriteFile(filepath.Join(tmpDir, "value"), []byte("0\n"), 0644)
+		os.WriteFile(filepath.Join(tmpDir, "edge"), []byte("none\n"), 0644)
+		os.Rename(tmpDir, pinDir)
+	})
+	serveFifo(tb, filepath.Join(base, "unexport"), func(pin string) {
+		os.RemoveAll(filepath.Join(base, "gpio"+pin))
+	})
+	return base
+}
+
+func serveFifo(tb testing.TB, path string, handle func(pin string)) {
+	require.NoError(tb, syscall.Mkfifo(path, 0600))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			fifo, err := os.Open(path)
+			if err != nil {
+				return
+			}
+			data, _ := io.ReadAll(fifo)
+			fifo.Close()
+			for _, pin := range strings.Fields(string(data)) {
+				if pin == "stop" {
+					return
+				}
+				handle(pin)
+			}
+		}
+	}()
+	tb.Cleanup(func() {
+		os.WriteFile(path, []byte("stop"), 0600)
+		<-done
+	})
+}
+
+func TestControllerFakeSysfs(t *testing.T) {
+	ctrl := CreateController(newFakeSysfs(t), "/dev")
+
+	t.Run("export and unexport", func(t *testing.T) {
+		require.NoError(t, ctrl.ExportPin(4, Output))
+		assert.Equal(t, ErrAlreadyExported, ctrl.ExportPin(4, Input))
+
+		pins, err := ctrl.ListExportedPins()
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]Direction{4: Output}, pins)
+
+		require.NoError(t, ctrl.SetValue(4, 1))
+		value, err := ctrl.GetValue(4)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+
+		require.NoError(t, ctrl.UnexportPin(4))
+		assert.Equal(t, ErrNotExported, ctrl.UnexportPin(4))
+		assert.Equal(t, ErrNotExported, ctrl.SetValue(4, 1))
+	})
+
+	t.Run("set direction", func(t *testing.T) {
+		assert.Equal(t, ErrNotExported, ctrl.SetDirection(5, Output))
+		require.NoError(t, ctrl.ExportPin(5, Input))
+		assert.Equal(t, ErrInvalidDirection, ctrl.SetValue(5, 1))
+
+		require.NoError(t, ctrl.SetDirection(5, Output))
+		assert.NoError(t, ctrl.SetValue(5, 1))
+		pins, err := ctrl.ListExportedPins()
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]Direction{5: Output}, pins)
+
+		require.NoError(t, ctrl.SetDirection(5, Input))
+		assert.Equal(t, ErrInvalidDirection, ctrl.SetValue(5, 0))
+		require.NoError(t, ctrl.UnexportPin(5))
+	})
+
+	t.Run("get values - some pins failed", func(t *testing.T) {
+		require.NoError(t, ctrl.ExportPin(6, Output))
+		require.NoError(t, ctrl.SetValue(6, 1))
+
+		values, err := ctrl.GetValues([]int{6, 7})
+		assert.Equal(t, PinErrors{7: ErrNotExported}, err)
+		assert.Equal(t, map[int]int{6: 1}, values, "values of other pins should be kept")
+		require.NoError(t, ctrl.UnexportPin(6))
+	})
+}
+
+// Tests below are meant to be executed with -race flag
+
+func TestConcurrentExport(t *testing.T) {
+	ctrl := CreateController(newFakeSysfs(t), "/dev")
+
+	results := make(chan error, stressWorkers)
+	var wg sync.WaitGroup
+	for i := 0; i < stressWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- ctrl.ExportPin(7, Output)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, ErrAlreadyExported, err)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	results = make(chan error, stressWorkers)
+	for i := 0; i < stressWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- ctrl.UnexportPin(7)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded = 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, ErrNotExported, err)
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestConcurrentToggle(t *testing.T) {
+	ctrl := CreateController(newFakeSysfs(t), "/dev")
+	require.NoError(t, ctrl.ExportPin(3, Output))
+	require.NoError(t, ctrl.SetValue(3, 0))
+
+	const toggles = 25
+	var wg sync.WaitGroup
+	for i := 0; i < stressWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < toggles; j++ {
+				_, err := ctrl.Toggle(3)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, err := ctrl.GetValue(3)
+	assert.NoError(t, err)
+	assert.Equal(t, (stressWorkers*toggles)%2, value)
+}
+
+func TestConcurrentMixedOperations(t *testing.T) {
+	base := newFakeSysfs(t)
+	ctrl := CreateController(base, "/dev")
+
+	const pins = 4
+	const rounds = 20
+	expected := map[error]bool{nil: true, ErrAlreadyExported: true, ErrNotExported: true, ErrInvalidDirection: true}
+
+	var wg sync.WaitGroup
+	for i := 0; i < stressWorkers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				pin := (worker + j) % pins
+				var err error
+				switch (worker * j) % 5 {
+				case 0:
+					err = ctrl.ExportPin(pin, Output)
+				case 1:
+					err = ctrl.UnexportPin(pin)
+				case 2:
+					err = ctrl.SetValue(pin, j%2)
+				case 3:
+					_, err = ctrl.Toggle(pin)
+				default:
+					_, err = ctrl.ListExportedPins()
+				}
+				assert.True(t, expected[err], "unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	listed, err := ctrl.ListExportedPins()
+	require.NoError(t, err)
+	for pin := 0; pin < pins; pin++ {
+		_, exists := listed[pin]
+		_, statErr := os.Stat(filepath.Join(base, "gpio"+strconv.Itoa(pin)))
+		assert.Equal(t, statErr == nil, exists)
+	}
+}
+
+// uncachedSetValue repeats sysfs accesses done for each SetValue before value files were cached
+func uncachedSetValue(basePath, pin string, value int) error {
+	if !isExported(basePath, pin) {
+		return ErrNotExported
+	}
+	out, err := isOutput(basePath, pin)
+	if err != nil || !out {
+		return ErrInvalidDirection
+	}
+	fValue, err := os.OpenFile(pinPath(basePath, pin)+pathValueSuffix, os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return ErrUnknown
+	}
+	defer fValue.Close()
+	_, err = fValue.WriteString(strconv.Itoa(value))
+	return err
+}
+
+func BenchmarkSetValueUncached(b *testing.B) {
+	base := newFakeSysfs(b)
+	ctrl := CreateController(base, "/dev")
+	require.NoError(b, ctrl.ExportPin(2, Output))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := uncachedSetValue(base, "2", i%2)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	ctrl.UnexportPin(2)
+}
+
+func BenchmarkSetValue(b *testing.B) {
+	ctrl := CreateController(newFakeSysfs(b), "/dev")
+	require.NoError(b, ctrl.ExportPin(2, Output))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := ctrl.SetValue(2, i%2)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	ctrl.UnexportPin(2)
+}
+
+func BenchmarkToggle(b *testing.B) {
+	ctrl := CreateController(newFakeSysfs(b), "/dev")
+	require.NoError(b, ctrl.ExportPin(2, Output))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ctrl.Toggle(2)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	ctrl.UnexportPin(2)
+}
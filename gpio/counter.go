@@ -0,0 +1,79 @@
+package gpio
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCounterWindow - sliding window used for rate calculation if not given
+	DefaultCounterWindow = time.Second
+	// maxCounterSamples limits memory used for rate calculation on high frequency inputs
+	maxCounterSamples = 100000
+)
+
+// counter counts edges delivered by event subscription
+type counter struct {
+	pin    int
+	edge   Edge
+	window time.Duration
+	events <-chan Event
+
+	mtx   sync.Mutex
+	count uint64
+	times []time.Time
+}
+
+func newCounter(pin int, edge Edge, window time.Duration, events <-chan Event) *counter {
+	result := &counter{pin: pin, edge: edge, window: window, events: events}
+	go result.run()
+	return result
+}
+
+// run consumes events until subscription gets closed
+func (cn *counter) run() {
+	for event := range cn.events {
+		if !cn.edge.Matches(event.Value) {
+			continue
+		}
+		cn.mtx.Lock()
+		cn.count++
+		cn.times = append(cn.times, event.Time)
+		if len(cn.times) > maxCounterSamples {
+			cn.times = cn.times[len(cn.times)-maxCounterSamples:]
+		}
+		cn.mtx.Unlock()
+	}
+}
+
+func (cn *counter) state(now time.Time) CounterState {
+	cn.mtx.Lock()
+	defer cn.mtx.Unlock()
+	return cn.stateLocked(now)
+}
+
+func (cn *counter) reset(now time.Time) CounterState {
+	cn.mtx.Lock()
+	defer cn.mtx.Unlock()
+	result := cn.stateLocked(now)
+	cn.count = 0
+	cn.times = nil
+	return result
+}
+
+func (cn *counter) stateLocked(now time.Time) CounterState {
+	limit := now.Add(-cn.window)
+	first := 0
+	for first < len(cn.times) && cn.times[first].Before(limit) {
+		first++
+	}
+	cn.times = cn.times[first:]
+
+	return CounterState{
+		Pin:    cn.pin,
+		Edge:   cn.edge,
+		Window: cn.window,
+		Count:  cn.count,
+		Rate:   float64(len(cn.times)) / cn.window.Seconds(),
+	}
+}
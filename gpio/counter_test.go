@@ -0,0 +1,30 @@
+package gpio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	events := make(chan Event)
+	cn := newCounter(3, Rising, time.Second, events)
+	start := time.Now()
+
+	for i := 0; i < 10; i++ {
+		at := start.Add(time.Duration(i) * 200 * time.Millisecond)
+		events <- Event{Pin: 3, Value: 1, Time: at}
+		events <- Event{Pin: 3, Value: 0, Time: at.Add(100 * time.Millisecond)}
+	}
+	close(events)
+
+	assert.Eventually(t, func() bool { return cn.state(start).Count == 10 }, time.Second, time.Millisecond)
+
+	state := cn.state(start.Add(1900 * time.Millisecond))
+	assert.Equal(t, CounterState{Pin: 3, Edge: Rising, Window: time.Second, Count: 10, Rate: 5}, state)
+
+	state = cn.reset(start.Add(1900 * time.Millisecond))
+	assert.Equal(t, uint64(10), state.Count)
+	assert.Equal(t, CounterState{Pin: 3, Edge: Rising, Window: time.Second}, cn.state(start))
+}
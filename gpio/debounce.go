@@ -0,0 +1,86 @@
+package gpio
+
+import (
+	"time"
+)
+
+// maxSettleTime limits how long GetValue waits for filtered input to settle
+const maxSettleTime = time.Second
+
+// debouncer applies Filter to raw values observed on input pin
+type debouncer struct {
+	filter     Filter
+	stable     int
+	raw        int
+	lastAccept time.Time
+	lastChange time.Time
+}
+
+func newDebouncer(filter Filter, initial int, at time.Time) *debouncer {
+	return &debouncer{filter: filter, stable: initial, raw: initial, lastChange: at}
+}
+
+// update processes raw value observed at given time
+// It returns true if value should be accepted as new stable one, otherwise it may
+// return non-zero delay after which value has to be checked again
+func (d *debouncer) update(value int, at time.Time) (bool, time.Duration) {
+	if value != d.raw {
+		d.raw = value
+		d.lastChange = at
+	}
+	if value == d.stable {
+		return false, 0
+	}
+
+	if stableFor := at.Sub(d.lastChange); stableFor < d.filter.MinStable {
+		return false, d.filter.MinStable - stableFor
+	}
+	if !d.lastAccept.IsZero() {
+		if since := at.Sub(d.lastAccept); since < d.filter.Debounce {
+			return false, d.filter.Debounce - since
+		}
+	}
+
+	d.stable = value
+	d.lastAccept = at
+	return true, 0
+}
+
+// settleTime returns how long value read without event tracking has to stay unchanged
+func (f Filter) settleTime() time.Duration {
+	if f.MinStable > f.Debounce {
+		return f.MinStable
+	}
+	return f.Debounce
+}
+
+// readSettled reads value repeatedly until it stays unchanged for filter settle time
+func readSettled(read func() (int, error), filter Filter) (int, error) {
+	settle := filter.settleTime()
+	interval := settle / 8
+	if interval < 100*time.Microsecond {
+		interval = 100 * time.Microsecond
+	}
+
+	value, err := read()
+	if err != nil {
+		return -1, err
+	}
+	start := time.Now()
+	since := start
+	for time.Since(since) < settle {
+		if time.Since(start) > maxSettleTime {
+			return -1, ErrUnstableValue
+		}
+		time.Sleep(interval)
+		current, err := read()
+		if err != nil {
+			return -1, err
+		}
+		if current != value {
+			value = current
+			since = time.Now()
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,86 @@
+package gpio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebouncer(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	t.Run("no filter - every change accepted", func(t *testing.T) {
+		d := newDebouncer(Filter{}, 0, start)
+		accepted, _ := d.update(1, at(1))
+		assert.True(t, accepted)
+		accepted, _ = d.update(1, at(2))
+		assert.False(t, accepted)
+		accepted, _ = d.update(0, at(3))
+		assert.True(t, accepted)
+	})
+
+	t.Run("debounce window - bounces ignored", func(t *testing.T) {
+		d := newDebouncer(Filter{Debounce: 10 * time.Millisecond}, 0, start)
+		accepted, _ := d.update(1, at(1))
+		assert.True(t, accepted)
+
+		accepted, recheck := d.update(0, at(3))
+		assert.False(t, accepted)
+		assert.Equal(t, 8*time.Millisecond, recheck)
+
+		accepted, _ = d.update(1, at(4))
+		assert.False(t, accepted)
+		assert.Equal(t, 1, d.stable)
+
+		accepted, _ = d.update(0, at(12))
+		assert.True(t, accepted)
+	})
+
+	t.Run("minimum stable duration - glitch filtered", func(t *testing.T) {
+		d := newDebouncer(Filter{MinStable: 5 * time.Millisecond}, 0, start)
+		accepted, recheck := d.update(1, at(1))
+		assert.False(t, accepted)
+		assert.Equal(t, 5*time.Millisecond, recheck)
+
+		accepted, recheck = d.update(0, at(2))
+		assert.False(t, accepted)
+		assert.Zero(t, recheck)
+
+		accepted, _ = d.update(1, at(3))
+		assert.False(t, accepted)
+		accepted, _ = d.update(1, at(7))
+		assert.False(t, accepted)
+		accepted, _ = d.update(1, at(8))
+		assert.True(t, accepted)
+	})
+}
+
+func TestReadSettled(t *testing.T) {
+	filter := Filter{MinStable: 2 * time.Millisecond}
+
+	t.Run("value settles after bounces", func(t *testing.T) {
+		samples := []int{1, 0, 1, 0, 0}
+		read := func() (int, error) {
+			value := samples[0]
+			if len(samples) > 1 {
+				samples = samples[1:]
+			}
+			return value, nil
+		}
+		value, err := readSettled(read, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, value)
+	})
+
+	t.Run("value never settles", func(t *testing.T) {
+		value := 0
+		read := func() (int, error) {
+			value = 1 - value
+			return value, nil
+		}
+		_, err := readSettled(read, filter)
+		assert.Equal(t, ErrUnstableValue, err)
+	})
+}
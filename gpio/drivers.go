@@ -0,0 +1,67 @@
+package gpio
+
+import "github.com/sirupsen/logrus"
+
+// CheckDirection verifies that pin already exported by someone else has direction required by driver
+// reusing it, drivers must not change direction of pins they did not export
+func CheckDirection(ctrl Controller, pin int, mode Direction) error {
+	pins, err := ctrl.ListExportedPins()
+	if err != nil {
+		return err
+	}
+	current, exported := pins[pin]
+	if !exported {
+		return ErrNotExported
+	}
+	if current != mode {
+		logrus.Debugf("Already exported pin '%d' has direction other than required\n", pin)
+		return ErrInvalidDirection
+	}
+	return nil
+}
+
+// ClaimPins exports pins used by driver and claims them exclusively, already exported pins are reused
+// if their direction matches, returned map tells which pins were exported by call (to unexport them on release)
+// On error all pins claimed so far are released
+func ClaimPins(ctrl Owned, pins map[int]Direction) (map[int]bool, error) {
+	result := map[int]bool{}
+	for pin, mode := range pins {
+		exported := true
+		err := ctrl.ExportPin(pin, mode)
+		if err == ErrAlreadyExported {
+			exported = false
+			err = CheckDirection(ctrl, pin, mode)
+		}
+		if err == nil {
+			err = ctrl.Claim(pin, true)
+			if err != nil && exported {
+				ctrl.UnexportPin(pin)
+			}
+		}
+		if err != nil {
+			ReleasePins(ctrl, result)
+			return nil, err
+		}
+		result[pin] = exported
+	}
+	return result, nil
+}
+
+// ReleasePins gives back pins claimed with ClaimPins, pins exported by driver are unexported
+// and reused ones are only released
+func ReleasePins(ctrl Owned, pins map[int]bool) error {
+	var result error
+	for pin, exported := range pins {
+		var err error
+		if exported {
+			err = ctrl.UnexportPin(pin)
+		} else {
+			err = ctrl.Release(pin)
+		}
+		if err != nil {
+			logrus.Warnf("Failed to release driver pin '%d': %v\n", pin, err)
+			result = err
+		}
+	}
+	return result
+}
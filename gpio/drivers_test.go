@@ -0,0 +1,42 @@
+package gpio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimPins(t *testing.T) {
+	ctrl := CreateController(newFakeSysfs(t), "/dev")
+	driver := ctrl.WithOwner(DriverOwner("stepper", "x"))
+	require.NoError(t, ctrl.ExportPin(4, Output))
+	require.NoError(t, ctrl.ExportPin(5, Input))
+
+	t.Run("already exported pin with other direction", func(t *testing.T) {
+		_, err := ClaimPins(driver, map[int]Direction{4: Output, 5: Output, 6: Output})
+		assert.Equal(t, ErrInvalidDirection, err)
+		assert.Empty(t, ctrl.ListClaims())
+		pins, err := ctrl.ListExportedPins()
+		require.NoError(t, err)
+		assert.Equal(t, map[int]Direction{4: Output, 5: Input}, pins)
+	})
+
+	t.Run("claim and release", func(t *testing.T) {
+		claimed, err := ClaimPins(driver, map[int]Direction{4: Output, 6: Output})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]bool{4: false, 6: true}, claimed)
+		assert.Equal(t, ErrPinClaimed, ctrl.SetValue(4, 1))
+		assert.Equal(t, ErrPinClaimed, ctrl.UnexportPin(6))
+		assert.NoError(t, driver.SetValue(6, 1))
+
+		_, err = ClaimPins(ctrl.WithOwner("alice"), map[int]Direction{6: Output})
+		assert.Equal(t, ErrPinClaimed, err)
+
+		assert.NoError(t, ReleasePins(driver, claimed))
+		assert.Empty(t, ctrl.ListClaims())
+		pins, err := ctrl.ListExportedPins()
+		require.NoError(t, err)
+		assert.Equal(t, map[int]Direction{4: Output, 5: Input}, pins, "reused pin should stay exported")
+	})
+}
@@ -1,6 +1,11 @@
 package gpio
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
 
 var (
 	ErrInvalidDirection = errors.New("invalid direction")
@@ -10,4 +15,31 @@ var (
 	ErrInvalidPin       = errors.New("invalid pin")
 	ErrInvalidValue     = errors.New("invalid value")
 	ErrNotImplemented   = errors.New("not implemented")
+	ErrUnstableValue    = errors.New("value not stable")
+	ErrInvalidEdge      = errors.New("invalid edge")
+	ErrAlreadyRunning   = errors.New("already running")
+	ErrNotRunning       = errors.New("not running")
+	ErrTimeout          = errors.New("timeout")
+	ErrPinClaimed       = errors.New("pin claimed by other owner")
+	ErrNotClaimed       = errors.New("pin not claimed by owner")
+	ErrNoOwner          = errors.New("owner not given")
+	ErrExternalPin      = errors.New("pin exported by other process")
+	ErrAlreadyAdopted   = errors.New("pin already owned")
 )
+
+// PinErrors collects errors of bulk operation for each failed pin
+type PinErrors map[int]error
+
+func (pe PinErrors) Error() string {
+	pins := make([]int, 0, len(pe))
+	for pin := range pe {
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+
+	parts := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		parts = append(parts, fmt.Sprintf("pin %d: %v", pin, pe[pin]))
+	}
+	return strings.Join(parts, ", ")
+}
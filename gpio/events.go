@@ -0,0 +1,277 @@
+package gpio
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	eventQueueSize   = 64
+	epollTimeoutMsec = 100
+)
+
+// watchedPin keeps state of single input pin observed for edge events
+type watchedPin struct {
+	pin         int
+	file        *os.File
+	filter      *debouncer
+	timer       *time.Timer
+	subscribers []chan Event
+}
+
+// dispatcher waits for sysfs edge notifications (POLLPRI on value file) and
+// delivers filtered events to subscribers
+type dispatcher struct {
+	basePath string
+
+	mtx     sync.Mutex
+	epfd    int
+	pins    map[int]*watchedPin
+	fds     map[int32]*watchedPin
+	all     []chan Event
+	running bool
+}
+
+func newDispatcher(basePath string) *dispatcher {
+	return &dispatcher{basePath: basePath, epfd: -1, pins: map[int]*watchedPin{}, fds: map[int32]*watchedPin{}}
+}
+
+func (d *dispatcher) subscribe(pin int, filter Filter) (<-chan Event, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	wp, exists := d.pins[pin]
+	if !exists {
+		var err error
+		wp, err = d.watch(pin, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	events := make(chan Event, eventQueueSize)
+	wp.subscribers = append(wp.subscribers, events)
+
+	if !d.running {
+		d.running = true
+		go d.loop()
+	}
+	return events, nil
+}
+
+func (d *dispatcher) unsubscribe(pin int, events <-chan Event) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	wp, exists := d.pins[pin]
+	if !exists {
+		return
+	}
+	for i, sub := range wp.subscribers {
+		if (<-chan Event)(sub) == events {
+			close(sub)
+			wp.subscribers = append(wp.subscribers[:i], wp.subscribers[i+1:]...)
+			break
+		}
+	}
+	if len(wp.subscribers) == 0 {
+		d.unwatch(wp)
+	}
+}
+
+// subscribeAll returns channel receiving events of all watched pins and all published events
+func (d *dispatcher) subscribeAll() <-chan Event {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	events := make(chan Event, eventQueueSize)
+	d.all = append(d.all, events)
+	return events
+}
+
+func (d *dispatcher) unsubscribeAll(events <-chan Event) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for i, sub := range d.all {
+		if (<-chan Event)(sub) == events {
+			close(sub)
+			d.all = append(d.all[:i], d.all[i+1:]...)
+			return
+		}
+	}
+}
+
+func (d *dispatcher) publish(event Event) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.deliver(d.all, event)
+}
+
+// remove stops watching pin and closes all its subscriptions
+func (d *dispatcher) remove(pin int) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	wp, exists := d.pins[pin]
+	if !exists {
+		return
+	}
+	for _, sub := range wp.subscribers {
+		close(sub)
+	}
+	wp.subscribers = nil
+	d.unwatch(wp)
+}
+
+func (d *dispatcher) setFilter(pin int, filter Filter) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if wp, exists := d.pins[pin]; exists {
+		wp.filter.filter = filter
+	}
+}
+
+// stableValue returns last accepted value of watched pin
+func (d *dispatcher) stableValue(pin int) (int, bool) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	wp, exists := d.pins[pin]
+	if !exists {
+		return -1, false
+	}
+	return wp.filter.stable, true
+}
+
+// watch enables edge notifications for pin, must be called with mutex locked
+func (d *dispatcher) watch(pin int, filter Filter) (*watchedPin, error) {
+	if d.epfd < 0 {
+		epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+		if err != nil {
+			logrus.Errorln("Failed to create epoll instance:", err)
+			return nil, ErrUnknown
+		}
+		d.epfd = epfd
+	}
+
+	pinString := strconv.Itoa(pin)
+	err := setEdge(d.basePath, pinString, edgeBoth)
+	if err != nil {
+		return nil, err
+	}
+	fValue, err := openValue(d.basePath, pinString, os.O_RDONLY)
+	if err != nil {
+		setEdge(d.basePath, pinString, edgeNone)
+		return nil, err
+	}
+	initial, err := readOpenedValue(fValue)
+	if err != nil {
+		fValue.Close()
+		setEdge(d.basePath, pinString, edgeNone)
+		return nil, err
+	}
+
+	fd := int32(fValue.Fd())
+	epEvent := syscall.EpollEvent{Events: syscall.EPOLLPRI | syscall.EPOLLERR, Fd: fd}
+	err = syscall.EpollCtl(d.epfd, syscall.EPOLL_CTL_ADD, int(fd), &epEvent)
+	if err != nil {
+		logrus.Errorln("Failed to register value file in epoll:", err)
+		fValue.Close()
+		setEdge(d.basePath, pinString, edgeNone)
+		return nil, ErrUnknown
+	}
+
+	wp := &watchedPin{pin: pin, file: fValue, filter: newDebouncer(filter, initial, time.Now())}
+	d.pins[pin] = wp
+	d.fds[fd] = wp
+	return wp, nil
+}
+
+// unwatch disables edge notifications for pin, must be called with mutex locked
+func (d *dispatcher) unwatch(wp *watchedPin) {
+	if wp.timer != nil {
+		wp.timer.Stop()
+	}
+	fd := int32(wp.file.Fd())
+	syscall.EpollCtl(d.epfd, syscall.EPOLL_CTL_DEL, int(fd), nil)
+	wp.file.Close()
+	setEdge(d.basePath, strconv.Itoa(wp.pin), edgeNone)
+	delete(d.fds, fd)
+	delete(d.pins, wp.pin)
+}
+
+func (d *dispatcher) loop() {
+	logrus.Traceln("gpio.dispatcher.loop() started")
+	epEvents := make([]syscall.EpollEvent, 16)
+	for {
+		n, err := syscall.EpollWait(d.epfd, epEvents, epollTimeoutMsec)
+		if err != nil && err != syscall.EINTR {
+			logrus.Errorln("Waiting for GPIO events failed:", err)
+			time.Sleep(epollTimeoutMsec * time.Millisecond)
+		}
+		now := time.Now()
+
+		d.mtx.Lock()
+		for i := 0; i < n; i++ {
+			if wp, exists := d.fds[epEvents[i].Fd]; exists {
+				d.process(wp, now)
+			}
+		}
+		if len(d.pins) == 0 {
+			d.running = false
+			d.mtx.Unlock()
+			logrus.Traceln("gpio.dispatcher.loop() finished")
+			return
+		}
+		d.mtx.Unlock()
+	}
+}
+
+// process reads current pin value and passes it through filter, must be called with mutex locked
+func (d *dispatcher) process(wp *watchedPin, at time.Time) {
+	value, err := readOpenedValue(wp.file)
+	if err != nil {
+		logrus.Warnf("Failed to read value of watched pin '%d': %v\n", wp.pin, err)
+		return
+	}
+
+	accepted, recheck := wp.filter.update(value, at)
+	if wp.timer != nil {
+		wp.timer.Stop()
+		wp.timer = nil
+	}
+	if recheck > 0 {
+		wp.timer = time.AfterFunc(recheck, func() {
+			d.mtx.Lock()
+			defer d.mtx.Unlock()
+			if d.pins[wp.pin] == wp {
+				d.process(wp, time.Now())
+			}
+		})
+	}
+	if !accepted {
+		return
+	}
+
+	event := Event{Pin: wp.pin, Value: value, Time: at}
+	d.deliver(wp.subscribers, event)
+	d.deliver(d.all, event)
+}
+
+// deliver sends event without blocking, must be called with mutex locked
+func (d *dispatcher) deliver(subscribers []chan Event, event Event) {
+	for _, sub := range subscribers {
+		select {
+		case sub <- event:
+		default:
+			logrus.Warnf("Event queue full, dropping event (pin '%d', device '%s')\n", event.Pin, event.Device)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package gpio
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Adopt takes over pin exported by other process (or by previous instance of this one)
+// so it can be changed and unexported like pins exported with ExportPin
+func (c *controller) Adopt(pin int) error {
+	logrus.Traceln("gpio.controller.Adopt()")
+	c.exportMtx.Lock()
+	defer c.exportMtx.Unlock()
+	unlock := c.lockPins(pin)
+	defer unlock()
+
+	if !isExported(c.basePath, strconv.Itoa(pin)) {
+		return ErrNotExported
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.owned[pin] {
+		return ErrAlreadyAdopted
+	}
+	c.owned[pin] = true
+	return nil
+}
+
+// ListExternalPins returns sorted list of exported pins that were neither exported nor adopted by controller
+func (c *controller) ListExternalPins() ([]int, error) {
+	logrus.Traceln("gpio.controller.ListExternalPins()")
+	c.exportMtx.Lock()
+	defer c.exportMtx.Unlock()
+
+	pins, err := listExported(c.basePath)
+	if err != nil {
+		return []int{}, ErrUnknown
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	result := []int{}
+	for _, pin := range pins {
+		pinInt, _ := strconv.Atoi(pin)
+		if !c.owned[pinInt] {
+			result = append(result, pinInt)
+		}
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+// checkExternal refuses changing pin exported by other process, must be called with pin lock held
+func (c *controller) checkExternal(pin int) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.owned[pin] {
+		logrus.Debugf("Pin '%d' exported by other process\n", pin)
+		return ErrExternalPin
+	}
+	return nil
+}
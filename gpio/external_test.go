@@ -0,0 +1,78 @@
+package gpio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalPins(t *testing.T) {
+	base := newFakeSysfs(t)
+	ctrl := CreateController(base, "/dev")
+	alice := ctrl.WithOwner("alice")
+
+	require.NoError(t, ctrl.ExportPin(4, Output))
+	// pin exported by other process directly through sysfs
+	require.NoError(t, os.WriteFile(filepath.Join(base, "export"), []byte("9"), 0600))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(base, "gpio9"))
+		return err == nil
+	}, time.Second, time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(base, "gpio9", "direction"), []byte("out\n"), 0644))
+
+	t.Run("list external pins", func(t *testing.T) {
+		pins, err := ctrl.ListExportedPins()
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]Direction{4: Output, 9: Output}, pins)
+
+		external, err := ctrl.ListExternalPins()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{9}, external)
+	})
+
+	t.Run("external pin is read only", func(t *testing.T) {
+		assert.Equal(t, ErrExternalPin, ctrl.SetValue(9, 1))
+		_, err := ctrl.Toggle(9)
+		assert.Equal(t, ErrExternalPin, err)
+		assert.Equal(t, PinErrors{9: ErrExternalPin}, ctrl.SetValues(map[int]int{4: 1, 9: 1}))
+		assert.Equal(t, ErrExternalPin, ctrl.SetDirection(9, Input))
+		assert.Equal(t, ErrExternalPin, ctrl.UnexportPin(9))
+		assert.Equal(t, ErrExternalPin, alice.Claim(9, true))
+		assert.Equal(t, ErrExternalPin, ctrl.ExportPin(9, Output))
+
+		value, err := ctrl.GetValue(9)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, value)
+	})
+
+	t.Run("adopt", func(t *testing.T) {
+		assert.Equal(t, ErrNotExported, ctrl.Adopt(10))
+		assert.Equal(t, ErrAlreadyAdopted, ctrl.Adopt(4))
+		require.NoError(t, ctrl.Adopt(9))
+
+		external, err := ctrl.ListExternalPins()
+		assert.NoError(t, err)
+		assert.Empty(t, external)
+
+		assert.Equal(t, ErrAlreadyExported, ctrl.ExportPin(9, Output))
+		assert.NoError(t, ctrl.SetValue(9, 1))
+		assert.NoError(t, alice.Claim(9, false))
+		assert.NoError(t, alice.UnexportPin(9))
+	})
+
+	t.Run("pin exported again by other process", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(base, "export"), []byte("9"), 0600))
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(filepath.Join(base, "gpio9"))
+			return err == nil
+		}, time.Second, time.Millisecond)
+
+		external, err := ctrl.ListExternalPins()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{9}, external, "unexported pin should not stay adopted")
+	})
+}
@@ -0,0 +1,531 @@
+// Package gpiotest provides in-memory GPIO controller for tests of drivers built on gpio package
+package gpiotest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+)
+
+// queueSize is a size of queues of events published to all subscribers
+const queueSize = 64
+
+// noOwner is an identity of Fake used without owner, as in real controller it can not claim pins
+const noOwner = ""
+
+// Fake keeps exported pins and their values in memory, it follows rules of real controller
+// (pins have to be exported, only outputs can be written, output starts low, pins claimed exclusively
+// can be changed only by their owner and claimed ones can be unexported only by their only owner)
+// and implements gpio.Controller, gpio.Bulk, gpio.EventSource and gpio.Claims, counters are not emulated
+// Hooks are called without internal lock held so they can use all methods of Fake
+type Fake struct {
+	mtx         sync.Mutex
+	exported    map[int]gpio.Direction
+	external    map[int]bool
+	values      map[int]int
+	pulses      map[int]int
+	bulkWrites  int
+	subscribers map[int][]chan gpio.Event
+	all         []chan gpio.Event
+	claims      map[int]map[string]bool
+	exclusive   map[int]bool
+
+	onWrite     func(pin, value int)
+	onDirection func(pin int, mode gpio.Direction)
+	onRead      func(pin int) int
+}
+
+// NewFake creates controller without any pin exported
+func NewFake() *Fake {
+	return &Fake{exported: map[int]gpio.Direction{}, external: map[int]bool{}, values: map[int]int{}, pulses: map[int]int{},
+		subscribers: map[int][]chan gpio.Event{}, claims: map[int]map[string]bool{}, exclusive: map[int]bool{}}
+}
+
+// OnWrite sets function called after every write of output pin
+func (f *Fake) OnWrite(fn func(pin, value int)) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.onWrite = fn
+}
+
+// OnDirection sets function called after direction of pin is changed
+func (f *Fake) OnDirection(fn func(pin int, mode gpio.Direction)) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.onDirection = fn
+}
+
+// OnRead sets function providing values of input pins, without it last driven value is read
+func (f *Fake) OnRead(fn func(pin int) int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.onRead = fn
+}
+
+// ExportExternal exports pin as other process would, such pin can be read but not changed or exported again
+func (f *Fake) ExportExternal(pin int, mode gpio.Direction) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.exported[pin] = mode
+	f.external[pin] = true
+}
+
+// Exported returns copy of exported pins with their directions
+func (f *Fake) Exported() map[int]gpio.Direction {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make(map[int]gpio.Direction, len(f.exported))
+	for pin, mode := range f.exported {
+		result[pin] = mode
+	}
+	return result
+}
+
+// Value returns last value of pin (written or driven) without calling any hook
+func (f *Fake) Value(pin int) int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.values[pin]
+}
+
+// Pulses returns number of rising edges written to pin
+func (f *Fake) Pulses(pin int) int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.pulses[pin]
+}
+
+// BulkWrites returns number of SetValues calls
+func (f *Fake) BulkWrites() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.bulkWrites
+}
+
+// Drive changes value of pin as external circuit would, event is delivered to pin subscribers
+// (waiting until each of them receives it) and to subscribers of all events
+func (f *Fake) Drive(pin, value int) {
+	f.mtx.Lock()
+	f.values[pin] = value
+	subscribers := append([]chan gpio.Event{}, f.subscribers[pin]...)
+	event := gpio.Event{Pin: pin, Value: value, Time: time.Now()}
+	f.deliver(event)
+	f.mtx.Unlock()
+
+	for _, sub := range subscribers {
+		sub <- event
+	}
+}
+
+func (f *Fake) SetValue(pin, value int) error {
+	return f.setValue(noOwner, pin, value)
+}
+
+func (f *Fake) setValue(owner string, pin, value int) error {
+	f.mtx.Lock()
+	err := f.write(owner, pin, value)
+	hook := f.onWrite
+	f.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	if hook != nil {
+		hook(pin, value)
+	}
+	return nil
+}
+
+func (f *Fake) GetValue(pin int) (int, error) {
+	f.mtx.Lock()
+	mode, exists := f.exported[pin]
+	value := f.values[pin]
+	hook := f.onRead
+	f.mtx.Unlock()
+	if !exists {
+		return -1, gpio.ErrNotExported
+	}
+	if mode == gpio.Input && hook != nil {
+		return hook(pin), nil
+	}
+	return value, nil
+}
+
+func (f *Fake) Toggle(pin int) (int, error) {
+	return f.toggle(noOwner, pin)
+}
+
+func (f *Fake) toggle(owner string, pin int) (int, error) {
+	f.mtx.Lock()
+	value := f.values[pin] ^ 1
+	f.mtx.Unlock()
+	err := f.setValue(owner, pin, value)
+	if err != nil {
+		return -1, err
+	}
+	return value, nil
+}
+
+func (f *Fake) ExportPin(pin int, mode gpio.Direction) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, exists := f.exported[pin]; exists {
+		if f.external[pin] {
+			return gpio.ErrExternalPin
+		}
+		return gpio.ErrAlreadyExported
+	}
+	f.exported[pin] = mode
+	if mode == gpio.Output {
+		f.values[pin] = 0
+	}
+	delete(f.claims, pin)
+	delete(f.exclusive, pin)
+	return nil
+}
+
+func (f *Fake) SetDirection(pin int, mode gpio.Direction) error {
+	return f.setDirection(noOwner, pin, mode)
+}
+
+func (f *Fake) setDirection(owner string, pin int, mode gpio.Direction) error {
+	f.mtx.Lock()
+	current, exists := f.exported[pin]
+	if !exists {
+		f.mtx.Unlock()
+		return gpio.ErrNotExported
+	}
+	if f.external[pin] {
+		f.mtx.Unlock()
+		return gpio.ErrExternalPin
+	}
+	if f.claimedExclusively(owner, pin) {
+		f.mtx.Unlock()
+		return gpio.ErrPinClaimed
+	}
+	if current == mode {
+		f.mtx.Unlock()
+		return nil
+	}
+	f.exported[pin] = mode
+	if mode == gpio.Output {
+		f.values[pin] = 0
+	}
+	hook := f.onDirection
+	f.mtx.Unlock()
+	if hook != nil {
+		hook(pin, mode)
+	}
+	return nil
+}
+
+func (f *Fake) UnexportPin(pin int) error {
+	return f.unexportPin(noOwner, pin)
+}
+
+// unexportPin refuses pins claimed by anyone else than owner and removes all claims of pin
+func (f *Fake) unexportPin(owner string, pin int) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, exists := f.exported[pin]; !exists {
+		return gpio.ErrNotExported
+	}
+	if f.external[pin] {
+		return gpio.ErrExternalPin
+	}
+	owners := f.claims[pin]
+	if len(owners) > 1 || (len(owners) == 1 && !owners[owner]) {
+		return gpio.ErrPinClaimed
+	}
+	for _, sub := range f.subscribers[pin] {
+		close(sub)
+	}
+	delete(f.subscribers, pin)
+	delete(f.exported, pin)
+	delete(f.claims, pin)
+	delete(f.exclusive, pin)
+	return nil
+}
+
+func (f *Fake) ListExportedPins() (map[int]gpio.Direction, error) {
+	return f.Exported(), nil
+}
+
+func (f *Fake) SetValues(values map[int]int) error {
+	return f.setValues(noOwner, values)
+}
+
+func (f *Fake) setValues(owner string, values map[int]int) error {
+	f.mtx.Lock()
+	f.bulkWrites++
+	// as in real controller nothing is written if any pin can not be written
+	errs := gpio.PinErrors{}
+	for pin, value := range values {
+		err := f.checkWrite(owner, pin, value)
+		if err != nil {
+			errs[pin] = err
+		}
+	}
+	if len(errs) > 0 {
+		f.mtx.Unlock()
+		return errs
+	}
+	for pin, value := range values {
+		f.write(owner, pin, value)
+	}
+	hook := f.onWrite
+	f.mtx.Unlock()
+	if hook != nil {
+		for pin, value := range values {
+			hook(pin, value)
+		}
+	}
+	return nil
+}
+
+func (f *Fake) GetValues(pins []int) (map[int]int, error) {
+	result := map[int]int{}
+	errs := gpio.PinErrors{}
+	for _, pin := range pins {
+		value, err := f.GetValue(pin)
+		if err != nil {
+			errs[pin] = err
+			continue
+		}
+		result[pin] = value
+	}
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
+}
+
+// SetFilter is accepted for exported pins but events are never filtered
+func (f *Fake) SetFilter(pin int, filter gpio.Filter) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, exists := f.exported[pin]; !exists {
+		return gpio.ErrNotExported
+	}
+	return nil
+}
+
+// Subscribe returns unbuffered channel so events are received in order they are driven
+func (f *Fake) Subscribe(pin int) (<-chan gpio.Event, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, exists := f.exported[pin]; !exists {
+		return nil, gpio.ErrNotExported
+	}
+	events := make(chan gpio.Event)
+	f.subscribers[pin] = append(f.subscribers[pin], events)
+	return events, nil
+}
+
+func (f *Fake) Unsubscribe(pin int, events <-chan gpio.Event) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i, sub := range f.subscribers[pin] {
+		if (<-chan gpio.Event)(sub) == events {
+			close(sub)
+			f.subscribers[pin] = append(f.subscribers[pin][:i], f.subscribers[pin][i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *Fake) SubscribeAll() <-chan gpio.Event {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	events := make(chan gpio.Event, queueSize)
+	f.all = append(f.all, events)
+	return events
+}
+
+func (f *Fake) UnsubscribeAll(events <-chan gpio.Event) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i, sub := range f.all {
+		if (<-chan gpio.Event)(sub) == events {
+			close(sub)
+			f.all = append(f.all[:i], f.all[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *Fake) Publish(event gpio.Event) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deliver(event)
+}
+
+func (f *Fake) StartCounter(pin int, edge gpio.Edge, window time.Duration) error {
+	return gpio.ErrNotImplemented
+}
+
+func (f *Fake) StopCounter(pin int) error {
+	return gpio.ErrNotRunning
+}
+
+func (f *Fake) ResetCounter(pin int) (gpio.CounterState, error) {
+	return gpio.CounterState{}, gpio.ErrNotRunning
+}
+
+func (f *Fake) GetCounter(pin int) (gpio.CounterState, error) {
+	return gpio.CounterState{}, gpio.ErrNotRunning
+}
+
+func (f *Fake) StartCapture(pin int) error {
+	return gpio.ErrNotImplemented
+}
+
+func (f *Fake) StopCapture(pin int) error {
+	return gpio.ErrNotRunning
+}
+
+func (f *Fake) GetCapture(pin int) (gpio.CaptureState, error) {
+	return gpio.CaptureState{}, gpio.ErrNotRunning
+}
+
+func (f *Fake) TriggerAndMeasure(trigger, echo int, pulse, timeout time.Duration) (time.Duration, error) {
+	return 0, gpio.ErrNotImplemented
+}
+
+// WithOwner returns controller sharing pins with f and changing them on behalf of owner
+func (f *Fake) WithOwner(owner string) gpio.Owned {
+	return &owned{Fake: f, owner: owner}
+}
+
+func (f *Fake) ListClaims() map[int]gpio.Claim {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := map[int]gpio.Claim{}
+	for pin, owners := range f.claims {
+		cl := gpio.Claim{Exclusive: f.exclusive[pin]}
+		for owner := range owners {
+			cl.Owners = append(cl.Owners, owner)
+		}
+		sort.Strings(cl.Owners)
+		result[pin] = cl
+	}
+	return result
+}
+
+// write changes value of output pin on behalf of owner, must be called with mutex locked
+func (f *Fake) write(owner string, pin, value int) error {
+	err := f.checkWrite(owner, pin, value)
+	if err != nil {
+		return err
+	}
+	if value == 1 && f.values[pin] == 0 {
+		f.pulses[pin]++
+	}
+	f.values[pin] = value
+	return nil
+}
+
+// checkWrite validates value, pin direction, ownership and claim, must be called with mutex locked
+func (f *Fake) checkWrite(owner string, pin, value int) error {
+	mode, exists := f.exported[pin]
+	if !exists {
+		return gpio.ErrNotExported
+	}
+	if f.external[pin] {
+		return gpio.ErrExternalPin
+	}
+	if f.claimedExclusively(owner, pin) {
+		return gpio.ErrPinClaimed
+	}
+	if mode != gpio.Output {
+		return gpio.ErrInvalidDirection
+	}
+	if value != 0 && value != 1 {
+		return gpio.ErrInvalidValue
+	}
+	return nil
+}
+
+// claimedExclusively checks if pin is claimed exclusively by anyone else than owner,
+// must be called with mutex locked
+func (f *Fake) claimedExclusively(owner string, pin int) bool {
+	return f.exclusive[pin] && !f.claims[pin][owner]
+}
+
+// deliver sends event to subscribers of all events without blocking, must be called with mutex locked
+func (f *Fake) deliver(event gpio.Event) {
+	for _, sub := range f.all {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+type owned struct {
+	*Fake
+	owner string
+}
+
+func (o *owned) SetValue(pin, value int) error {
+	return o.setValue(o.owner, pin, value)
+}
+
+func (o *owned) SetValues(values map[int]int) error {
+	return o.setValues(o.owner, values)
+}
+
+func (o *owned) Toggle(pin int) (int, error) {
+	return o.toggle(o.owner, pin)
+}
+
+func (o *owned) SetDirection(pin int, mode gpio.Direction) error {
+	return o.setDirection(o.owner, pin, mode)
+}
+
+func (o *owned) UnexportPin(pin int) error {
+	return o.unexportPin(o.owner, pin)
+}
+
+func (o *owned) Claim(pin int, exclusive bool) error {
+	if o.owner == noOwner {
+		return gpio.ErrNoOwner
+	}
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if _, exists := o.exported[pin]; !exists {
+		return gpio.ErrNotExported
+	}
+	if o.external[pin] {
+		return gpio.ErrExternalPin
+	}
+	owners := o.claims[pin]
+	if len(owners) == 0 || (len(owners) == 1 && owners[o.owner]) {
+		o.claims[pin] = map[string]bool{o.owner: true}
+		o.exclusive[pin] = exclusive
+		return nil
+	}
+	if exclusive || o.exclusive[pin] {
+		return gpio.ErrPinClaimed
+	}
+	owners[o.owner] = true
+	return nil
+}
+
+func (o *owned) Release(pin int) error {
+	if o.owner == noOwner {
+		return gpio.ErrNoOwner
+	}
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if !o.claims[pin][o.owner] {
+		return gpio.ErrNotClaimed
+	}
+	delete(o.claims[pin], o.owner)
+	if len(o.claims[pin]) == 0 {
+		delete(o.claims, pin)
+		delete(o.exclusive, pin)
+	}
+	return nil
+}
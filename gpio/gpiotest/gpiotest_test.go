@@ -0,0 +1,57 @@
+package gpiotest
+
+import (
+	"testing"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaims(t *testing.T) {
+	ctrl := NewFake()
+	driver := ctrl.WithOwner("stepper:x")
+	client := ctrl.WithOwner("client")
+	require.NoError(t, ctrl.ExportPin(1, gpio.Output))
+	require.NoError(t, ctrl.ExportPin(2, gpio.Output))
+
+	t.Run("claim", func(t *testing.T) {
+		assert.Equal(t, gpio.ErrNoOwner, ctrl.WithOwner("").Claim(1, true))
+		assert.Equal(t, gpio.ErrNotExported, driver.Claim(3, true))
+		require.NoError(t, driver.Claim(1, true))
+		require.NoError(t, driver.Claim(2, false))
+		assert.Equal(t, gpio.ErrPinClaimed, client.Claim(1, false))
+		assert.NoError(t, client.Claim(2, false))
+		assert.Equal(t, map[int]gpio.Claim{
+			1: {Owners: []string{"stepper:x"}, Exclusive: true},
+			2: {Owners: []string{"client", "stepper:x"}},
+		}, ctrl.ListClaims())
+	})
+
+	t.Run("exclusive claim", func(t *testing.T) {
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.SetValue(1, 1))
+		assert.Equal(t, gpio.PinErrors{1: gpio.ErrPinClaimed}, client.SetValues(map[int]int{1: 1, 2: 1}))
+		assert.Equal(t, 0, ctrl.Value(2), "nothing should be written if any pin fails")
+		assert.Equal(t, gpio.ErrPinClaimed, client.SetDirection(1, gpio.Input))
+		_, err := ctrl.Toggle(1)
+		assert.Equal(t, gpio.ErrPinClaimed, err)
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.UnexportPin(1))
+		assert.NoError(t, driver.SetValue(1, 1))
+		assert.Equal(t, 1, ctrl.Value(1))
+	})
+
+	t.Run("shared claim", func(t *testing.T) {
+		assert.NoError(t, client.SetValue(2, 1))
+		assert.Equal(t, gpio.ErrPinClaimed, driver.UnexportPin(2))
+		assert.NoError(t, client.Release(2))
+		assert.Equal(t, gpio.ErrNotClaimed, client.Release(2))
+		assert.NoError(t, driver.UnexportPin(2))
+	})
+
+	t.Run("release", func(t *testing.T) {
+		require.NoError(t, driver.Release(1))
+		assert.NoError(t, ctrl.SetValue(1, 0))
+		assert.NoError(t, ctrl.UnexportPin(1))
+		assert.Empty(t, ctrl.ListClaims())
+	})
+}
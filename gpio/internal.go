@@ -5,167 +5,221 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/markamdev/repico/sysfs"
 	"github.com/sirupsen/logrus"
 )
 
-var usedPins = map[int]Direction{}
-
 // functions in this file are only used internally so param validation is omited
 
 const (
-	pathGpioBase        = "/sys/class/gpio"
-	pathGpioExport      = "/sys/class/gpio/export"
-	pathGpioUnexport    = "/sys/class/gpio/unexport"
-	pathGpioPinBase     = "/sys/class/gpio/gpio"
+	pathExportSuffix    = "/export"
+	pathUnexportSuffix  = "/unexport"
+	pathPinPrefix       = "/gpio"
 	pathDirectionSuffix = "/direction"
 	pathValueSuffix     = "/value"
+	pathEdgeSuffix      = "/edge"
+	pathActiveLowSuffix = "/active_low"
+	pathBaseSuffix      = "/base"
+	pathNgpioSuffix     = "/ngpio"
+	pathLabelSuffix     = "/label"
 )
 
-func isExported(pin string) bool {
-	pinPath := pathGpioPinBase + pin
-	logrus.Trace("isExported():", pinPath)
-	_, err := os.Stat(pinPath)
-	if err != nil {
-		logrus.Traceln("isExported() Stat() error:", err)
-		return false
-	}
-	return true
+const (
+	edgeNone = "none"
+	edgeBoth = "both"
+)
+
+const (
+	// pinDirTimeout limits waiting for pin directory to appear (or disappear)
+	// after writing to export (or unexport) file
+	pinDirTimeout = time.Second
+)
+
+func pinPath(basePath, pin string) string {
+	return basePath + pathPinPrefix + pin
 }
 
-func exportPin(pin string) error {
-	fExport, err := os.OpenFile(pathGpioExport, os.O_WRONLY, 0755)
-	if err != nil {
-		logrus.Traceln("exportPin() export opening failed:", err)
-		return ErrUnknown
-	}
-	defer fExport.Close()
+func isExported(basePath, pin string) bool {
+	logrus.Trace("isExported():", pinPath(basePath, pin))
+	return sysfs.Exists(pinPath(basePath, pin))
+}
 
-	_, err = fExport.WriteString(pin)
+func exportPin(basePath, pin string) error {
+	err := sysfs.WriteAttribute(basePath+pathExportSuffix, pin)
 	if err != nil {
 		logrus.Traceln("exportPin() export error:", err)
 		return ErrUnknown
 	}
-
 	return nil
 }
 
-func setDirection(pin, dir string) error {
-	dirPath := pathGpioPinBase + pin + pathDirectionSuffix
+func setDirection(basePath, pin, dir string) error {
+	dirPath := pinPath(basePath, pin) + pathDirectionSuffix
 	logrus.Traceln("setDirection(): ", dirPath)
 
-	fDir, err := os.OpenFile(dirPath, os.O_WRONLY, 0755)
+	err := sysfs.WriteAttribute(dirPath, dir)
 	if err != nil {
+		logrus.Traceln("setDirection() writing error:", err)
 		return ErrUnknown
 	}
-	defer fDir.Close()
+	return nil
+}
 
-	_, err = fDir.WriteString(dir)
+func unexportPin(basePath, pin string) error {
+	err := sysfs.WriteAttribute(basePath+pathUnexportSuffix, pin)
 	if err != nil {
-		logrus.Traceln("setDirection() writing error:", err)
+		logrus.Traceln("unexportPin() unexport error:", err)
 		return ErrUnknown
 	}
-
 	return nil
 }
 
-func unexportPin(pin string) error {
-	fUnexport, err := os.OpenFile(pathGpioUnexport, os.O_WRONLY, 0755)
+func isOutput(basePath, pin string) (bool, error) {
+	dirString, err := sysfs.ReadAttribute(pinPath(basePath, pin) + pathDirectionSuffix)
 	if err != nil {
-		logrus.Traceln("unexportPin() unexport opening failed:", err)
-		return ErrUnknown
+		logrus.Traceln("isOutput() failed to read direction file:", err)
+		return false, ErrUnknown
+	}
+	if dirString == "out" {
+		return true, nil
 	}
-	defer fUnexport.Close()
+	return false, nil
+}
 
-	_, err = fUnexport.WriteString(pin)
+func listExported(basePath string) ([]string, error) {
+	entries, err := os.ReadDir(basePath)
 	if err != nil {
-		logrus.Traceln("exportPin() export error:", err)
-		return ErrUnknown
+		logrus.Traceln("listExported() failed to read GPIO directory:", err)
+		return []string{}, ErrUnknown
 	}
 
-	return nil
+	result := make([]string, 0, len(entries))
+	for _, ent := range entries {
+		currName := ent.Name()
+		if !strings.HasPrefix(currName, "gpio") {
+			continue
+		}
+		id := strings.TrimPrefix(currName, "gpio")
+		_, err = strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// chip describes GPIO chip registered in sysfs, its lines are pins from base to base+ngpio-1
+type chip struct {
+	path  string
+	base  int
+	ngpio int
+	label string
 }
 
-func isOutput(pin string) (bool, error) {
-	dirPath := pathGpioPinBase + pin + pathDirectionSuffix
-	fDirection, err := os.OpenFile(dirPath, os.O_RDONLY, 0755)
+func listChips(basePath string) ([]chip, error) {
+	entries, err := os.ReadDir(basePath)
 	if err != nil {
-		logrus.Traceln("isOutput() cannot open direction file:", err)
-		return false, ErrUnknown
+		logrus.Traceln("listChips() failed to read GPIO directory:", err)
+		return []chip{}, ErrUnknown
 	}
-	defer fDirection.Close()
 
-	buffer := make([]byte, 16)
-	n, err := fDirection.Read(buffer)
-	if err != nil && err != io.EOF {
-		logrus.Traceln("isOutput() failed to read direction file:", err)
-		return false, ErrUnknown
-	}
-	dirString := strings.TrimRight(string(buffer[:n]), "\n\r")
-	if dirString == "out" {
-		return true, nil
+	result := []chip{}
+	for _, ent := range entries {
+		if !strings.HasPrefix(ent.Name(), "gpiochip") {
+			continue
+		}
+		chipPath := basePath + "/" + ent.Name()
+		base, err := readIntAttribute(chipPath + pathBaseSuffix)
+		if err != nil {
+			return []chip{}, err
+		}
+		ngpio, err := readIntAttribute(chipPath + pathNgpioSuffix)
+		if err != nil {
+			return []chip{}, err
+		}
+		label, _ := sysfs.ReadAttribute(chipPath + pathLabelSuffix)
+		result = append(result, chip{path: chipPath, base: base, ngpio: ngpio, label: label})
 	}
-	return false, nil
+	return result, nil
 }
 
-func setValue(pin, value string) error {
-	valuePath := pathGpioPinBase + pin + pathValueSuffix
-	fValue, err := os.OpenFile(valuePath, os.O_WRONLY, 0755)
+func readIntAttribute(path string) (int, error) {
+	valString, err := sysfs.ReadAttribute(path)
 	if err != nil {
-		logrus.Traceln("setValue() cannot open value file:", err)
-		return ErrUnknown
+		return 0, ErrUnknown
 	}
-	defer fValue.Close()
+	value, err := strconv.Atoi(strings.TrimSpace(valString))
+	if err != nil {
+		logrus.Traceln("readIntAttribute() invalid content:", err)
+		return 0, ErrUnknown
+	}
+	return value, nil
+}
 
-	_, err = fValue.WriteString(value)
+func isActiveLow(basePath, pin string) bool {
+	value, err := sysfs.ReadAttribute(pinPath(basePath, pin) + pathActiveLowSuffix)
+	return err == nil && value == "1"
+}
+
+func hasEdge(basePath, pin string) bool {
+	return sysfs.Exists(pinPath(basePath, pin) + pathEdgeSuffix)
+}
+
+func setEdge(basePath, pin, edge string) error {
+	err := sysfs.WriteAttribute(pinPath(basePath, pin)+pathEdgeSuffix, edge)
 	if err != nil {
-		logrus.Traceln("setValue() cannot set GPIO state:", err)
+		logrus.Traceln("setEdge() cannot set edge:", err)
 		return ErrUnknown
 	}
-
 	return nil
 }
 
-func getValue(pin string) (string, error) {
-	valuePath := pathGpioPinBase + pin + pathValueSuffix
-	fValue, err := os.OpenFile(valuePath, os.O_RDONLY, 0755)
+func openValue(basePath, pin string, flag int) (*os.File, error) {
+	valuePath := pinPath(basePath, pin) + pathValueSuffix
+	fValue, err := os.OpenFile(valuePath, flag, 0755)
 	if err != nil {
-		logrus.Traceln("getValue() cannot open value file:", err)
-		return "", ErrUnknown
+		logrus.Traceln("openValue() cannot open value file:", err)
+		return nil, ErrUnknown
 	}
+	return fValue, nil
+}
 
-	defer fValue.Close()
-
+// readOpenedValue reads value from already opened file starting always from its beginning
+func readOpenedValue(fValue *os.File) (int, error) {
 	buffer := make([]byte, 16)
-	n, err := fValue.Read(buffer)
+	n, err := fValue.ReadAt(buffer, 0)
 	if err != nil && err != io.EOF {
-		logrus.Traceln("getValue() failed to read value file:", err)
-		return "", ErrUnknown
+		logrus.Traceln("readOpenedValue() failed to read value file:", err)
+		return -1, ErrUnknown
 	}
 
-	return strings.TrimRight(string(buffer[:n]), "\r\n"), nil
-}
-
-func listExported() ([]string, error) {
-	entries, err := os.ReadDir(pathGpioBase)
+	value, err := strconv.Atoi(strings.TrimRight(string(buffer[:n]), "\r\n"))
 	if err != nil {
-		logrus.Traceln("listExported() failed to read GPIO directory:", err)
-		return []string{}, ErrUnknown
+		logrus.Traceln("readOpenedValue() failed to convert value:", err)
+		return -1, ErrUnknown
 	}
+	return value, nil
+}
 
-	result := make([]string, 0, len(entries))
-	for _, ent := range entries {
-		currName := ent.Name()
-		if !strings.HasPrefix(currName, "gpio") {
-			continue
-		}
-		id := strings.TrimPrefix(currName, "gpio")
-		_, err = strconv.Atoi(id)
-		if err != nil {
-			continue
-		}
+// waitForPin waits until pin directory exists (or not) after export (or unexport)
+func waitForPin(basePath, pin string, exported bool) error {
+	if !sysfs.WaitFor(pinPath(basePath, pin), exported, pinDirTimeout) {
+		logrus.Traceln("waitForPin() timeout for pin:", pin)
+		return ErrUnknown
+	}
+	return nil
+}
 
-		result = append(result, id)
+// writeOpenedValue writes value to already opened file always at its beginning
+func writeOpenedValue(fValue *os.File, value int) error {
+	_, err := fValue.WriteAt([]byte(strconv.Itoa(value)), 0)
+	if err != nil {
+		logrus.Traceln("writeOpenedValue() cannot set GPIO state:", err)
+		return ErrUnknown
 	}
-	return result, nil
+	return nil
 }
@@ -1,5 +1,7 @@
 package gpio
 
+import "time"
+
 // Direction defines GPIO pin direction
 // Possible values are Unset, Input and Output
 type Direction int
@@ -40,11 +42,318 @@ func StringToDirection(dr string) Direction {
 	}
 }
 
-// Controller is an interface of GPIO controlling object
+// Edge defines which input transitions are taken into account
+// Possible values are InvalidEdge, Rising, Falling and Both
+type Edge int
+
+const (
+	// InvalidEdge - default value, not set
+	InvalidEdge Edge = iota
+	// Rising - transition from 0 to 1
+	Rising
+	// Falling - transition from 1 to 0
+	Falling
+	// Both - any transition
+	Both
+)
+
+const (
+	edgeRising  = "rising"
+	edgeFalling = "falling"
+)
+
+func EdgeToString(ed Edge) string {
+	switch ed {
+	case Rising:
+		return edgeRising
+	case Falling:
+		return edgeFalling
+	case Both:
+		return edgeBoth
+	default:
+		return "-"
+	}
+}
+
+func StringToEdge(ed string) Edge {
+	switch ed {
+	case edgeRising:
+		return Rising
+	case edgeFalling:
+		return Falling
+	case edgeBoth:
+		return Both
+	default:
+		return InvalidEdge
+	}
+}
+
+// Matches checks if transition to given value is of this edge type
+func (ed Edge) Matches(value int) bool {
+	switch ed {
+	case Rising:
+		return value == 1
+	case Falling:
+		return value == 0
+	case Both:
+		return true
+	default:
+		return false
+	}
+}
+
+// Filter defines software filtering of input pin transitions
+// Debounce is a time window after accepted change in which further transitions are ignored,
+// MinStable is a time for which new value has to stay unchanged before it is accepted
+type Filter struct {
+	Debounce  time.Duration
+	MinStable time.Duration
+}
+
+// NoPin marks event not related to single pin
+const NoPin = -1
+
+// Event describes accepted (filtered) change of input pin value or state change
+// published by device built on top of pins (then Device is set and Pin is NoPin)
+type Event struct {
+	Pin    int
+	Value  int
+	Time   time.Time
+	Device string
+}
+
+// CounterState describes edge counter working on input pin
+// Rate is a number of counted edges per second within last Window
+type CounterState struct {
+	Pin    int
+	Edge   Edge
+	Window time.Duration
+	Count  uint64
+	Rate   float64
+}
+
+// PulseStats describes widths of pulses of single level captured on input pin
+type PulseStats struct {
+	Count   int
+	Last    time.Duration
+	Min     time.Duration
+	Max     time.Duration
+	Average time.Duration
+}
+
+// CaptureState describes pulse capture working on input pin
+// DutyCycle is a ratio of average high time to average period (0 if not known yet)
+type CaptureState struct {
+	Pin       int
+	High      PulseStats
+	Low       PulseStats
+	DutyCycle float64
+}
+
+// Claim describes owners of pin, shared claim protects pin from being unexported by anyone else
+// while exclusive claim (with single owner) additionally allows only owner to change pin value and direction
+type Claim struct {
+	Owners    []string
+	Exclusive bool
+}
+
+// Usage defines who currently uses GPIO line
+// Possible values are Free, Exported, External and Used
+type Usage int
+
+const (
+	// Free - line not used by anyone
+	Free Usage = iota
+	// Exported - line exported (or adopted) by controller
+	Exported
+	// External - line exported in sysfs by other process
+	External
+	// Used - line requested by kernel driver or other process through character device
+	Used
+)
+
+func UsageToString(us Usage) string {
+	switch us {
+	case Free:
+		return "free"
+	case Exported:
+		return "exported"
+	case External:
+		return "external"
+	case Used:
+		return "used"
+	default:
+		return "-"
+	}
+}
+
+// Bias defines pull resistor configuration of GPIO line
+// Possible values are BiasUnknown, BiasDisabled, PullUp and PullDown
+type Bias int
+
+const (
+	// BiasUnknown - default value, bias not reported (line left as configured by firmware)
+	BiasUnknown Bias = iota
+	// BiasDisabled - no pull resistor
+	BiasDisabled
+	// PullUp - pull-up resistor enabled
+	PullUp
+	// PullDown - pull-down resistor enabled
+	PullDown
+)
+
+func BiasToString(bs Bias) string {
+	switch bs {
+	case BiasDisabled:
+		return "disabled"
+	case PullUp:
+		return "pull-up"
+	case PullDown:
+		return "pull-down"
+	default:
+		return "-"
+	}
+}
+
+// Drive defines how output GPIO line is driven
+// Possible values are DriveUnknown, PushPull, OpenDrain and OpenSource
+type Drive int
+
+const (
+	// DriveUnknown - default value, not known or line is not an output
+	DriveUnknown Drive = iota
+	// PushPull - line driven both high and low
+	PushPull
+	// OpenDrain - line driven only low
+	OpenDrain
+	// OpenSource - line driven only high
+	OpenSource
+)
+
+func DriveToString(dr Drive) string {
+	switch dr {
+	case PushPull:
+		return "push-pull"
+	case OpenDrain:
+		return "open-drain"
+	case OpenSource:
+		return "open-source"
+	default:
+		return "-"
+	}
+}
+
+// Features describes GPIO line features and their current configuration
+// EdgeDetection is known only for exported lines (sysfs provides edge attribute only for lines with interrupt),
+// Bias and Drive come from chip character device (bias is reported by kernel 5.5 and newer, drive only for outputs),
+// they are set by kernel drivers or device tree as sysfs interface can not change them
+// PWM is not described as kernel does not tell which lines are routed to PWM channels (see pwm package)
+type Features struct {
+	EdgeDetection bool
+	Bias          Bias
+	Drive         Drive
+}
+
+// LineInfo describes single GPIO line, Name and Consumer are known only if chip has character device
+// Direction is Invalid if it can not be determined
+type LineInfo struct {
+	Pin       int
+	Chip      string
+	Offset    int
+	Name      string
+	Consumer  string
+	Usage     Usage
+	Direction Direction
+	ActiveLow bool
+	Features  Features
+}
+
+// Controller is an interface of GPIO controlling object, optional features are provided by separate
+// interfaces implemented by controller returned from CreateController
 type Controller interface {
 	SetValue(pin, value int) error
 	GetValue(pin int) (int, error)
+	Toggle(pin int) (int, error)
 	ExportPin(pin int, mode Direction) error
+	SetDirection(pin int, mode Direction) error
 	UnexportPin(pin int) error
 	ListExportedPins() (map[int]Direction, error)
 }
+
+// Bulk reads and writes many pins at once
+type Bulk interface {
+	SetValues(values map[int]int) error
+	GetValues(pins []int) (map[int]int, error)
+}
+
+// BulkController is a controller of pins changed together
+type BulkController interface {
+	Controller
+	Bulk
+}
+
+// EventSource delivers filtered changes of input pins and events published by devices
+type EventSource interface {
+	SetFilter(pin int, filter Filter) error
+	Subscribe(pin int) (<-chan Event, error)
+	Unsubscribe(pin int, events <-chan Event)
+	SubscribeAll() <-chan Event
+	UnsubscribeAll(events <-chan Event)
+	Publish(event Event)
+}
+
+// Owned is a controller acting on behalf of owner, only such controller can claim pins
+type Owned interface {
+	Controller
+	Bulk
+	Counters
+	Claim(pin int, exclusive bool) error
+	Release(pin int) error
+}
+
+// Claims gives access to pins on behalf of owners (ex. client or device driver)
+type Claims interface {
+	WithOwner(owner string) Owned
+	ListClaims() map[int]Claim
+}
+
+// Adoption takes over pins exported by other processes, such pins can not be changed until adopted
+type Adoption interface {
+	Adopt(pin int) error
+	ListExternalPins() ([]int, error)
+}
+
+// LineInspector describes all GPIO lines, not only exported ones
+type LineInspector interface {
+	// Capabilities describes all lines of all GPIO chips sorted by pin number
+	Capabilities() ([]LineInfo, error)
+}
+
+// Counters counts edges and measures pulses of input pins
+type Counters interface {
+	StartCounter(pin int, edge Edge, window time.Duration) error
+	StopCounter(pin int) error
+	ResetCounter(pin int) (CounterState, error)
+	GetCounter(pin int) (CounterState, error)
+	StartCapture(pin int) error
+	StopCapture(pin int) error
+	GetCapture(pin int) (CaptureState, error)
+	TriggerAndMeasure(trigger, echo int, pulse, timeout time.Duration) (time.Duration, error)
+}
+
+// EventController is a controller of pins used with their events
+type EventController interface {
+	Controller
+	EventSource
+}
+
+// FullController provides all features of GPIO controller
+type FullController interface {
+	Controller
+	Bulk
+	EventSource
+	Counters
+	Claims
+	Adoption
+	LineInspector
+}
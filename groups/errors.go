@@ -0,0 +1,11 @@
+package groups
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid group configuration")
+	ErrInvalidName   = errors.New("invalid name")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound      = errors.New("not found")
+)
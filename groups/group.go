@@ -0,0 +1,102 @@
+package groups
+
+import (
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+type group struct {
+	name    string
+	cfg     Config
+	ctrl    gpio.Owned
+	claimed map[int]bool
+}
+
+// Write sets all group pins with single bulk operation
+func (g *group) Write(value uint32) error {
+	logrus.Traceln("groups.group.Write()")
+	if g.cfg.Direction != gpio.Output {
+		return gpio.ErrInvalidDirection
+	}
+	if len(g.cfg.Pins) < MaxPins && value >= 1<<uint(len(g.cfg.Pins)) {
+		return ErrInvalidValue
+	}
+
+	values := make(map[int]int, len(g.cfg.Pins))
+	for i, pin := range g.cfg.Pins {
+		values[pin] = int(value>>g.bit(i)) & 1
+	}
+	return g.ctrl.SetValues(values)
+}
+
+// Read gets all group pins with single bulk operation
+func (g *group) Read() (uint32, error) {
+	logrus.Traceln("groups.group.Read()")
+	values, err := g.ctrl.GetValues(g.cfg.Pins)
+	if err != nil {
+		return 0, err
+	}
+
+	result := uint32(0)
+	for i, pin := range g.cfg.Pins {
+		result |= uint32(values[pin]&1) << g.bit(i)
+	}
+	return result, nil
+}
+
+func (g *group) Status() Status {
+	return Status{Name: g.name, Config: g.cfg}
+}
+
+func (g *group) Close() error {
+	logrus.Traceln("groups.group.Close()")
+	return gpio.ReleasePins(g.ctrl, g.claimed)
+}
+
+// bit returns register bit number for pin at given list index
+func (g *group) bit(index int) uint {
+	if g.cfg.Order == MSBFirst {
+		return uint(len(g.cfg.Pins) - 1 - index)
+	}
+	return uint(index)
+}
+
+func validateConfig(cfg Config) error {
+	if len(cfg.Pins) == 0 || len(cfg.Pins) > MaxPins {
+		return ErrInvalidConfig
+	}
+	if cfg.Order != LSBFirst && cfg.Order != MSBFirst {
+		return ErrInvalidConfig
+	}
+	if cfg.Direction != gpio.Input && cfg.Direction != gpio.Output {
+		return ErrInvalidConfig
+	}
+	used := map[int]bool{}
+	for _, pin := range cfg.Pins {
+		if pin < 0 || used[pin] {
+			return ErrInvalidConfig
+		}
+		used[pin] = true
+	}
+	return nil
+}
+
+// CreateGroup claims group pins (as "group:{name}" owner) with common direction and returns group object
+func CreateGroup(ctrl gpio.Claims, name string, cfg Config) (Group, error) {
+	logrus.Traceln("groups.CreateGroup()")
+	err := validateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &group{name: name, cfg: cfg, ctrl: ctrl.WithOwner(gpio.DriverOwner(ownerKind, name))}
+	pins := make(map[int]gpio.Direction, len(cfg.Pins))
+	for _, pin := range cfg.Pins {
+		pins[pin] = cfg.Direction
+	}
+	result.claimed, err = gpio.ClaimPins(result.ctrl, pins)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
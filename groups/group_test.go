@@ -0,0 +1,123 @@
+package groups
+
+import (
+	"testing"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	mgr := CreateManager(ctrl)
+
+	t.Run("add - invalid config", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidName, mgr.Add("", Config{Pins: []int{1}, Order: LSBFirst, Direction: gpio.Output}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("bus", Config{Order: LSBFirst, Direction: gpio.Output}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("bus", Config{Pins: []int{1, 1}, Order: LSBFirst, Direction: gpio.Output}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("bus", Config{Pins: []int{1, 2}, Direction: gpio.Output}))
+		assert.Empty(t, ctrl.Exported())
+	})
+
+	require.NoError(t, mgr.Add("lsb", Config{Pins: []int{1, 2, 3, 4}, Order: LSBFirst, Direction: gpio.Output}))
+	require.NoError(t, mgr.Add("msb", Config{Pins: []int{5, 6, 7, 8}, Order: MSBFirst, Direction: gpio.Output}))
+	require.NoError(t, mgr.Add("switches", Config{Pins: []int{9, 10}, Order: LSBFirst, Direction: gpio.Input}))
+
+	t.Run("add - conflicting pins", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, mgr.Add("lsb", Config{Pins: []int{11}, Order: LSBFirst, Direction: gpio.Output}))
+		assert.Equal(t, ErrAlreadyExists, mgr.Add("other", Config{Pins: []int{4, 11}, Order: LSBFirst, Direction: gpio.Output}))
+	})
+
+	t.Run("write and read - LSB first", func(t *testing.T) {
+		grp, _ := mgr.Get("lsb")
+		require.NoError(t, grp.Write(0x6))
+		assert.Equal(t, map[int]int{1: 0, 2: 1, 3: 1, 4: 0}, read(ctrl, 1, 2, 3, 4))
+		assert.Equal(t, 1, ctrl.BulkWrites())
+
+		value, err := grp.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0x6), value)
+	})
+
+	t.Run("write and read - MSB first", func(t *testing.T) {
+		grp, _ := mgr.Get("msb")
+		require.NoError(t, grp.Write(0x1))
+		assert.Equal(t, map[int]int{5: 0, 6: 0, 7: 0, 8: 1}, read(ctrl, 5, 6, 7, 8))
+
+		value, err := grp.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0x1), value)
+	})
+
+	t.Run("write - value out of range", func(t *testing.T) {
+		grp, _ := mgr.Get("lsb")
+		assert.Equal(t, ErrInvalidValue, grp.Write(16))
+	})
+
+	t.Run("input group", func(t *testing.T) {
+		grp, _ := mgr.Get("switches")
+		assert.Equal(t, gpio.ErrInvalidDirection, grp.Write(1))
+
+		ctrl.Drive(10, 1)
+		value, err := grp.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(2), value)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		list := mgr.List()
+		require.Len(t, list, 3)
+		assert.Equal(t, "lsb", list[0].Name)
+		assert.Equal(t, []int{5, 6, 7, 8}, list[1].Config.Pins)
+	})
+
+	t.Run("claimed pins", func(t *testing.T) {
+		assert.Equal(t, gpio.Claim{Owners: []string{"group:switches"}, Exclusive: true}, ctrl.ListClaims()[9])
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.SetValue(1, 1))
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.UnexportPin(10))
+		assert.Equal(t, gpio.ErrPinClaimed, ctrl.WithOwner("alice").Claim(5, false))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.Equal(t, ErrNotFound, mgr.Remove("unknown"))
+		assert.NoError(t, mgr.Remove("lsb"))
+		assert.NoError(t, mgr.Remove("msb"))
+		assert.NoError(t, mgr.Remove("switches"))
+		assert.Empty(t, ctrl.Exported())
+		assert.Empty(t, ctrl.ListClaims())
+	})
+}
+
+func read(ctrl *gpiotest.Fake, pins ...int) map[int]int {
+	result := map[int]int{}
+	for _, pin := range pins {
+		result[pin] = ctrl.Value(pin)
+	}
+	return result
+}
+
+func TestGroupExternalPin(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	ctrl.ExportExternal(2, gpio.Output)
+	mgr := CreateManager(ctrl)
+
+	assert.Equal(t, gpio.ErrExternalPin, mgr.Add("bus", Config{Pins: []int{1, 2}, Order: LSBFirst, Direction: gpio.Output}))
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Output}, ctrl.Exported(), "pins exported by group should be released")
+	assert.Empty(t, mgr.List())
+}
+
+func TestGroupAlreadyExportedPin(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	require.NoError(t, ctrl.ExportPin(2, gpio.Input))
+	require.NoError(t, ctrl.ExportPin(3, gpio.Output))
+	mgr := CreateManager(ctrl)
+
+	assert.Equal(t, gpio.ErrInvalidDirection, mgr.Add("bus", Config{Pins: []int{1, 2}, Order: LSBFirst, Direction: gpio.Output}))
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Input, 3: gpio.Output}, ctrl.Exported(), "pins exported by group should be released")
+
+	require.NoError(t, mgr.Add("bus", Config{Pins: []int{1, 3}, Order: LSBFirst, Direction: gpio.Output}))
+	require.NoError(t, mgr.Remove("bus"))
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Input, 3: gpio.Output}, ctrl.Exported(), "reused pins should stay exported")
+}
@@ -0,0 +1,90 @@
+package groups
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+type manager struct {
+	ctrl   gpio.Claims
+	mtx    sync.Mutex
+	groups map[string]Group
+}
+
+func (m *manager) Add(name string, cfg Config) error {
+	logrus.Traceln("groups.manager.Add()")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, exists := m.groups[name]; exists {
+		return ErrAlreadyExists
+	}
+	requested := usedPins(cfg)
+	for _, grp := range m.groups {
+		for pin := range usedPins(grp.Status().Config) {
+			if requested[pin] {
+				return ErrAlreadyExists
+			}
+		}
+	}
+
+	grp, err := CreateGroup(m.ctrl, name, cfg)
+	if err != nil {
+		return err
+	}
+	m.groups[name] = grp
+	return nil
+}
+
+func (m *manager) Remove(name string) error {
+	logrus.Traceln("groups.manager.Remove()")
+	m.mtx.Lock()
+	grp, exists := m.groups[name]
+	delete(m.groups, name)
+	m.mtx.Unlock()
+
+	if !exists {
+		return ErrNotFound
+	}
+	return grp.Close()
+}
+
+func (m *manager) Get(name string) (Group, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	grp, exists := m.groups[name]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return grp, nil
+}
+
+func (m *manager) List() []Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	result := make([]Status, 0, len(m.groups))
+	for _, grp := range m.groups {
+		result = append(result, grp.Status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func usedPins(cfg Config) map[int]bool {
+	result := map[int]bool{}
+	for _, pin := range cfg.Pins {
+		result[pin] = true
+	}
+	return result
+}
+
+func CreateManager(ctrl gpio.Claims) Manager {
+	logrus.Traceln("groups.CreateManager()")
+	return &manager{ctrl: ctrl, groups: map[string]Group{}}
+}
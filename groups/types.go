@@ -0,0 +1,78 @@
+package groups
+
+import "github.com/markamdev/repico/gpio"
+
+// ownerKind is a kind of driver identity used by groups to claim pins
+const ownerKind = "group"
+
+// Order defines how group pins are mapped to register bits
+// Possible values are InvalidOrder, LSBFirst and MSBFirst
+type Order int
+
+const (
+	// InvalidOrder - default value, not set
+	InvalidOrder Order = iota
+	// LSBFirst - first pin on the list is the least significant bit
+	LSBFirst
+	// MSBFirst - first pin on the list is the most significant bit
+	MSBFirst
+)
+
+const (
+	orderLSBFirst = "lsb"
+	orderMSBFirst = "msb"
+)
+
+// MaxPins - maximum number of pins in single group
+const MaxPins = 32
+
+func OrderToString(or Order) string {
+	switch or {
+	case LSBFirst:
+		return orderLSBFirst
+	case MSBFirst:
+		return orderMSBFirst
+	default:
+		return "-"
+	}
+}
+
+func StringToOrder(or string) Order {
+	switch or {
+	case orderLSBFirst:
+		return LSBFirst
+	case orderMSBFirst:
+		return MSBFirst
+	default:
+		return InvalidOrder
+	}
+}
+
+// Config describes pins forming group and their common direction
+type Config struct {
+	Pins      []int
+	Order     Order
+	Direction gpio.Direction
+}
+
+// Status describes pin group
+type Status struct {
+	Name   string
+	Config Config
+}
+
+// Group is an interface of pin group working as single integer register
+type Group interface {
+	Write(value uint32) error
+	Read() (uint32, error)
+	Status() Status
+	Close() error
+}
+
+// Manager is an interface of object keeping named pin groups
+type Manager interface {
+	Add(name string, cfg Config) error
+	Remove(name string) error
+	Get(name string) (Group, error)
+	List() []Status
+}
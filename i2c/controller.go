@@ -0,0 +1,154 @@
+package i2c
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	devPath string
+
+	mtx   sync.Mutex
+	buses map[int]bus.I2C
+}
+
+// ListBuses returns numbers of buses available as /dev/i2c-N devices and added ones
+func (c *controller) ListBuses() ([]int, error) {
+	logrus.Traceln("i2c.controller.ListBuses()")
+	entries, err := os.ReadDir(c.devPath)
+	if err != nil {
+		logrus.Errorln("Failed to read devices directory:", err)
+		return []int{}, ErrUnknown
+	}
+
+	found := map[int]bool{}
+	for _, ent := range entries {
+		if !strings.HasPrefix(ent.Name(), pathBusPrefix[1:]) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimPrefix(ent.Name(), pathBusPrefix[1:]))
+		if err == nil {
+			found[number] = true
+		}
+	}
+	c.mtx.Lock()
+	for number := range c.buses {
+		found[number] = true
+	}
+	c.mtx.Unlock()
+
+	result := make([]int, 0, len(found))
+	for number := range found {
+		result = append(result, number)
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+func (c *controller) AddBus(number int, b bus.I2C) error {
+	logrus.Traceln("i2c.controller.AddBus()")
+	if number < 0 || b == nil {
+		return ErrInvalidBus
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, exists := c.buses[number]; exists {
+		return ErrAlreadyExists
+	}
+	c.buses[number] = b
+	return nil
+}
+
+// Scan probes all valid addresses with single byte read
+func (c *controller) Scan(number int) ([]uint16, error) {
+	logrus.Traceln("i2c.controller.Scan()")
+	b, err := c.bus(number)
+	if err != nil {
+		return []uint16{}, err
+	}
+
+	result := []uint16{}
+	buffer := make([]byte, 1)
+	for addr := uint16(MinAddress); addr <= MaxAddress; addr++ {
+		err = b.Tx(addr, nil, buffer)
+		if err == nil {
+			result = append(result, addr)
+			continue
+		}
+		if err != bus.ErrNack {
+			return []uint16{}, err
+		}
+	}
+	return result, nil
+}
+
+// ReadRegister writes register number and reads given number of bytes using repeated start
+func (c *controller) ReadRegister(number int, addr uint16, reg byte, length int) ([]byte, error) {
+	logrus.Traceln("i2c.controller.ReadRegister()")
+	if length <= 0 || length > MaxLength {
+		return nil, ErrInvalidValue
+	}
+	result := make([]byte, length)
+	err := c.Tx(number, addr, []byte{reg}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WriteRegister writes register number followed by data in single transfer
+func (c *controller) WriteRegister(number int, addr uint16, reg byte, data []byte) error {
+	logrus.Traceln("i2c.controller.WriteRegister()")
+	if len(data) == 0 || len(data) >= MaxLength {
+		return ErrInvalidValue
+	}
+	return c.Tx(number, addr, append([]byte{reg}, data...), nil)
+}
+
+func (c *controller) Tx(number int, addr uint16, w, r []byte) error {
+	logrus.Traceln("i2c.controller.Tx()")
+	if addr < MinAddress || addr > MaxAddress {
+		return ErrInvalidAddress
+	}
+	if (len(w) == 0 && len(r) == 0) || len(w) > MaxLength || len(r) > MaxLength {
+		return ErrInvalidValue
+	}
+	b, err := c.bus(number)
+	if err != nil {
+		return err
+	}
+	return b.Tx(addr, w, r)
+}
+
+// bus returns already opened (or added) bus or opens /dev/i2c-N device
+func (c *controller) bus(number int) (bus.I2C, error) {
+	if number < 0 {
+		return nil, ErrInvalidBus
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if b, exists := c.buses[number]; exists {
+		return b, nil
+	}
+
+	b, err := openBus(c.devPath, strconv.Itoa(number))
+	if os.IsNotExist(err) {
+		return nil, ErrInvalidBus
+	}
+	if err != nil {
+		return nil, ErrUnknown
+	}
+	c.buses[number] = b
+	return b, nil
+}
+
+func CreateController(devPath string) Controller {
+	logrus.Traceln("i2c.CreateController()")
+	return &controller{devPath: devPath, buses: map[int]bus.I2C{}}
+}
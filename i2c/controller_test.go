@@ -0,0 +1,74 @@
+package i2c
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	devPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "i2c-5"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "tty0"), nil, 0644))
+
+	sensor := &Registers{}
+	sensor.Set(0x10, 0xab)
+	sensor.Set(0x11, 0xcd)
+	eeprom := &Registers{}
+
+	ctrl := CreateController(devPath)
+	require.NoError(t, ctrl.AddBus(1, CreateFakeBus(map[uint16]Device{0x48: sensor, 0x50: eeprom})))
+
+	t.Run("add bus - already exists", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, ctrl.AddBus(1, CreateFakeBus(nil)))
+	})
+
+	t.Run("list buses", func(t *testing.T) {
+		buses, err := ctrl.ListBuses()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 5}, buses)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		addresses, err := ctrl.Scan(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint16{0x48, 0x50}, addresses)
+	})
+
+	t.Run("read register", func(t *testing.T) {
+		data, err := ctrl.ReadRegister(1, 0x48, 0x10, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0xab, 0xcd}, data)
+
+		_, err = ctrl.ReadRegister(1, 0x48, 0x10, 0)
+		assert.Equal(t, ErrInvalidValue, err)
+		_, err = ctrl.ReadRegister(1, 0x49, 0x10, 1)
+		assert.Equal(t, bus.ErrNack, err)
+	})
+
+	t.Run("write register", func(t *testing.T) {
+		assert.NoError(t, ctrl.WriteRegister(1, 0x50, 0x20, []byte{1, 2, 3}))
+		assert.Equal(t, byte(3), eeprom.Get(0x22))
+		assert.Equal(t, ErrInvalidValue, ctrl.WriteRegister(1, 0x50, 0x20, nil))
+	})
+
+	t.Run("raw transaction", func(t *testing.T) {
+		r := make([]byte, 3)
+		assert.NoError(t, ctrl.Tx(1, 0x50, []byte{0x20}, r))
+		assert.Equal(t, []byte{1, 2, 3}, r)
+		assert.Equal(t, ErrInvalidAddress, ctrl.Tx(1, 0x78, []byte{0}, nil))
+		assert.Equal(t, ErrInvalidValue, ctrl.Tx(1, 0x50, nil, nil))
+	})
+
+	t.Run("invalid bus", func(t *testing.T) {
+		_, err := ctrl.Scan(7)
+		assert.Equal(t, ErrInvalidBus, err)
+		// regular file does not accept I2C ioctls
+		_, err = ctrl.Scan(5)
+		assert.Equal(t, ErrUnknown, err)
+	})
+}
@@ -0,0 +1,11 @@
+package i2c
+
+import "errors"
+
+var (
+	ErrInvalidBus     = errors.New("invalid bus")
+	ErrInvalidAddress = errors.New("invalid address")
+	ErrInvalidValue   = errors.New("invalid value")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrUnknown        = errors.New("unknown error")
+)
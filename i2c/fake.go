@@ -0,0 +1,83 @@
+package i2c
+
+import (
+	"sync"
+
+	"github.com/markamdev/repico/bus"
+)
+
+// Device is an interface of emulated I2C device used with fake bus
+type Device interface {
+	Tx(w, r []byte) error
+}
+
+// Registers emulates typical register based device: first written byte sets
+// register pointer, following bytes are written to consecutive registers and
+// reads return consecutive registers starting from pointer
+type Registers struct {
+	mtx  sync.Mutex
+	regs [256]byte
+	ptr  byte
+}
+
+func (d *Registers) Tx(w, r []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if len(w) > 0 {
+		d.ptr = w[0]
+		for _, value := range w[1:] {
+			d.regs[d.ptr] = value
+			d.ptr++
+		}
+	}
+	for i := range r {
+		r[i] = d.regs[d.ptr]
+		d.ptr++
+	}
+	return nil
+}
+
+// Get returns register value
+func (d *Registers) Get(reg byte) byte {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return d.regs[reg]
+}
+
+// Set sets register value
+func (d *Registers) Set(reg, value byte) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.regs[reg] = value
+}
+
+type fakeBus struct {
+	mtx     sync.Mutex
+	devices map[uint16]Device
+	closed  bool
+}
+
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.closed {
+		return bus.ErrClosed
+	}
+	dev, exists := b.devices[addr]
+	if !exists {
+		return bus.ErrNack
+	}
+	return dev.Tx(w, r)
+}
+
+func (b *fakeBus) Close() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.closed = true
+	return nil
+}
+
+// CreateFakeBus creates in-memory bus with emulated devices (for tests and development without hardware)
+func CreateFakeBus(devices map[uint16]Device) bus.I2C {
+	return &fakeBus{devices: devices}
+}
@@ -0,0 +1,151 @@
+package i2c
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathBusPrefix = "/i2c-"
+
+	// ioctl requests and flags from linux/i2c-dev.h and linux/i2c.h
+	ioctlSlave = 0x0703
+	ioctlFuncs = 0x0705
+	ioctlRdwr  = 0x0707
+	funcI2C    = 0x00000001
+	flagRead   = 0x0001
+)
+
+// message mirrors struct i2c_msg
+type message struct {
+	addr   uint16
+	flags  uint16
+	length uint16
+	buf    uintptr
+}
+
+// rdwrData mirrors struct i2c_rdwr_ioctl_data
+type rdwrData struct {
+	msgs  uintptr
+	nmsgs uint32
+}
+
+func ioctl(fd, request, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// hwBus is I2C bus handled by kernel driver through /dev/i2c-N character device
+type hwBus struct {
+	mtx      sync.Mutex
+	file     *os.File
+	combined bool
+}
+
+func openBus(devPath, number string) (*hwBus, error) {
+	file, err := os.OpenFile(devPath+pathBusPrefix+number, os.O_RDWR, 0)
+	if err != nil {
+		logrus.Traceln("openBus() cannot open bus device:", err)
+		return nil, err
+	}
+
+	var funcs uint64
+	err = ioctl(file.Fd(), ioctlFuncs, uintptr(unsafe.Pointer(&funcs)))
+	if err != nil {
+		logrus.Traceln("openBus() cannot get adapter functionality:", err)
+		file.Close()
+		return nil, ErrUnknown
+	}
+	return &hwBus{file: file, combined: funcs&funcI2C != 0}, nil
+}
+
+// Tx uses combined (repeated start) transfer if adapter supports it,
+// otherwise separate write and read calls are done for selected slave
+func (b *hwBus) Tx(addr uint16, w, r []byte) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.file == nil {
+		return bus.ErrClosed
+	}
+
+	var err error
+	if b.combined {
+		err = b.rdwr(addr, w, r)
+	} else {
+		err = b.readWrite(addr, w, r)
+	}
+	return translateError(err)
+}
+
+func (b *hwBus) rdwr(addr uint16, w, r []byte) error {
+	msgs := make([]message, 0, 2)
+	if len(w) > 0 {
+		msgs = append(msgs, message{addr: addr, length: uint16(len(w)), buf: uintptr(unsafe.Pointer(&w[0]))})
+	}
+	if len(r) > 0 {
+		msgs = append(msgs, message{addr: addr, flags: flagRead, length: uint16(len(r)), buf: uintptr(unsafe.Pointer(&r[0]))})
+	}
+	data := rdwrData{msgs: uintptr(unsafe.Pointer(&msgs[0])), nmsgs: uint32(len(msgs))}
+	err := ioctl(b.file.Fd(), ioctlRdwr, uintptr(unsafe.Pointer(&data)))
+	runtime.KeepAlive(w)
+	runtime.KeepAlive(r)
+	runtime.KeepAlive(msgs)
+	return err
+}
+
+func (b *hwBus) readWrite(addr uint16, w, r []byte) error {
+	err := ioctl(b.file.Fd(), ioctlSlave, uintptr(addr))
+	if err != nil {
+		return err
+	}
+	if len(w) > 0 {
+		_, err = b.file.Write(w)
+		if err != nil {
+			return err
+		}
+	}
+	if len(r) > 0 {
+		_, err = b.file.Read(r)
+	}
+	return err
+}
+
+func (b *hwBus) Close() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.file == nil {
+		return bus.ErrClosed
+	}
+	err := b.file.Close()
+	b.file = nil
+	return err
+}
+
+// translateError maps errno values reported by I2C adapters to bus errors
+func translateError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	switch err {
+	case nil:
+		return nil
+	case syscall.ENXIO, syscall.EREMOTEIO, syscall.EIO:
+		return bus.ErrNack
+	case syscall.ETIMEDOUT:
+		return bus.ErrTimeout
+	default:
+		logrus.Traceln("I2C transfer error:", err)
+		return ErrUnknown
+	}
+}
@@ -0,0 +1,24 @@
+package i2c
+
+import "github.com/markamdev/repico/bus"
+
+const (
+	// MinAddress - lowest address probed by bus scan (lower ones are reserved)
+	MinAddress = 0x03
+	// MaxAddress - highest valid 7-bit device address (higher ones are reserved)
+	MaxAddress = 0x77
+	// MaxLength - maximum number of bytes in single transfer
+	MaxLength = 4096
+)
+
+// Controller is an interface of object giving access to I2C buses
+type Controller interface {
+	ListBuses() ([]int, error)
+	// AddBus registers additional (ex. software) bus under given number
+	AddBus(number int, b bus.I2C) error
+	// Scan returns addresses of all devices answering on bus
+	Scan(number int) ([]uint16, error)
+	ReadRegister(number int, addr uint16, reg byte, length int) ([]byte, error)
+	WriteRegister(number int, addr uint16, reg byte, data []byte) error
+	Tx(number int, addr uint16, w, r []byte) error
+}
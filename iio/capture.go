@@ -0,0 +1,170 @@
+package iio
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+// Capture enables selected scan elements, reads requested number of scans from /dev/iio:deviceN
+// and disables buffer again. Device trigger has to be configured earlier (if device needs one).
+func (c *controller) Capture(device int, channels []string, samples int, timeout time.Duration) (Capture, error) {
+	logrus.Traceln("iio.controller.Capture()")
+	if len(channels) == 0 || samples <= 0 || samples > MaxSamples || timeout < 0 {
+		return Capture{}, ErrInvalidValue
+	}
+	if timeout == 0 {
+		timeout = DefaultCaptureTimeout
+	}
+	dev := strconv.Itoa(device)
+	if device < 0 || !isDevice(c.basePath, dev) {
+		return Capture{}, ErrInvalidDevice
+	}
+	if !c.buffered(dev) {
+		return Capture{}, ErrNotSupported
+	}
+
+	c.mtx.Lock()
+	if c.capturing[device] {
+		c.mtx.Unlock()
+		return Capture{}, ErrBusy
+	}
+	c.capturing[device] = true
+	c.mtx.Unlock()
+	defer func() {
+		c.mtx.Lock()
+		delete(c.capturing, device)
+		c.mtx.Unlock()
+	}()
+
+	elements, err := c.elements(dev, channels)
+	if err != nil {
+		return Capture{}, err
+	}
+	ordered := make([]*element, len(elements))
+	for i := range elements {
+		ordered[i] = &elements[i]
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].index < ordered[j].index })
+	size := layout(ordered)
+
+	data, err := c.readBuffer(dev, elements, samples*size, timeout)
+	if err != nil {
+		return Capture{}, err
+	}
+
+	result := Capture{Channels: channels, Units: make([]string, len(channels)), Scans: make([][]float64, samples)}
+	scales := make([]float64, len(channels))
+	offsets := make([]float64, len(channels))
+	for i, channel := range channels {
+		result.Units[i] = UnitOf(channel)
+		scales[i], offsets[i], err = calibration(c.basePath, dev, channel)
+		if err != nil {
+			return Capture{}, err
+		}
+	}
+	for s := 0; s < samples; s++ {
+		scan := data[s*size : (s+1)*size]
+		result.Scans[s] = make([]float64, len(elements))
+		for i, el := range elements {
+			result.Scans[s][i] = (float64(el.decode(scan)) + offsets[i]) * scales[i]
+		}
+	}
+	return result, nil
+}
+
+// elements returns scan elements of given channels in the same order
+func (c *controller) elements(device string, channels []string) ([]element, error) {
+	used := map[string]bool{}
+	result := make([]element, 0, len(channels))
+	for _, channel := range channels {
+		if used[channel] || channel == "" || strings.Contains(channel, "/") {
+			return nil, ErrInvalidChannel
+		}
+		used[channel] = true
+		el, err := readElement(c.basePath, device, channel)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, el)
+	}
+	return result, nil
+}
+
+// readBuffer enables only given elements and buffer, reads length bytes and disables buffer
+func (c *controller) readBuffer(device string, elements []element, length int, timeout time.Duration) ([]byte, error) {
+	devPath := devicePath(c.basePath, device)
+	fDev, err := os.OpenFile(devicePath(c.devPath, device), os.O_RDONLY, 0)
+	if err != nil {
+		logrus.Errorln("Failed to open IIO device:", err)
+		return nil, ErrUnknown
+	}
+	defer fDev.Close()
+
+	// buffer cannot be configured when enabled, it also could be left enabled by other process
+	err = sysfs.WriteAttribute(devPath+pathBufferEnable, "0")
+	if err != nil {
+		return nil, ErrUnknown
+	}
+	err = c.selectElements(device, elements)
+	if err != nil {
+		return nil, err
+	}
+	defer c.selectElements(device, nil)
+	err = sysfs.WriteAttribute(devPath+pathBufferLength, strconv.Itoa(length))
+	if err != nil {
+		return nil, ErrUnknown
+	}
+	err = sysfs.WriteAttribute(devPath+pathBufferEnable, "1")
+	if err != nil {
+		return nil, ErrUnknown
+	}
+	defer sysfs.WriteAttribute(devPath+pathBufferEnable, "0")
+
+	// deadline cannot be set on non-pollable files (ex. regular files used in tests)
+	fDev.SetReadDeadline(time.Now().Add(timeout))
+	data := make([]byte, length)
+	_, err = io.ReadFull(fDev, data)
+	if os.IsTimeout(err) {
+		return nil, ErrTimeout
+	}
+	if err != nil {
+		logrus.Errorln("Failed to read IIO device buffer:", err)
+		return nil, ErrUnknown
+	}
+	return data, nil
+}
+
+// selectElements enables given scan elements and disables all others
+func (c *controller) selectElements(device string, elements []element) error {
+	scanPath := devicePath(c.basePath, device) + pathScanElements
+	entries, err := os.ReadDir(scanPath)
+	if err != nil {
+		logrus.Traceln("selectElements() reading directory failed:", err)
+		return ErrUnknown
+	}
+	enabled := map[string]bool{}
+	for _, el := range elements {
+		enabled[attrInputPrefix+el.channel+attrEnableSuffix] = true
+	}
+	for _, ent := range entries {
+		if !strings.HasSuffix(ent.Name(), attrEnableSuffix) {
+			continue
+		}
+		value := "0"
+		if enabled[ent.Name()] {
+			value = "1"
+		}
+		err = sysfs.WriteAttribute(scanPath+"/"+ent.Name(), value)
+		if err != nil {
+			return ErrUnknown
+		}
+	}
+	return nil
+}
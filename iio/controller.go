@@ -0,0 +1,107 @@
+package iio
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	basePath string
+	devPath  string
+
+	mtx       sync.Mutex
+	capturing map[int]bool
+}
+
+// ListDevices returns iio:deviceN devices with their input channels (triggers are skipped)
+func (c *controller) ListDevices() ([]Device, error) {
+	logrus.Traceln("iio.controller.ListDevices()")
+	entries, err := os.ReadDir(c.basePath)
+	if err != nil {
+		logrus.Errorln("Failed to read IIO devices directory:", err)
+		return []Device{}, ErrUnknown
+	}
+
+	result := []Device{}
+	for _, ent := range entries {
+		if !strings.HasPrefix(ent.Name(), pathDevicePrefix[1:]) {
+			continue
+		}
+		device := strings.TrimPrefix(ent.Name(), pathDevicePrefix[1:])
+		number, err := strconv.Atoi(device)
+		if err != nil {
+			continue
+		}
+		channels, err := listChannels(c.basePath, device)
+		if err != nil {
+			return []Device{}, err
+		}
+		name, _ := sysfs.ReadAttribute(devicePath(c.basePath, device) + pathNameSuffix)
+		result = append(result, Device{Number: number, Name: name, Channels: channels, Buffered: c.buffered(device)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Number < result[j].Number })
+	return result, nil
+}
+
+func (c *controller) ReadChannel(device int, channel string) (Reading, error) {
+	logrus.Traceln("iio.controller.ReadChannel()")
+	if strings.Contains(channel, "/") || channel == "" {
+		return Reading{}, ErrInvalidChannel
+	}
+	dev := strconv.Itoa(device)
+	if device < 0 || !isDevice(c.basePath, dev) {
+		return Reading{}, ErrInvalidDevice
+	}
+	return c.read(dev, channel)
+}
+
+func (c *controller) ReadAll(device int) ([]Reading, error) {
+	logrus.Traceln("iio.controller.ReadAll()")
+	dev := strconv.Itoa(device)
+	if device < 0 || !isDevice(c.basePath, dev) {
+		return []Reading{}, ErrInvalidDevice
+	}
+	channels, err := listChannels(c.basePath, dev)
+	if err != nil {
+		return []Reading{}, err
+	}
+
+	result := make([]Reading, 0, len(channels))
+	for _, channel := range channels {
+		reading, err := c.read(dev, channel)
+		if err != nil {
+			return []Reading{}, err
+		}
+		result = append(result, reading)
+	}
+	return result, nil
+}
+
+// read returns raw value of channel with scale and offset applied
+func (c *controller) read(device, channel string) (Reading, error) {
+	raw, err := readRaw(c.basePath, device, channel)
+	if err != nil {
+		return Reading{}, err
+	}
+	scale, offset, err := calibration(c.basePath, device, channel)
+	if err != nil {
+		return Reading{}, err
+	}
+	return Reading{Channel: channel, Raw: raw, Value: (float64(raw) + offset) * scale, Unit: UnitOf(channel)}, nil
+}
+
+func (c *controller) buffered(device string) bool {
+	_, err := os.Stat(devicePath(c.basePath, device) + pathBufferEnable)
+	return err == nil
+}
+
+func CreateController(basePath, devPath string) Controller {
+	logrus.Traceln("iio.CreateController()")
+	return &controller{basePath: basePath, devPath: devPath, capturing: map[int]bool{}}
+}
@@ -0,0 +1,131 @@
+package iio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFakeDevice prepares device directory with attributes similar to ones created by IIO drivers
+func createFakeDevice(t *testing.T, basePath, device string, attributes map[string]string) string {
+	return sysfstest.CreateDir(t, filepath.Join(basePath, "iio:device"+device), attributes)
+}
+
+func TestController(t *testing.T) {
+	basePath := t.TempDir()
+	devPath := t.TempDir()
+	adcDir := createFakeDevice(t, basePath, "0", map[string]string{
+		"name":                             "ads1015",
+		"in_voltage0_raw":                  "-5",
+		"in_voltage1_raw":                  "1000",
+		"in_voltage_scale":                 "0.5",
+		"buffer/enable":                    "0",
+		"buffer/length":                    "0",
+		"scan_elements/in_voltage0_en":     "0",
+		"scan_elements/in_voltage0_index":  "0",
+		"scan_elements/in_voltage0_type":   "le:s12/16>>4",
+		"scan_elements/in_voltage1_en":     "0",
+		"scan_elements/in_voltage1_index":  "1",
+		"scan_elements/in_voltage1_type":   "be:u16/16>>0",
+		"scan_elements/in_timestamp_en":    "1",
+		"scan_elements/in_timestamp_index": "2",
+		"scan_elements/in_timestamp_type":  "le:s64/64>>0",
+	})
+	createFakeDevice(t, basePath, "1", map[string]string{
+		"name":           "cpu_thermal",
+		"in_temp_raw":    "100",
+		"in_temp_offset": "-50",
+		"in_temp_scale":  "100",
+	})
+	sysfstest.CreateDir(t, filepath.Join(basePath, "trigger0"), nil)
+	ctrl := CreateController(basePath, devPath)
+
+	t.Run("list devices", func(t *testing.T) {
+		devices, err := ctrl.ListDevices()
+		assert.NoError(t, err)
+		assert.Equal(t, []Device{
+			{Number: 0, Name: "ads1015", Channels: []string{"voltage0", "voltage1"}, Buffered: true},
+			{Number: 1, Name: "cpu_thermal", Channels: []string{"temp"}},
+		}, devices)
+	})
+
+	t.Run("read channel", func(t *testing.T) {
+		reading, err := ctrl.ReadChannel(0, "voltage0")
+		assert.NoError(t, err)
+		assert.Equal(t, Reading{Channel: "voltage0", Raw: -5, Value: -2.5, Unit: "mV"}, reading)
+
+		reading, err = ctrl.ReadChannel(1, "temp")
+		assert.NoError(t, err)
+		assert.Equal(t, Reading{Channel: "temp", Raw: 100, Value: 5000, Unit: "m°C"}, reading)
+
+		_, err = ctrl.ReadChannel(0, "voltage2")
+		assert.Equal(t, ErrInvalidChannel, err)
+		_, err = ctrl.ReadChannel(0, "../../iio:device1/in_temp")
+		assert.Equal(t, ErrInvalidChannel, err)
+		_, err = ctrl.ReadChannel(2, "voltage0")
+		assert.Equal(t, ErrInvalidDevice, err)
+	})
+
+	t.Run("read all", func(t *testing.T) {
+		readings, err := ctrl.ReadAll(0)
+		assert.NoError(t, err)
+		assert.Equal(t, []Reading{
+			{Channel: "voltage0", Raw: -5, Value: -2.5, Unit: "mV"},
+			{Channel: "voltage1", Raw: 1000, Value: 500, Unit: "mV"},
+		}, readings)
+	})
+
+	t.Run("capture", func(t *testing.T) {
+		// two scans of voltage0 (le:s12/16>>4) and voltage1 (be:u16/16>>0)
+		data := []byte{0xb0, 0xff, 0x03, 0xe8, 0xf0, 0x7f, 0x00, 0x00}
+		require.NoError(t, os.WriteFile(filepath.Join(devPath, "iio:device0"), data, 0644))
+
+		capture, err := ctrl.Capture(0, []string{"voltage1", "voltage0"}, 2, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, Capture{
+			Channels: []string{"voltage1", "voltage0"},
+			Units:    []string{"mV", "mV"},
+			Scans:    [][]float64{{500, -2.5}, {0, 1023.5}},
+		}, capture)
+
+		assert.Equal(t, "8", sysfstest.ReadFile(t, filepath.Join(adcDir, "buffer/length")))
+		assert.Equal(t, "0", sysfstest.ReadFile(t, filepath.Join(adcDir, "buffer/enable")))
+		assert.Equal(t, "0", sysfstest.ReadFile(t, filepath.Join(adcDir, "scan_elements/in_timestamp_en")))
+	})
+
+	t.Run("capture - errors", func(t *testing.T) {
+		_, err := ctrl.Capture(1, []string{"temp"}, 1, 0)
+		assert.Equal(t, ErrNotSupported, err)
+		_, err = ctrl.Capture(0, []string{"voltage0", "voltage0"}, 1, 0)
+		assert.Equal(t, ErrInvalidChannel, err)
+		_, err = ctrl.Capture(0, []string{"voltage0"}, 0, 0)
+		assert.Equal(t, ErrInvalidValue, err)
+		// buffer file contains less data than requested
+		_, err = ctrl.Capture(0, []string{"voltage0"}, 10, 0)
+		assert.Equal(t, ErrUnknown, err)
+	})
+}
+
+func TestElementDecode(t *testing.T) {
+	el, err := parseElementType("voltage0", 0, "be:s24/32>>8")
+	require.NoError(t, err)
+	elements := []*element{&el}
+	assert.Equal(t, 4, layout(elements))
+	assert.Equal(t, int64(-2), el.decode([]byte{0xff, 0xff, 0xfe, 0x00}))
+
+	for _, value := range []string{"le:s12/16", "le:s20/16>>0", "le:u12/12>>0", "le:s8/8X2>>0"} {
+		_, err = parseElementType("voltage0", 0, value)
+		assert.Equal(t, ErrUnknown, err, value)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	elements := []*element{{storage: 2}, {storage: 8}, {storage: 1}}
+	assert.Equal(t, 24, layout(elements))
+	assert.Equal(t, []int{0, 8, 16}, []int{elements[0].offset, elements[1].offset, elements[2].offset})
+}
@@ -0,0 +1,13 @@
+package iio
+
+import "errors"
+
+var (
+	ErrInvalidDevice  = errors.New("invalid device")
+	ErrInvalidChannel = errors.New("invalid channel")
+	ErrInvalidValue   = errors.New("invalid value")
+	ErrNotSupported   = errors.New("buffered capture not supported")
+	ErrBusy           = errors.New("capture already running")
+	ErrTimeout        = errors.New("timeout")
+	ErrUnknown        = errors.New("unknown error")
+)
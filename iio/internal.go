@@ -0,0 +1,217 @@
+package iio
+
+import (
+	"encoding/binary"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathDevicePrefix = "/iio:device"
+	pathNameSuffix   = "/name"
+	pathBufferEnable = "/buffer/enable"
+	pathBufferLength = "/buffer/length"
+	pathScanElements = "/scan_elements"
+	attrInputPrefix  = "in_"
+	attrRawSuffix    = "_raw"
+	attrScaleSuffix  = "_scale"
+	attrOffsetSuffix = "_offset"
+	attrEnableSuffix = "_en"
+	attrIndexSuffix  = "_index"
+	attrTypeSuffix   = "_type"
+)
+
+// elementType matches scan element format, ex. "le:s12/16>>4"
+var elementType = regexp.MustCompile(`^(be|le):(s|u)(\d+)/(\d+)(?:X(\d+))?>>(\d+)$`)
+
+// element describes layout of single channel in buffer scan
+type element struct {
+	channel string
+	index   int
+	big     bool
+	signed  bool
+	bits    uint
+	storage int
+	shift   uint
+	offset  int
+}
+
+func devicePath(basePath, device string) string {
+	return basePath + pathDevicePrefix + device
+}
+
+func isDevice(basePath, device string) bool {
+	return sysfs.Exists(devicePath(basePath, device))
+}
+
+// channelType returns channel name without index and modifiers, ex. "voltage" for "voltage0-voltage1"
+func channelType(channel string) string {
+	end := strings.IndexAny(channel, "0123456789_-")
+	if end < 0 {
+		return channel
+	}
+	return channel[:end]
+}
+
+// listChannels returns names of input channels having _raw attribute
+func listChannels(basePath, device string) ([]string, error) {
+	entries, err := os.ReadDir(devicePath(basePath, device))
+	if err != nil {
+		logrus.Traceln("listChannels() reading directory failed:", err)
+		return nil, ErrUnknown
+	}
+	result := []string{}
+	for _, ent := range entries {
+		name := ent.Name()
+		if strings.HasPrefix(name, attrInputPrefix) && strings.HasSuffix(name, attrRawSuffix) {
+			result = append(result, strings.TrimSuffix(strings.TrimPrefix(name, attrInputPrefix), attrRawSuffix))
+		}
+	}
+	return result, nil
+}
+
+// channelAttribute returns float value of channel attribute or shared (per channel type) one, or
+// default value if none of them exists
+func channelAttribute(basePath, device, channel, suffix string, defValue float64) (float64, error) {
+	devPath := devicePath(basePath, device)
+	for _, name := range []string{channel, channelType(channel)} {
+		value, err := sysfs.ReadAttribute(devPath + "/" + attrInputPrefix + name + suffix)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, ErrUnknown
+		}
+		result, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			logrus.Traceln("channelAttribute() invalid value:", err)
+			return 0, ErrUnknown
+		}
+		return result, nil
+	}
+	return defValue, nil
+}
+
+// calibration returns scale and offset of channel
+func calibration(basePath, device, channel string) (float64, float64, error) {
+	scale, err := channelAttribute(basePath, device, channel, attrScaleSuffix, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := channelAttribute(basePath, device, channel, attrOffsetSuffix, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	return scale, offset, nil
+}
+
+func readRaw(basePath, device, channel string) (int64, error) {
+	value, err := sysfs.ReadAttribute(devicePath(basePath, device) + "/" + attrInputPrefix + channel + attrRawSuffix)
+	if os.IsNotExist(err) {
+		return 0, ErrInvalidChannel
+	}
+	if err != nil {
+		return 0, ErrUnknown
+	}
+	raw, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		logrus.Traceln("readRaw() invalid value:", err)
+		return 0, ErrUnknown
+	}
+	return raw, nil
+}
+
+func scanElementPath(basePath, device, channel, suffix string) string {
+	return devicePath(basePath, device) + pathScanElements + "/" + attrInputPrefix + channel + suffix
+}
+
+// readElement reads index and type of channel in buffer scan
+func readElement(basePath, device, channel string) (element, error) {
+	value, err := sysfs.ReadAttribute(scanElementPath(basePath, device, channel, attrIndexSuffix))
+	if err != nil {
+		return element{}, ErrInvalidChannel
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil {
+		return element{}, ErrUnknown
+	}
+	value, err = sysfs.ReadAttribute(scanElementPath(basePath, device, channel, attrTypeSuffix))
+	if err != nil {
+		return element{}, ErrUnknown
+	}
+	return parseElementType(channel, index, value)
+}
+
+func parseElementType(channel string, index int, value string) (element, error) {
+	match := elementType.FindStringSubmatch(value)
+	if match == nil {
+		logrus.Traceln("parseElementType() unexpected type:", value)
+		return element{}, ErrUnknown
+	}
+	bits, _ := strconv.Atoi(match[3])
+	storage, _ := strconv.Atoi(match[4])
+	shift, _ := strconv.Atoi(match[6])
+	if (match[5] != "" && match[5] != "1") || (storage != 8 && storage != 16 && storage != 32 && storage != 64) ||
+		bits == 0 || bits+shift > storage {
+		logrus.Traceln("parseElementType() unsupported type:", value)
+		return element{}, ErrUnknown
+	}
+	return element{channel: channel, index: index, big: match[1] == "be", signed: match[2] == "s",
+		bits: uint(bits), storage: storage / 8, shift: uint(shift)}, nil
+}
+
+// decode returns value of element stored in scan
+func (el element) decode(scan []byte) int64 {
+	data := scan[el.offset : el.offset+el.storage]
+	var order binary.ByteOrder = binary.LittleEndian
+	if el.big {
+		order = binary.BigEndian
+	}
+	var value uint64
+	switch el.storage {
+	case 1:
+		value = uint64(data[0])
+	case 2:
+		value = uint64(order.Uint16(data))
+	case 4:
+		value = uint64(order.Uint32(data))
+	default:
+		value = order.Uint64(data)
+	}
+
+	value >>= el.shift
+	if el.bits < 64 {
+		value &= 1<<el.bits - 1
+	}
+	if el.signed && el.bits < 64 && value&(1<<(el.bits-1)) != 0 {
+		return int64(value) - 1<<el.bits
+	}
+	return int64(value)
+}
+
+// layout sets offsets of elements (sorted by index) and returns size of single scan
+func layout(elements []*element) int {
+	size := 0
+	largest := 1
+	for i := range elements {
+		if rem := size % elements[i].storage; rem != 0 {
+			size += elements[i].storage - rem
+		}
+		elements[i].offset = size
+		size += elements[i].storage
+		if elements[i].storage > largest {
+			largest = elements[i].storage
+		}
+	}
+	if rem := size % largest; rem != 0 {
+		size += largest - rem
+	}
+	return size
+}
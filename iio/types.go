@@ -0,0 +1,65 @@
+package iio
+
+import "time"
+
+const (
+	// MaxSamples - maximum number of scans in single buffered capture
+	MaxSamples = 4096
+	// DefaultCaptureTimeout - time given for filling the buffer if not set in request
+	DefaultCaptureTimeout = time.Second
+)
+
+// Device describes IIO device with its input channels ("voltage0", "temp", ...)
+type Device struct {
+	Number   int
+	Name     string
+	Channels []string
+	// Buffered is true if device supports buffered capture
+	Buffered bool
+}
+
+// Reading is a value of channel calculated as (raw + offset) * scale
+type Reading struct {
+	Channel string
+	Raw     int64
+	Value   float64
+	// Unit of Value as defined by IIO ABI for channel type (empty if not known)
+	Unit string
+}
+
+// Capture contains samples of selected channels, each scan holds values in order of requested channels
+type Capture struct {
+	Channels []string
+	Units    []string
+	Scans    [][]float64
+}
+
+// Controller is an interface of object giving access to IIO (ex. ADC) devices
+type Controller interface {
+	ListDevices() ([]Device, error)
+	ReadChannel(device int, channel string) (Reading, error)
+	ReadAll(device int) ([]Reading, error)
+	// Capture enables given channels in device buffer and reads given number of scans
+	Capture(device int, channels []string, samples int, timeout time.Duration) (Capture, error)
+}
+
+// units of processed values for channel types (see sysfs-bus-iio ABI document)
+var units = map[string]string{
+	"voltage":          "mV",
+	"altvoltage":       "mV",
+	"current":          "mA",
+	"power":            "mW",
+	"temp":             "m°C",
+	"pressure":         "kPa",
+	"humidityrelative": "m%",
+	"illuminance":      "lx",
+	"accel":            "m/s²",
+	"anglvel":          "rad/s",
+	"magn":             "G",
+	"resistance":       "Ω",
+}
+
+// UnitOf returns unit of values read from channel
+func UnitOf(channel string) string {
+	return units[channelType(channel)]
+}
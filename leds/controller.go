@@ -0,0 +1,142 @@
+package leds
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	basePath string
+
+	mtx sync.Mutex
+}
+
+func (c *controller) ListLEDs() ([]LED, error) {
+	logrus.Traceln("leds.controller.ListLEDs()")
+	entries, err := os.ReadDir(c.basePath)
+	if err != nil {
+		logrus.Errorln("Failed to read LEDs directory:", err)
+		return []LED{}, ErrUnknown
+	}
+
+	result := []LED{}
+	for _, ent := range entries {
+		if !isLED(c.basePath, ent.Name()) {
+			continue
+		}
+		led, err := readLED(c.basePath, ent.Name())
+		if err != nil {
+			logrus.Warnf("Failed to read LED '%s': %v\n", ent.Name(), err)
+			continue
+		}
+		result = append(result, led)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (c *controller) GetLED(name string) (LED, error) {
+	logrus.Traceln("leds.controller.GetLED()")
+	if !c.valid(name) {
+		return LED{}, ErrInvalidLED
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return readLED(c.basePath, name)
+}
+
+func (c *controller) SetBrightness(name string, brightness int) error {
+	logrus.Traceln("leds.controller.SetBrightness()")
+	if !c.valid(name) {
+		return ErrInvalidLED
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	path := ledPath(c.basePath, name)
+	maxBrightness, err := readInt(path + pathMaxBrightnessSuffix)
+	if err != nil {
+		return err
+	}
+	if brightness < 0 || brightness > maxBrightness {
+		return ErrInvalidValue
+	}
+	return writeInt(path+pathBrightnessSuffix, brightness)
+}
+
+func (c *controller) SetTrigger(name, trigger string) error {
+	logrus.Traceln("leds.controller.SetTrigger()")
+	if !c.valid(name) {
+		return ErrInvalidLED
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.setTrigger(name, trigger)
+}
+
+func (c *controller) SetTimer(name string, on, off time.Duration) error {
+	logrus.Traceln("leds.controller.SetTimer()")
+	if !c.valid(name) {
+		return ErrInvalidLED
+	}
+	if on < 0 || off < 0 {
+		return ErrInvalidValue
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	err := c.setTrigger(name, TriggerTimer)
+	if err != nil {
+		return err
+	}
+	// delay attributes are created by timer trigger when it is activated
+	path := ledPath(c.basePath, name)
+	if !sysfs.WaitFor(path+pathDelayOffSuffix, true, delayTimeout) {
+		return ErrUnknown
+	}
+	err = writeInt(path+pathDelayOnSuffix, int(on/time.Millisecond))
+	if err != nil {
+		return err
+	}
+	return writeInt(path+pathDelayOffSuffix, int(off/time.Millisecond))
+}
+
+// setTrigger checks if trigger is available for LED and activates it, must be called with mutex locked
+func (c *controller) setTrigger(name, trigger string) error {
+	path := ledPath(c.basePath, name)
+	content, err := sysfs.ReadAttribute(path + pathTriggerSuffix)
+	if err != nil {
+		return ErrUnknown
+	}
+	triggers, active := parseTriggers(content)
+	if active == trigger {
+		return nil
+	}
+	for _, available := range triggers {
+		if available == trigger {
+			err = sysfs.WriteAttribute(path+pathTriggerSuffix, trigger)
+			if err != nil {
+				logrus.Traceln("setTrigger() writing error:", err)
+				return ErrUnknown
+			}
+			return nil
+		}
+	}
+	return ErrInvalidTrigger
+}
+
+// valid checks if name describes existing LED (and is not a path)
+func (c *controller) valid(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/") && name != "." && name != ".." && isLED(c.basePath, name)
+}
+
+func CreateController(basePath string) Controller {
+	logrus.Traceln("leds.CreateController()")
+	return &controller{basePath: basePath}
+}
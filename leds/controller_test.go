@@ -0,0 +1,76 @@
+package leds
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const triggers = "none rc-feedback kbd-scrolllock timer [heartbeat] mmc0"
+
+func TestController(t *testing.T) {
+	basePath := t.TempDir()
+	actDir := sysfstest.CreateDir(t, filepath.Join(basePath, "ACT"), map[string]string{
+		"brightness": "0", "max_brightness": "255", "trigger": triggers,
+	})
+	pwrDir := sysfstest.CreateDir(t, filepath.Join(basePath, "PWR"), map[string]string{
+		"brightness": "1", "max_brightness": "1", "trigger": "none [timer] heartbeat",
+		"delay_on": "100", "delay_off": "900",
+	})
+	sysfstest.CreateDir(t, filepath.Join(basePath, "not-a-led"), nil)
+	ctrl := CreateController(basePath)
+
+	t.Run("list LEDs", func(t *testing.T) {
+		list, err := ctrl.ListLEDs()
+		assert.NoError(t, err)
+		assert.Equal(t, []LED{
+			{Name: "ACT", Brightness: 0, MaxBrightness: 255, Trigger: TriggerHeartbeat,
+				Triggers: []string{"none", "rc-feedback", "kbd-scrolllock", "timer", "heartbeat", "mmc0"}},
+			{Name: "PWR", Brightness: 1, MaxBrightness: 1, Trigger: TriggerTimer,
+				Triggers: []string{"none", "timer", "heartbeat"}, DelayOn: 100 * time.Millisecond, DelayOff: 900 * time.Millisecond},
+		}, list)
+	})
+
+	t.Run("get LED - invalid name", func(t *testing.T) {
+		for _, name := range []string{"", "LED0", "not-a-led", "..", "../ACT"} {
+			_, err := ctrl.GetLED(name)
+			assert.Equal(t, ErrInvalidLED, err, name)
+		}
+	})
+
+	t.Run("set brightness", func(t *testing.T) {
+		require.NoError(t, ctrl.SetBrightness("ACT", 128))
+		assert.Equal(t, "128", sysfstest.ReadFile(t, filepath.Join(actDir, "brightness")))
+
+		assert.Equal(t, ErrInvalidValue, ctrl.SetBrightness("ACT", 256))
+		assert.Equal(t, ErrInvalidValue, ctrl.SetBrightness("ACT", -1))
+		assert.Equal(t, ErrInvalidLED, ctrl.SetBrightness("LED0", 1))
+	})
+
+	t.Run("set trigger", func(t *testing.T) {
+		require.NoError(t, ctrl.SetTrigger("ACT", "mmc0"))
+		assert.Equal(t, "mmc0", sysfstest.ReadFile(t, filepath.Join(actDir, "trigger")))
+		assert.Equal(t, ErrInvalidTrigger, ctrl.SetTrigger("PWR", "mmc0"))
+	})
+
+	t.Run("set timer", func(t *testing.T) {
+		require.NoError(t, ctrl.SetTimer("PWR", 250*time.Millisecond, 250*time.Millisecond))
+		assert.Equal(t, "250", sysfstest.ReadFile(t, filepath.Join(pwrDir, "delay_on")))
+		assert.Equal(t, "250", sysfstest.ReadFile(t, filepath.Join(pwrDir, "delay_off")))
+
+		assert.Equal(t, ErrInvalidValue, ctrl.SetTimer("PWR", -time.Millisecond, 0))
+	})
+}
+
+func TestParseTriggers(t *testing.T) {
+	triggers, active := parseTriggers("[none] timer heartbeat\n")
+	assert.Equal(t, []string{"none", "timer", "heartbeat"}, triggers)
+	assert.Equal(t, TriggerNone, active)
+
+	_, active = parseTriggers("none timer [heartbeat]")
+	assert.Equal(t, TriggerHeartbeat, active)
+}
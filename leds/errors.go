@@ -0,0 +1,10 @@
+package leds
+
+import "errors"
+
+var (
+	ErrInvalidLED     = errors.New("invalid LED")
+	ErrInvalidValue   = errors.New("invalid value")
+	ErrInvalidTrigger = errors.New("invalid trigger")
+	ErrUnknown        = errors.New("unknown error")
+)
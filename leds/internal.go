@@ -0,0 +1,97 @@
+package leds
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathBrightnessSuffix    = "/brightness"
+	pathMaxBrightnessSuffix = "/max_brightness"
+	pathTriggerSuffix       = "/trigger"
+	pathDelayOnSuffix       = "/delay_on"
+	pathDelayOffSuffix      = "/delay_off"
+)
+
+// delayTimeout limits waiting for timer trigger attributes to appear after trigger is set
+const delayTimeout = time.Second
+
+func ledPath(basePath, name string) string {
+	return basePath + "/" + name
+}
+
+func isLED(basePath, name string) bool {
+	return sysfs.Exists(ledPath(basePath, name) + pathBrightnessSuffix)
+}
+
+func readInt(path string) (int, error) {
+	value, err := sysfs.ReadAttribute(path)
+	if err != nil {
+		return 0, ErrUnknown
+	}
+	result, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		logrus.Traceln("readInt() conversion error:", err)
+		return 0, ErrUnknown
+	}
+	return result, nil
+}
+
+func writeInt(path string, value int) error {
+	err := sysfs.WriteAttribute(path, strconv.Itoa(value))
+	if err != nil {
+		return ErrUnknown
+	}
+	return nil
+}
+
+// parseTriggers returns all triggers and the active one (given in brackets), ex. "none [heartbeat] timer"
+func parseTriggers(content string) ([]string, string) {
+	triggers := strings.Fields(content)
+	active := TriggerNone
+	for i, trigger := range triggers {
+		if strings.HasPrefix(trigger, "[") && strings.HasSuffix(trigger, "]") {
+			triggers[i] = strings.Trim(trigger, "[]")
+			active = triggers[i]
+		}
+	}
+	return triggers, active
+}
+
+func readLED(basePath, name string) (LED, error) {
+	path := ledPath(basePath, name)
+	brightness, err := readInt(path + pathBrightnessSuffix)
+	if err != nil {
+		return LED{}, err
+	}
+	maxBrightness, err := readInt(path + pathMaxBrightnessSuffix)
+	if err != nil {
+		return LED{}, err
+	}
+	content, err := sysfs.ReadAttribute(path + pathTriggerSuffix)
+	if err != nil {
+		return LED{}, ErrUnknown
+	}
+	triggers, active := parseTriggers(content)
+	result := LED{Name: name, Brightness: brightness, MaxBrightness: maxBrightness, Trigger: active, Triggers: triggers}
+
+	if active == TriggerTimer {
+		on, err := readInt(path + pathDelayOnSuffix)
+		if err != nil {
+			return LED{}, err
+		}
+		off, err := readInt(path + pathDelayOffSuffix)
+		if err != nil {
+			return LED{}, err
+		}
+		result.DelayOn = time.Duration(on) * time.Millisecond
+		result.DelayOff = time.Duration(off) * time.Millisecond
+	}
+	return result, nil
+}
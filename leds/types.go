@@ -0,0 +1,37 @@
+package leds
+
+import "time"
+
+const (
+	// TriggerNone - LED is controlled only by brightness
+	TriggerNone = "none"
+	// TriggerTimer - LED blinks with DelayOn and DelayOff times
+	TriggerTimer = "timer"
+	// TriggerHeartbeat - LED blinks with frequency depending on system load
+	TriggerHeartbeat = "heartbeat"
+)
+
+// LED describes current state of LED class device
+type LED struct {
+	Name          string
+	Brightness    int
+	MaxBrightness int
+	// Trigger - kernel event source controlling LED (TriggerNone if not set)
+	Trigger string
+	// Triggers - all triggers available for LED
+	Triggers []string
+	// DelayOn and DelayOff are set only for TriggerTimer
+	DelayOn  time.Duration
+	DelayOff time.Duration
+}
+
+// Controller is an interface of object controlling LEDs handled by kernel LED class
+type Controller interface {
+	ListLEDs() ([]LED, error)
+	GetLED(name string) (LED, error)
+	// SetBrightness sets LED brightness, kernel disables trigger when brightness is set to 0
+	SetBrightness(name string, brightness int) error
+	SetTrigger(name, trigger string) error
+	// SetTimer enables TriggerTimer with given blinking times
+	SetTimer(name string, on, off time.Duration) error
+}
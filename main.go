@@ -5,9 +5,27 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 
+	"github.com/markamdev/repico/bitbang"
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/devices"
+	"github.com/markamdev/repico/display"
+	"github.com/markamdev/repico/encoder"
 	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/groups"
+	"github.com/markamdev/repico/i2c"
+	"github.com/markamdev/repico/iio"
+	"github.com/markamdev/repico/leds"
+	"github.com/markamdev/repico/onewire"
+	"github.com/markamdev/repico/pwm"
+	"github.com/markamdev/repico/sensors"
+	"github.com/markamdev/repico/serial"
 	"github.com/markamdev/repico/server"
+	"github.com/markamdev/repico/servo"
+	"github.com/markamdev/repico/spi"
+	"github.com/markamdev/repico/stepper"
 	v2 "github.com/markamdev/repico/v2"
 	"github.com/namsral/flag"
 	"github.com/sirupsen/logrus"
@@ -16,6 +34,12 @@ import (
 var (
 	port  = flag.Int("repico-port", 8080, "Repico listening port")
 	level = flag.String("log-level", "ERROR", "Log level: ERROR, DEBUG or VERBOSE")
+
+	oneWireTTL = flag.Duration("onewire-cache-ttl", onewire.DefaultCacheTTL, "Time for which 1-Wire sensor readings are reused")
+
+	softI2C     = flag.String("soft-i2c", "", "Software I2C buses as comma separated list of number:sda:scl[:frequency]")
+	softSPI     = flag.String("soft-spi", "", "Software SPI devices as comma separated list of bus.cs:clock:mosi:miso:cs")
+	softOneWire = flag.String("soft-onewire", "", "Pins of software 1-Wire buses as comma separated list")
 )
 
 func main() {
@@ -24,11 +48,41 @@ func main() {
 
 	logrus.Debugln("RePiCo starts listening on port", *port)
 
-	ctrl := gpio.CreateController("/sys/class/gpio")
+	ctrl := gpio.CreateController("/sys/class/gpio", "/dev")
+	pwmCtrl := pwm.CreateController("/sys/class/pwm")
+	servoMgr := servo.CreateManager(pwmCtrl)
+	stepperMgr := stepper.CreateManager(ctrl)
+	encoderMgr := encoder.CreateManager(ctrl)
+	groupMgr := groups.CreateManager(ctrl)
+	i2cCtrl := i2c.CreateController("/dev")
+	spiCtrl := spi.CreateController("/dev")
+	oneWireCtrl := onewire.CreateController("/sys/bus/w1/devices", *oneWireTTL)
+	iioCtrl := iio.CreateController("/sys/bus/iio/devices", "/dev")
+	sensorsCtrl := sensors.CreateController("/sys/class/thermal", "/sys/class/hwmon")
+	ledCtrl := leds.CreateController("/sys/class/leds")
+	serialCtrl := serial.CreateController("/dev")
+	addSoftwareBuses(ctrl, i2cCtrl, spiCtrl, oneWireCtrl)
+	displayMgr := display.CreateManager(ctrl, i2cCtrl)
+	deviceReg := devices.CreateRegistry(ctrl)
+	deviceReg.Register(devices.RelayType, devices.NewRelay)
 
 	newRouter := server.NewHandler()
 	gpioSubRouter := newRouter.GetSubRouter("/v2")
 	v2.AttachHandlers(gpioSubRouter, ctrl)
+	v2.AttachPWMHandlers(gpioSubRouter, pwmCtrl)
+	v2.AttachServoHandlers(gpioSubRouter, servoMgr)
+	v2.AttachStepperHandlers(gpioSubRouter, stepperMgr)
+	v2.AttachEncoderHandlers(gpioSubRouter, encoderMgr)
+	v2.AttachGroupHandlers(gpioSubRouter, groupMgr)
+	v2.AttachI2CHandlers(gpioSubRouter, i2cCtrl)
+	v2.AttachSPIHandlers(gpioSubRouter, spiCtrl)
+	v2.AttachOneWireHandlers(gpioSubRouter, oneWireCtrl)
+	v2.AttachAnalogHandlers(gpioSubRouter, iioCtrl)
+	v2.AttachSensorsHandlers(gpioSubRouter, sensorsCtrl)
+	v2.AttachLEDHandlers(gpioSubRouter, ledCtrl)
+	v2.AttachSerialHandlers(gpioSubRouter, serialCtrl)
+	v2.AttachDisplayHandlers(gpioSubRouter, displayMgr)
+	v2.AttachDeviceHandlers(gpioSubRouter, deviceReg)
 
 	sigChannel := make(chan os.Signal, 1)
 	signal.Notify(sigChannel, os.Interrupt)
@@ -50,6 +104,60 @@ func main() {
 	newRouter.Shutdown(context.Background())
 }
 
+// addSoftwareBuses creates bit-banged buses given in options, invalid ones stop application
+func addSoftwareBuses(ctrl gpio.Claims, i2cCtrl i2c.Controller, spiCtrl spi.Controller, oneWireCtrl onewire.Controller) {
+	for _, spec := range splitList(*softI2C) {
+		number, cfg, err := bitbang.ParseI2C(spec)
+		if err == nil {
+			var b bus.I2C
+			b, err = bitbang.CreateI2C(ctrl, strconv.Itoa(number), cfg)
+			if err == nil {
+				err = i2cCtrl.AddBus(number, b)
+			}
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to create software I2C bus '%s': %v\n", spec, err)
+		}
+	}
+	for _, spec := range splitList(*softSPI) {
+		name, pins, err := bitbang.ParseSPI(spec)
+		var dev spi.Device
+		if err == nil {
+			dev, err = spi.StringToDevice(name)
+		}
+		if err == nil {
+			var b bus.SPI
+			b, err = bitbang.CreateSPI(ctrl, name, pins, bus.SPIConfig{})
+			if err == nil {
+				err = spiCtrl.AddDevice(dev, b)
+			}
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to create software SPI device '%s': %v\n", spec, err)
+		}
+	}
+	for _, spec := range splitList(*softOneWire) {
+		cfg, err := bitbang.ParseOneWire(spec)
+		if err == nil {
+			var b bus.OneWire
+			b, err = bitbang.CreateOneWire(ctrl, spec, cfg)
+			if err == nil {
+				err = oneWireCtrl.AddBus(b)
+			}
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to create software 1-Wire bus '%s': %v\n", spec, err)
+		}
+	}
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 func initLogger() {
 	logrus.SetLevel(logrus.TraceLevel)
 	logrus.SetFormatter(&logrus.TextFormatter{
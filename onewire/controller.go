@@ -0,0 +1,154 @@
+package onewire
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	basePath string
+
+	mtx   sync.Mutex
+	ttl   time.Duration
+	cache map[string]Reading
+	// reading serializes sysfs reads of single device so concurrent requests share one conversion
+	reading map[string]*sync.Mutex
+	buses   []*softBus
+}
+
+// ListDevices returns slaves found in devices directory (bus masters are skipped) and on software buses,
+// devices directory may be missing if w1 drivers are not loaded and software buses are used
+func (c *controller) ListDevices() ([]Device, error) {
+	logrus.Traceln("onewire.controller.ListDevices()")
+	buses := c.softBuses()
+	entries, err := os.ReadDir(c.basePath)
+	if err != nil && !(os.IsNotExist(err) && len(buses) > 0) {
+		logrus.Errorln("Failed to read 1-Wire devices directory:", err)
+		return []Device{}, ErrUnknown
+	}
+
+	result := []Device{}
+	for _, ent := range entries {
+		dev, ok := parseID(ent.Name())
+		if ok {
+			result = append(result, dev)
+		}
+	}
+	for _, sb := range buses {
+		rom, err := sb.readROM()
+		if err != nil {
+			logrus.Warnln("Failed to read ROM on software 1-Wire bus:", err)
+			continue
+		}
+		dev, _ := parseID(romToID(rom))
+		result = append(result, dev)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (c *controller) ReadTemperature(id string) (Reading, error) {
+	logrus.Traceln("onewire.controller.ReadTemperature()")
+	dev, ok := parseID(id)
+	if !ok {
+		return Reading{}, ErrInvalidDevice
+	}
+	var soft *softBus
+	var rom []byte
+	if !isDevice(c.basePath, id) {
+		soft, rom = findSoft(c.softBuses(), id)
+		if soft == nil {
+			return Reading{}, ErrInvalidDevice
+		}
+	}
+	if !IsTemperatureSensor(dev.Family) {
+		return Reading{}, ErrNotSupported
+	}
+
+	c.mtx.Lock()
+	lock, exists := c.reading[id]
+	if !exists {
+		lock = &sync.Mutex{}
+		c.reading[id] = lock
+	}
+	c.mtx.Unlock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	if cached, ok := c.cached(id); ok {
+		return cached, nil
+	}
+	var temperature float64
+	var err error
+	if soft != nil {
+		temperature, err = soft.readTemperature(rom)
+	} else {
+		temperature, err = readTemperature(c.basePath, id)
+	}
+	if err != nil {
+		return Reading{}, err
+	}
+	result := Reading{Device: dev, Temperature: temperature, Time: time.Now()}
+
+	c.mtx.Lock()
+	c.cache[id] = result
+	c.mtx.Unlock()
+	return result, nil
+}
+
+func (c *controller) AddBus(b bus.OneWire) error {
+	logrus.Traceln("onewire.controller.AddBus()")
+	if b == nil {
+		return ErrInvalidBus
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.buses = append(c.buses, &softBus{ow: b})
+	return nil
+}
+
+func (c *controller) SetCacheTTL(ttl time.Duration) {
+	logrus.Traceln("onewire.controller.SetCacheTTL()")
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.ttl = ttl
+}
+
+func (c *controller) GetCacheTTL() time.Duration {
+	logrus.Traceln("onewire.controller.GetCacheTTL()")
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.ttl
+}
+
+func (c *controller) softBuses() []*softBus {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.buses
+}
+
+// cached returns reading which is still valid
+func (c *controller) cached(id string) (Reading, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	reading, exists := c.cache[id]
+	if !exists || time.Since(reading.Time) >= c.ttl {
+		return Reading{}, false
+	}
+	return reading, true
+}
+
+func CreateController(basePath string, ttl time.Duration) Controller {
+	logrus.Traceln("onewire.CreateController()")
+	ctrl := &controller{basePath: basePath, cache: map[string]Reading{}, reading: map[string]*sync.Mutex{}}
+	ctrl.SetCacheTTL(ttl)
+	return ctrl
+}
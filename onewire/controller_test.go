@@ -0,0 +1,172 @@
+package onewire
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	slaveValid   = "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"
+	slaveInvalid = "72 01 4b 46 7f ff 0e 10 57 : crc=ff NO\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"
+)
+
+// createFakeSlave prepares device directory similar to one created by w1 kernel drivers
+func createFakeSlave(t *testing.T, basePath, id, content string) {
+	sysfstest.CreateDir(t, filepath.Join(basePath, id), nil)
+	if content != "" {
+		writeFakeSlave(t, basePath, id, content)
+	}
+}
+
+func writeFakeSlave(t *testing.T, basePath, id, content string) {
+	sysfstest.WriteFile(t, filepath.Join(basePath, id, "w1_slave"), content)
+}
+
+func TestController(t *testing.T) {
+	basePath := t.TempDir()
+	createFakeSlave(t, basePath, "w1_bus_master1", "")
+	createFakeSlave(t, basePath, "28-0316a2793aff", slaveValid)
+	createFakeSlave(t, basePath, "10-000802b4c3d1", slaveInvalid)
+	createFakeSlave(t, basePath, "01-00001a2b3c4d", "")
+	ctrl := CreateController(basePath, time.Hour)
+
+	t.Run("list devices", func(t *testing.T) {
+		devices, err := ctrl.ListDevices()
+		assert.NoError(t, err)
+		assert.Equal(t, []Device{
+			{ID: "01-00001a2b3c4d", Family: 0x01},
+			{ID: "10-000802b4c3d1", Family: 0x10},
+			{ID: "28-0316a2793aff", Family: 0x28},
+		}, devices)
+	})
+
+	t.Run("read temperature", func(t *testing.T) {
+		reading, err := ctrl.ReadTemperature("28-0316a2793aff")
+		assert.NoError(t, err)
+		assert.Equal(t, 23.125, reading.Temperature)
+		assert.Equal(t, byte(0x28), reading.Family)
+	})
+
+	t.Run("read temperature - cached", func(t *testing.T) {
+		writeFakeSlave(t, basePath, "28-0316a2793aff", "72 01 : crc=57 YES\n72 01 t=-1500\n")
+		reading, err := ctrl.ReadTemperature("28-0316a2793aff")
+		assert.NoError(t, err)
+		assert.Equal(t, 23.125, reading.Temperature)
+
+		ctrl.SetCacheTTL(0)
+		assert.Equal(t, time.Duration(0), ctrl.GetCacheTTL())
+		reading, err = ctrl.ReadTemperature("28-0316a2793aff")
+		assert.NoError(t, err)
+		assert.Equal(t, -1.5, reading.Temperature)
+	})
+
+	t.Run("read temperature - errors", func(t *testing.T) {
+		_, err := ctrl.ReadTemperature("10-000802b4c3d1")
+		assert.Equal(t, ErrCRC, err)
+		_, err = ctrl.ReadTemperature("01-00001a2b3c4d")
+		assert.Equal(t, ErrNotSupported, err)
+		_, err = ctrl.ReadTemperature("28-000000000000")
+		assert.Equal(t, ErrInvalidDevice, err)
+		_, err = ctrl.ReadTemperature("../28-0316a2793aff")
+		assert.Equal(t, ErrInvalidDevice, err)
+		_, err = ctrl.ReadTemperature("w1_bus_master1")
+		assert.Equal(t, ErrInvalidDevice, err)
+	})
+}
+
+func TestParseSlave(t *testing.T) {
+	temperature, err := parseSlave(slaveValid)
+	assert.NoError(t, err)
+	assert.Equal(t, 23.125, temperature)
+
+	_, err = parseSlave(slaveInvalid)
+	assert.Equal(t, ErrCRC, err)
+	_, err = parseSlave("")
+	assert.Equal(t, ErrUnknown, err)
+	_, err = parseSlave("00 : crc=00 YES\n00 t=abc\n")
+	assert.Equal(t, ErrUnknown, err)
+}
+
+// fakeBus emulates software bus with single temperature sensor
+type fakeBus struct {
+	rom        []byte
+	scratchpad []byte
+	converted  bool
+}
+
+func newFakeBus(rom []byte, scratchpad []byte) *fakeBus {
+	rom = append(rom, crc8(rom))
+	return &fakeBus{rom: rom, scratchpad: scratchpad}
+}
+
+func (fb *fakeBus) Reset() error {
+	if fb.rom == nil {
+		return bus.ErrNoDevice
+	}
+	return nil
+}
+
+func (fb *fakeBus) Tx(w, r []byte) error {
+	switch {
+	case len(w) == 1 && w[0] == cmdReadROM:
+		copy(r, fb.rom)
+	case len(w) == 2+romLength && w[0] == cmdMatchROM && bytes.Equal(w[1:1+romLength], fb.rom):
+		if w[1+romLength] == cmdConvert {
+			fb.converted = true
+		} else if w[1+romLength] == cmdReadScratchpad && fb.converted {
+			copy(r, fb.scratchpad)
+		}
+	default:
+		for i := range r {
+			r[i] = 0xff
+		}
+	}
+	return nil
+}
+
+func (fb *fakeBus) Close() error {
+	return nil
+}
+
+func TestSoftwareBus(t *testing.T) {
+	conversionTime = time.Millisecond
+	ctrl := CreateController(filepath.Join(t.TempDir(), "missing"), 0)
+	assert.Equal(t, ErrInvalidBus, ctrl.AddBus(nil))
+	require.NoError(t, ctrl.AddBus(newFakeBus([]byte{0x28, 0xff, 0x3a, 0x79, 0xa2, 0x16, 0x03},
+		[]byte{0x72, 0x01, 0x4b, 0x46, 0x7f, 0xff, 0x0e, 0x10, 0x57})))
+	require.NoError(t, ctrl.AddBus(newFakeBus([]byte{0x10, 0xd1, 0xc3, 0xb4, 0x02, 0x08, 0x00},
+		[]byte{0x72, 0x01, 0x4b, 0x46, 0x7f, 0xff, 0x0e, 0x10, 0x00})))
+	require.NoError(t, ctrl.AddBus(&fakeBus{}))
+
+	t.Run("list devices", func(t *testing.T) {
+		devices, err := ctrl.ListDevices()
+		assert.NoError(t, err)
+		assert.Equal(t, []Device{
+			{ID: "10-000802b4c3d1", Family: 0x10},
+			{ID: "28-0316a2793aff", Family: 0x28},
+		}, devices)
+	})
+
+	t.Run("read temperature", func(t *testing.T) {
+		reading, err := ctrl.ReadTemperature("28-0316a2793aff")
+		assert.NoError(t, err)
+		assert.Equal(t, 23.125, reading.Temperature)
+
+		_, err = ctrl.ReadTemperature("10-000802b4c3d1")
+		assert.Equal(t, ErrCRC, err)
+		_, err = ctrl.ReadTemperature("28-000000000000")
+		assert.Equal(t, ErrInvalidDevice, err)
+	})
+}
+
+func TestCRC(t *testing.T) {
+	assert.Equal(t, byte(0xa2), crc8([]byte{0x02, 0x1c, 0xb8, 0x01, 0x00, 0x00, 0x00}))
+	assert.Equal(t, byte(0x57), crc8([]byte{0x72, 0x01, 0x4b, 0x46, 0x7f, 0xff, 0x0e, 0x10}))
+}
@@ -0,0 +1,11 @@
+package onewire
+
+import "errors"
+
+var (
+	ErrInvalidDevice = errors.New("invalid device")
+	ErrInvalidBus    = errors.New("invalid bus")
+	ErrNotSupported  = errors.New("device not supported")
+	ErrCRC           = errors.New("CRC check failed")
+	ErrUnknown       = errors.New("unknown error")
+)
@@ -0,0 +1,72 @@
+package onewire
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathSlaveSuffix = "/w1_slave"
+
+	crcValid          = "YES"
+	temperaturePrefix = "t="
+)
+
+var slaveName = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{12}$`)
+
+func devicePath(basePath, id string) string {
+	return basePath + "/" + id
+}
+
+func isDevice(basePath, id string) bool {
+	return sysfs.Exists(devicePath(basePath, id))
+}
+
+// parseID returns device described by slave name or false if name does not describe slave (ex. bus master)
+func parseID(id string) (Device, bool) {
+	if !slaveName.MatchString(id) {
+		return Device{}, false
+	}
+	family, _ := strconv.ParseUint(id[:2], 16, 8)
+	return Device{ID: id, Family: byte(family)}, true
+}
+
+// readTemperature reads and parses w1_slave file of w1_therm driver:
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+func readTemperature(basePath, id string) (float64, error) {
+	content, err := sysfs.ReadAttribute(devicePath(basePath, id) + pathSlaveSuffix)
+	if err != nil {
+		return 0, ErrUnknown
+	}
+	return parseSlave(content)
+}
+
+func parseSlave(content string) (float64, error) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) != 2 {
+		logrus.Traceln("parseSlave() unexpected content:", content)
+		return 0, ErrUnknown
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), crcValid) {
+		return 0, ErrCRC
+	}
+	pos := strings.LastIndex(lines[1], temperaturePrefix)
+	if pos < 0 {
+		logrus.Traceln("parseSlave() temperature not found:", lines[1])
+		return 0, ErrUnknown
+	}
+	milli, err := strconv.Atoi(strings.TrimSpace(lines[1][pos+len(temperaturePrefix):]))
+	if err != nil {
+		logrus.Traceln("parseSlave() invalid temperature:", err)
+		return 0, ErrUnknown
+	}
+	return float64(milli) / 1000, nil
+}
@@ -0,0 +1,123 @@
+package onewire
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cmdReadROM        = 0x33
+	cmdMatchROM       = 0x55
+	cmdConvert        = 0x44
+	cmdReadScratchpad = 0xbe
+
+	romLength        = 8
+	scratchpadLength = 9
+	familyDS18S20    = 0x10
+)
+
+// conversionTime - time of temperature conversion with 12-bit resolution
+var conversionTime = 750 * time.Millisecond
+
+// softBus is a software 1-Wire bus with single slave found with Read ROM command,
+// bus interface gives byte access only and search algorithm needs single bit slots
+type softBus struct {
+	mtx sync.Mutex
+	ow  bus.OneWire
+}
+
+// readROM returns ROM (family, serial number and CRC) of the only slave on bus
+func (sb *softBus) readROM() ([]byte, error) {
+	sb.mtx.Lock()
+	defer sb.mtx.Unlock()
+	err := sb.ow.Reset()
+	if err != nil {
+		return nil, err
+	}
+	rom := make([]byte, romLength)
+	err = sb.ow.Tx([]byte{cmdReadROM}, rom)
+	if err != nil {
+		return nil, err
+	}
+	if crc8(rom[:romLength-1]) != rom[romLength-1] {
+		return nil, ErrCRC
+	}
+	return rom, nil
+}
+
+// readTemperature starts conversion and reads result from sensor scratchpad
+func (sb *softBus) readTemperature(rom []byte) (float64, error) {
+	sb.mtx.Lock()
+	defer sb.mtx.Unlock()
+	err := sb.command(rom, cmdConvert, nil)
+	if err != nil {
+		logrus.Errorln("Failed to start temperature conversion:", err)
+		return 0, ErrUnknown
+	}
+	time.Sleep(conversionTime)
+
+	scratchpad := make([]byte, scratchpadLength)
+	err = sb.command(rom, cmdReadScratchpad, scratchpad)
+	if err != nil {
+		logrus.Errorln("Failed to read scratchpad:", err)
+		return 0, ErrUnknown
+	}
+	if crc8(scratchpad[:scratchpadLength-1]) != scratchpad[scratchpadLength-1] {
+		return 0, ErrCRC
+	}
+	raw := int16(uint16(scratchpad[0]) | uint16(scratchpad[1])<<8)
+	if rom[0] == familyDS18S20 {
+		return float64(raw) / 2, nil
+	}
+	return float64(raw) / 16, nil
+}
+
+// command resets bus, selects slave with given ROM and sends command, sb.mtx has to be locked
+func (sb *softBus) command(rom []byte, cmd byte, r []byte) error {
+	err := sb.ow.Reset()
+	if err != nil {
+		return err
+	}
+	w := append(append([]byte{cmdMatchROM}, rom...), cmd)
+	return sb.ow.Tx(w, r)
+}
+
+// romToID returns slave name in the same format as used by w1 kernel drivers
+func romToID(rom []byte) string {
+	serial := uint64(0)
+	for i := romLength - 2; i > 0; i-- {
+		serial = serial<<8 | uint64(rom[i])
+	}
+	return fmt.Sprintf("%02x-%012x", rom[0], serial)
+}
+
+// crc8 computes Dallas/Maxim CRC used by ROM and scratchpad
+func crc8(data []byte) byte {
+	crc := byte(0)
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			mix := (crc ^ b) & 1
+			crc >>= 1
+			if mix != 0 {
+				crc ^= 0x8c
+			}
+			b >>= 1
+		}
+	}
+	return crc
+}
+
+// findSoft returns software bus with slave of given ID and its ROM
+func findSoft(buses []*softBus, id string) (*softBus, []byte) {
+	for _, sb := range buses {
+		rom, err := sb.readROM()
+		if err == nil && romToID(rom) == id {
+			return sb, rom
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package onewire
+
+import (
+	"time"
+
+	"github.com/markamdev/repico/bus"
+)
+
+// DefaultCacheTTL - time for which temperature reading is reused (single conversion takes up to 750ms)
+const DefaultCacheTTL = 5 * time.Second
+
+// Device describes single 1-Wire slave found on bus
+type Device struct {
+	// ID - slave name in sysfs ("FF-SSSSSSSSSSSS" where FF is family code)
+	ID     string
+	Family byte
+}
+
+// Reading is a temperature (in Celsius degrees) measured by sensor
+type Reading struct {
+	Device
+	Temperature float64
+	Time        time.Time
+}
+
+// Controller is an interface of object giving access to 1-Wire devices handled by w1 kernel drivers
+type Controller interface {
+	ListDevices() ([]Device, error)
+	// AddBus registers additional (ex. software) bus with single slave
+	AddBus(b bus.OneWire) error
+	// ReadTemperature returns cached reading if it is not older than cache TTL
+	ReadTemperature(id string) (Reading, error)
+	SetCacheTTL(ttl time.Duration)
+	GetCacheTTL() time.Duration
+}
+
+// families of temperature sensors supported by w1_therm driver
+var thermFamilies = map[byte]string{
+	0x10: "DS18S20",
+	0x22: "DS1822",
+	0x28: "DS18B20",
+	0x3b: "DS1825",
+	0x42: "DS28EA00",
+}
+
+// FamilyToString returns name of sensor family or empty string if family is not known
+func FamilyToString(family byte) string {
+	return thermFamilies[family]
+}
+
+// IsTemperatureSensor returns true if devices of given family can be read with ReadTemperature
+func IsTemperatureSensor(family byte) bool {
+	_, exists := thermFamilies[family]
+	return exists
+}
@@ -0,0 +1,201 @@
+package pwm
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	basePath string
+}
+
+func (c *controller) ExportChannel(chip, channel int) error {
+	logrus.Traceln("pwm.controller.ExportChannel()")
+	chipString, channelString, err := c.validateChannel(chip, channel)
+	if err != nil {
+		return err
+	}
+
+	count, err := channelCount(c.basePath, chipString)
+	if err != nil {
+		logrus.Errorln("Failed to read number of channels:", err)
+		return err
+	}
+	if channel >= count {
+		return ErrInvalidChannel
+	}
+
+	if isExported(c.basePath, chipString, channelString) {
+		return ErrAlreadyExported
+	}
+
+	return exportChannel(c.basePath, chipString, channelString)
+}
+
+func (c *controller) UnexportChannel(chip, channel int) error {
+	logrus.Traceln("pwm.controller.UnexportChannel()")
+	chipString, channelString, err := c.exportedChannel(chip, channel)
+	if err != nil {
+		return err
+	}
+
+	return unexportChannel(c.basePath, chipString, channelString)
+}
+
+func (c *controller) ListExportedChannels() ([]Channel, error) {
+	logrus.Traceln("pwm.controller.ListExportedChannels()")
+	channels, err := listExported(c.basePath)
+	if err != nil {
+		return []Channel{}, ErrUnknown
+	}
+	logrus.Debug("Currently detected PWM channels:", channels)
+
+	result := make([]Channel, 0, len(channels))
+	for _, ch := range channels {
+		chipInt, _ := strconv.Atoi(ch[0])
+		channelInt, _ := strconv.Atoi(ch[1])
+		result = append(result, Channel{Chip: chipInt, Channel: channelInt})
+	}
+	return result, nil
+}
+
+func (c *controller) GetState(chip, channel int) (State, error) {
+	logrus.Traceln("pwm.controller.GetState()")
+	chipString, channelString, err := c.exportedChannel(chip, channel)
+	if err != nil {
+		return State{}, err
+	}
+
+	period, err := getPeriod(c.basePath, chipString, channelString)
+	if err != nil {
+		logrus.Errorln("Failed to get period:", err)
+		return State{}, err
+	}
+	duty, err := getDutyCycle(c.basePath, chipString, channelString)
+	if err != nil {
+		logrus.Errorln("Failed to get duty cycle:", err)
+		return State{}, err
+	}
+	polarity, err := getPolarity(c.basePath, chipString, channelString)
+	if err != nil {
+		logrus.Errorln("Failed to get polarity:", err)
+		return State{}, err
+	}
+	enabled, err := getEnabled(c.basePath, chipString, channelString)
+	if err != nil {
+		logrus.Errorln("Failed to get enable state:", err)
+		return State{}, err
+	}
+
+	return State{
+		Channel:   Channel{Chip: chip, Channel: channel},
+		Period:    time.Duration(period),
+		DutyCycle: time.Duration(duty),
+		Polarity:  StringToPolarity(polarity),
+		Enabled:   enabled == "1",
+	}, nil
+}
+
+func (c *controller) SetPeriod(chip, channel int, period time.Duration) error {
+	logrus.Traceln("pwm.controller.SetPeriod()")
+	if period <= 0 {
+		return ErrInvalidValue
+	}
+	chipString, channelString, err := c.exportedChannel(chip, channel)
+	if err != nil {
+		return err
+	}
+
+	// kernel refuses period shorter than currently set duty cycle
+	duty, err := getDutyCycle(c.basePath, chipString, channelString)
+	if err != nil {
+		logrus.Errorln("Failed to get duty cycle:", err)
+		return err
+	}
+	if int64(period) < duty {
+		return ErrInvalidValue
+	}
+
+	return setPeriod(c.basePath, chipString, channelString, int64(period))
+}
+
+func (c *controller) SetDutyCycle(chip, channel int, duty time.Duration) error {
+	logrus.Traceln("pwm.controller.SetDutyCycle()")
+	if duty < 0 {
+		return ErrInvalidValue
+	}
+	chipString, channelString, err := c.exportedChannel(chip, channel)
+	if err != nil {
+		return err
+	}
+
+	period, err := getPeriod(c.basePath, chipString, channelString)
+	if err != nil {
+		logrus.Errorln("Failed to get period:", err)
+		return err
+	}
+	if int64(duty) > period {
+		return ErrInvalidValue
+	}
+
+	return setDutyCycle(c.basePath, chipString, channelString, int64(duty))
+}
+
+func (c *controller) SetPolarity(chip, channel int, polarity Polarity) error {
+	logrus.Traceln("pwm.controller.SetPolarity()")
+	if polarity != Normal && polarity != Inversed {
+		return ErrInvalidPolarity
+	}
+	chipString, channelString, err := c.exportedChannel(chip, channel)
+	if err != nil {
+		return err
+	}
+
+	return setPolarity(c.basePath, chipString, channelString, PolarityToString(polarity))
+}
+
+func (c *controller) SetEnabled(chip, channel int, enabled bool) error {
+	logrus.Traceln("pwm.controller.SetEnabled()")
+	chipString, channelString, err := c.exportedChannel(chip, channel)
+	if err != nil {
+		return err
+	}
+
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return setEnabled(c.basePath, chipString, channelString, value)
+}
+
+func (c *controller) validateChannel(chip, channel int) (string, string, error) {
+	if chip < 0 {
+		return "", "", ErrInvalidChip
+	}
+	if channel < 0 {
+		return "", "", ErrInvalidChannel
+	}
+	chipString := strconv.Itoa(chip)
+	if !isChip(c.basePath, chipString) {
+		return "", "", ErrInvalidChip
+	}
+	return chipString, strconv.Itoa(channel), nil
+}
+
+func (c *controller) exportedChannel(chip, channel int) (string, string, error) {
+	chipString, channelString, err := c.validateChannel(chip, channel)
+	if err != nil {
+		return "", "", err
+	}
+	if !isExported(c.basePath, chipString, channelString) {
+		return "", "", ErrNotExported
+	}
+	return chipString, channelString, nil
+}
+
+func CreateController(pwmPath string) Controller {
+	logrus.Traceln("pwm.CreateController()")
+	return &controller{basePath: pwmPath}
+}
@@ -0,0 +1,105 @@
+package pwm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+)
+
+// createFakeChip prepares minimal pwmchip directory similar to one created by kernel
+func createFakeChip(t *testing.T, basePath string, chip string, npwm string) string {
+	return sysfstest.CreateDir(t, filepath.Join(basePath, "pwmchip"+chip), map[string]string{
+		"export": "", "unexport": "", "npwm": npwm,
+	})
+}
+
+// createFakeChannel emulates kernel reaction on writing channel number to export file
+func createFakeChannel(t *testing.T, chipDir string, channel string) string {
+	return sysfstest.CreateDir(t, filepath.Join(chipDir, "pwm"+channel), map[string]string{
+		"period": "0", "duty_cycle": "0", "polarity": "normal", "enable": "0",
+	})
+}
+
+func TestController(t *testing.T) {
+	basePath := t.TempDir()
+	chipDir := createFakeChip(t, basePath, "0", "2")
+	ctrl := CreateController(basePath)
+
+	t.Run("export - invalid chip", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidChip, ctrl.ExportChannel(1, 0))
+		assert.Equal(t, ErrInvalidChip, ctrl.ExportChannel(-1, 0))
+	})
+
+	t.Run("export - channel out of range", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidChannel, ctrl.ExportChannel(0, 2))
+		assert.Equal(t, ErrInvalidChannel, ctrl.ExportChannel(0, -1))
+	})
+
+	t.Run("export - correct case", func(t *testing.T) {
+		assert.NoError(t, ctrl.ExportChannel(0, 1))
+		assert.Equal(t, "1", sysfstest.ReadFile(t, filepath.Join(chipDir, "export")))
+	})
+
+	t.Run("operations on not exported channel", func(t *testing.T) {
+		_, err := ctrl.GetState(0, 1)
+		assert.Equal(t, ErrNotExported, err)
+		assert.Equal(t, ErrNotExported, ctrl.SetPeriod(0, 1, time.Millisecond))
+		assert.Equal(t, ErrNotExported, ctrl.SetEnabled(0, 1, true))
+		assert.Equal(t, ErrNotExported, ctrl.UnexportChannel(0, 1))
+	})
+
+	chanDir := createFakeChannel(t, chipDir, "1")
+
+	t.Run("export - already exported", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExported, ctrl.ExportChannel(0, 1))
+	})
+
+	t.Run("list exported channels", func(t *testing.T) {
+		channels, err := ctrl.ListExportedChannels()
+		assert.NoError(t, err)
+		assert.Equal(t, []Channel{{Chip: 0, Channel: 1}}, channels)
+	})
+
+	t.Run("set period and duty cycle", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidValue, ctrl.SetPeriod(0, 1, 0))
+		assert.NoError(t, ctrl.SetPeriod(0, 1, 20*time.Millisecond))
+		assert.Equal(t, "20000000", sysfstest.ReadFile(t, filepath.Join(chanDir, "period")))
+
+		assert.Equal(t, ErrInvalidValue, ctrl.SetDutyCycle(0, 1, 21*time.Millisecond))
+		assert.NoError(t, ctrl.SetDutyCycle(0, 1, 1500*time.Microsecond))
+		assert.Equal(t, "1500000", sysfstest.ReadFile(t, filepath.Join(chanDir, "duty_cycle")))
+
+		assert.Equal(t, ErrInvalidValue, ctrl.SetPeriod(0, 1, time.Millisecond))
+	})
+
+	t.Run("set polarity", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidPolarity, ctrl.SetPolarity(0, 1, InvalidPolarity))
+		assert.NoError(t, ctrl.SetPolarity(0, 1, Inversed))
+		assert.Equal(t, "inversed", sysfstest.ReadFile(t, filepath.Join(chanDir, "polarity")))
+	})
+
+	t.Run("enable", func(t *testing.T) {
+		assert.NoError(t, ctrl.SetEnabled(0, 1, true))
+		assert.Equal(t, "1", sysfstest.ReadFile(t, filepath.Join(chanDir, "enable")))
+	})
+
+	t.Run("get state", func(t *testing.T) {
+		state, err := ctrl.GetState(0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, State{
+			Channel:   Channel{Chip: 0, Channel: 1},
+			Period:    20 * time.Millisecond,
+			DutyCycle: 1500 * time.Microsecond,
+			Polarity:  Inversed,
+			Enabled:   true,
+		}, state)
+	})
+
+	t.Run("unexport - correct case", func(t *testing.T) {
+		assert.NoError(t, ctrl.UnexportChannel(0, 1))
+		assert.Equal(t, "1", sysfstest.ReadFile(t, filepath.Join(chipDir, "unexport")))
+	})
+}
@@ -0,0 +1,14 @@
+package pwm
+
+import "errors"
+
+var (
+	ErrInvalidChip     = errors.New("invalid chip")
+	ErrInvalidChannel  = errors.New("invalid channel")
+	ErrAlreadyExported = errors.New("already exported")
+	ErrNotExported     = errors.New("not exported")
+	ErrInvalidValue    = errors.New("invalid value")
+	ErrInvalidPolarity = errors.New("invalid polarity")
+	ErrUnknown         = errors.New("unknown error")
+	ErrNotImplemented  = errors.New("not implemented")
+)
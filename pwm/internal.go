@@ -0,0 +1,172 @@
+package pwm
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathChipPrefix     = "/pwmchip"
+	pathChannelPrefix  = "/pwm"
+	pathExportSuffix   = "/export"
+	pathUnexportSuffix = "/unexport"
+	pathNpwmSuffix     = "/npwm"
+	pathPeriodSuffix   = "/period"
+	pathDutySuffix     = "/duty_cycle"
+	pathPolaritySuffix = "/polarity"
+	pathEnableSuffix   = "/enable"
+)
+
+func chipPath(basePath, chip string) string {
+	return basePath + pathChipPrefix + chip
+}
+
+func channelPath(basePath, chip, channel string) string {
+	return chipPath(basePath, chip) + pathChannelPrefix + channel
+}
+
+func isChip(basePath, chip string) bool {
+	return sysfs.Exists(chipPath(basePath, chip))
+}
+
+func isExported(basePath, chip, channel string) bool {
+	return sysfs.Exists(channelPath(basePath, chip, channel))
+}
+
+func readIntAttribute(path string) (int64, error) {
+	value, err := sysfs.ReadAttribute(path)
+	if err != nil {
+		return 0, ErrUnknown
+	}
+
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		logrus.Traceln("readIntAttribute() conversion error:", err)
+		return 0, ErrUnknown
+	}
+	return result, nil
+}
+
+func writeIntAttribute(path string, value int64) error {
+	err := sysfs.WriteAttribute(path, strconv.FormatInt(value, 10))
+	if err != nil {
+		return ErrUnknown
+	}
+	return nil
+}
+
+func channelCount(basePath, chip string) (int, error) {
+	count, err := readIntAttribute(chipPath(basePath, chip) + pathNpwmSuffix)
+	return int(count), err
+}
+
+func exportChannel(basePath, chip, channel string) error {
+	err := sysfs.WriteAttribute(chipPath(basePath, chip)+pathExportSuffix, channel)
+	if err != nil {
+		return ErrUnknown
+	}
+	return nil
+}
+
+func unexportChannel(basePath, chip, channel string) error {
+	err := sysfs.WriteAttribute(chipPath(basePath, chip)+pathUnexportSuffix, channel)
+	if err != nil {
+		return ErrUnknown
+	}
+	return nil
+}
+
+func setPeriod(basePath, chip, channel string, period int64) error {
+	return writeIntAttribute(channelPath(basePath, chip, channel)+pathPeriodSuffix, period)
+}
+
+func getPeriod(basePath, chip, channel string) (int64, error) {
+	return readIntAttribute(channelPath(basePath, chip, channel) + pathPeriodSuffix)
+}
+
+func setDutyCycle(basePath, chip, channel string, duty int64) error {
+	return writeIntAttribute(channelPath(basePath, chip, channel)+pathDutySuffix, duty)
+}
+
+func getDutyCycle(basePath, chip, channel string) (int64, error) {
+	return readIntAttribute(channelPath(basePath, chip, channel) + pathDutySuffix)
+}
+
+func setPolarity(basePath, chip, channel, polarity string) error {
+	err := sysfs.WriteAttribute(channelPath(basePath, chip, channel)+pathPolaritySuffix, polarity)
+	if err != nil {
+		return ErrUnknown
+	}
+	return nil
+}
+
+func getPolarity(basePath, chip, channel string) (string, error) {
+	value, err := sysfs.ReadAttribute(channelPath(basePath, chip, channel) + pathPolaritySuffix)
+	if err != nil {
+		return "", ErrUnknown
+	}
+	return value, nil
+}
+
+func setEnabled(basePath, chip, channel, enabled string) error {
+	err := sysfs.WriteAttribute(channelPath(basePath, chip, channel)+pathEnableSuffix, enabled)
+	if err != nil {
+		return ErrUnknown
+	}
+	return nil
+}
+
+func getEnabled(basePath, chip, channel string) (string, error) {
+	value, err := sysfs.ReadAttribute(channelPath(basePath, chip, channel) + pathEnableSuffix)
+	if err != nil {
+		return "", ErrUnknown
+	}
+	return value, nil
+}
+
+func listExported(basePath string) ([][2]string, error) {
+	chips, err := os.ReadDir(basePath)
+	if err != nil {
+		logrus.Traceln("listExported() failed to read PWM directory:", err)
+		return [][2]string{}, ErrUnknown
+	}
+
+	result := [][2]string{}
+	for _, chipEnt := range chips {
+		chip, ok := numericSuffix(chipEnt.Name(), strings.TrimPrefix(pathChipPrefix, "/"))
+		if !ok {
+			continue
+		}
+		channels, err := os.ReadDir(chipPath(basePath, chip))
+		if err != nil {
+			logrus.Traceln("listExported() failed to read chip directory:", err)
+			continue
+		}
+		for _, chanEnt := range channels {
+			channel, ok := numericSuffix(chanEnt.Name(), strings.TrimPrefix(pathChannelPrefix, "/"))
+			if !ok {
+				continue
+			}
+			result = append(result, [2]string{chip, channel})
+		}
+	}
+	return result, nil
+}
+
+func numericSuffix(name, prefix string) (string, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	id := strings.TrimPrefix(name, prefix)
+	_, err := strconv.Atoi(id)
+	if err != nil {
+		return "", false
+	}
+	return id, true
+}
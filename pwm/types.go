@@ -0,0 +1,70 @@
+package pwm
+
+import "time"
+
+// Polarity defines PWM output polarity
+// Possible values are Invalid, Normal and Inversed
+type Polarity int
+
+const (
+	// InvalidPolarity - default value, not set
+	InvalidPolarity Polarity = iota
+	// Normal - output is high for duty cycle part of period
+	Normal
+	// Inversed - output is low for duty cycle part of period
+	Inversed
+)
+
+const (
+	polarityNormal   = "normal"
+	polarityInversed = "inversed"
+)
+
+func PolarityToString(pl Polarity) string {
+	switch pl {
+	case Normal:
+		return polarityNormal
+	case Inversed:
+		return polarityInversed
+	default:
+		return "-"
+	}
+}
+
+func StringToPolarity(pl string) Polarity {
+	switch pl {
+	case polarityNormal:
+		return Normal
+	case polarityInversed:
+		return Inversed
+	default:
+		return InvalidPolarity
+	}
+}
+
+// Channel identifies single PWM output as a pair of chip and channel numbers
+type Channel struct {
+	Chip    int
+	Channel int
+}
+
+// State describes current configuration of exported PWM channel
+type State struct {
+	Channel
+	Period    time.Duration
+	DutyCycle time.Duration
+	Polarity  Polarity
+	Enabled   bool
+}
+
+// Controller is an interface of PWM controlling object
+type Controller interface {
+	ExportChannel(chip, channel int) error
+	UnexportChannel(chip, channel int) error
+	ListExportedChannels() ([]Channel, error)
+	GetState(chip, channel int) (State, error)
+	SetPeriod(chip, channel int, period time.Duration) error
+	SetDutyCycle(chip, channel int, duty time.Duration) error
+	SetPolarity(chip, channel int, polarity Polarity) error
+	SetEnabled(chip, channel int, enabled bool) error
+}
@@ -0,0 +1,95 @@
+package sensors
+
+import (
+	"strconv"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	thermalPath string
+	hwmonPath   string
+}
+
+func (c *controller) ListThermalZones() ([]ThermalZone, error) {
+	logrus.Traceln("sensors.controller.ListThermalZones()")
+	zones, err := listNumbered(c.thermalPath, pathZonePrefix)
+	if err != nil {
+		logrus.Errorln("Failed to read thermal zones directory:", err)
+		return []ThermalZone{}, err
+	}
+
+	result := make([]ThermalZone, 0, len(zones))
+	for _, zone := range zones {
+		tz, err := readZone(c.thermalPath, zone)
+		if err != nil {
+			// disabled zones return error on temperature reading
+			logrus.Warnf("Failed to read thermal zone '%d': %v\n", zone, err)
+			continue
+		}
+		result = append(result, tz)
+	}
+	return result, nil
+}
+
+func (c *controller) GetThermalZone(zone int) (ThermalZone, error) {
+	logrus.Traceln("sensors.controller.GetThermalZone()")
+	if zone < 0 || !sysfs.Exists(c.thermalPath+pathZonePrefix+strconv.Itoa(zone)) {
+		return ThermalZone{}, ErrInvalidZone
+	}
+	return readZone(c.thermalPath, zone)
+}
+
+func (c *controller) ListCoolingDevices() ([]CoolingDevice, error) {
+	logrus.Traceln("sensors.controller.ListCoolingDevices()")
+	devices, err := listNumbered(c.thermalPath, pathCoolingPrefix)
+	if err != nil {
+		logrus.Errorln("Failed to read thermal directory:", err)
+		return []CoolingDevice{}, err
+	}
+
+	result := make([]CoolingDevice, 0, len(devices))
+	for _, device := range devices {
+		cd, err := readCoolingDevice(c.thermalPath, device)
+		if err != nil {
+			logrus.Warnf("Failed to read cooling device '%d': %v\n", device, err)
+			continue
+		}
+		result = append(result, cd)
+	}
+	return result, nil
+}
+
+func (c *controller) ListChips() ([]Chip, error) {
+	logrus.Traceln("sensors.controller.ListChips()")
+	chips, err := listNumbered(c.hwmonPath, pathChipPrefix)
+	if err != nil {
+		logrus.Errorln("Failed to read hwmon directory:", err)
+		return []Chip{}, err
+	}
+
+	result := make([]Chip, 0, len(chips))
+	for _, chip := range chips {
+		ch, err := readChip(c.hwmonPath, chip)
+		if err != nil {
+			logrus.Warnf("Failed to read hwmon chip '%d': %v\n", chip, err)
+			continue
+		}
+		result = append(result, ch)
+	}
+	return result, nil
+}
+
+func (c *controller) GetChip(chip int) (Chip, error) {
+	logrus.Traceln("sensors.controller.GetChip()")
+	if chip < 0 || !sysfs.Exists(c.hwmonPath+pathChipPrefix+strconv.Itoa(chip)) {
+		return Chip{}, ErrInvalidChip
+	}
+	return readChip(c.hwmonPath, chip)
+}
+
+func CreateController(thermalPath, hwmonPath string) Controller {
+	logrus.Traceln("sensors.CreateController()")
+	return &controller{thermalPath: thermalPath, hwmonPath: hwmonPath}
+}
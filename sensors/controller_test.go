@@ -0,0 +1,104 @@
+package sensors
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThermal(t *testing.T) {
+	thermalPath := t.TempDir()
+	sysfstest.CreateDir(t, filepath.Join(thermalPath, "thermal_zone0"), map[string]string{
+		"type":              "cpu-thermal",
+		"temp":              "48686",
+		"trip_point_0_type": "critical",
+		"trip_point_0_temp": "90000",
+		"trip_point_1_type": "passive",
+		"trip_point_1_temp": "80000",
+		"trip_point_1_hyst": "2000",
+	})
+	// disabled zone without readable temperature
+	sysfstest.CreateDir(t, filepath.Join(thermalPath, "thermal_zone1"), map[string]string{"type": "gpu-thermal"})
+	sysfstest.CreateDir(t, filepath.Join(thermalPath, "cooling_device0"), map[string]string{
+		"type":      "cpufreq-cpu0",
+		"cur_state": "1",
+		"max_state": "3",
+	})
+	ctrl := CreateController(thermalPath, t.TempDir())
+
+	expected := ThermalZone{Number: 0, Type: "cpu-thermal", Temperature: 48.686, Trips: []TripPoint{
+		{Type: "critical", Temperature: 90},
+		{Type: "passive", Temperature: 80, Hysteresis: new(float64)},
+	}}
+	*expected.Trips[1].Hysteresis = 2
+
+	t.Run("list thermal zones", func(t *testing.T) {
+		zones, err := ctrl.ListThermalZones()
+		assert.NoError(t, err)
+		assert.Equal(t, []ThermalZone{expected}, zones)
+	})
+
+	t.Run("get thermal zone", func(t *testing.T) {
+		zone, err := ctrl.GetThermalZone(0)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, zone)
+
+		_, err = ctrl.GetThermalZone(1)
+		assert.Equal(t, ErrUnknown, err)
+		_, err = ctrl.GetThermalZone(2)
+		assert.Equal(t, ErrInvalidZone, err)
+	})
+
+	t.Run("list cooling devices", func(t *testing.T) {
+		devices, err := ctrl.ListCoolingDevices()
+		assert.NoError(t, err)
+		assert.Equal(t, []CoolingDevice{{Number: 0, Type: "cpufreq-cpu0", State: 1, MaxState: 3}}, devices)
+	})
+}
+
+func TestHwmon(t *testing.T) {
+	hwmonPath := t.TempDir()
+	sysfstest.CreateDir(t, filepath.Join(hwmonPath, "hwmon0"), map[string]string{
+		"name":            "cpu_thermal",
+		"temp1_input":     "48686",
+		"temp1_crit":      "90000",
+		"temp1_crit_hyst": "85000",
+		"temp1_max_alarm": "0",
+	})
+	sysfstest.CreateDir(t, filepath.Join(hwmonPath, "hwmon1", "device"), map[string]string{
+		"name":        "ina219",
+		"in0_input":   "5072",
+		"in0_label":   "bus",
+		"in0_max":     "5500",
+		"curr1_input": "750",
+		"fan1_input":  "1200",
+		"fan1_enable": "1",
+	})
+	ctrl := CreateController(t.TempDir(), hwmonPath)
+
+	cpu := Chip{Number: 0, Name: "cpu_thermal", Sensors: []Sensor{
+		{Name: "temp1", Type: "temp", Unit: "°C", Value: 48.686, Thresholds: map[string]float64{"crit": 90, "crit_hyst": 85}},
+	}}
+	power := Chip{Number: 1, Name: "ina219", Sensors: []Sensor{
+		{Name: "curr1", Type: "curr", Unit: "A", Value: 0.75, Thresholds: map[string]float64{}},
+		{Name: "fan1", Type: "fan", Unit: "RPM", Value: 1200, Thresholds: map[string]float64{}},
+		{Name: "in0", Label: "bus", Type: "in", Unit: "V", Value: 5.072, Thresholds: map[string]float64{"max": 5.5}},
+	}}
+
+	t.Run("list chips", func(t *testing.T) {
+		chips, err := ctrl.ListChips()
+		assert.NoError(t, err)
+		assert.Equal(t, []Chip{cpu, power}, chips)
+	})
+
+	t.Run("get chip", func(t *testing.T) {
+		chip, err := ctrl.GetChip(1)
+		assert.NoError(t, err)
+		assert.Equal(t, power, chip)
+
+		_, err = ctrl.GetChip(2)
+		assert.Equal(t, ErrInvalidChip, err)
+	})
+}
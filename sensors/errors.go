@@ -0,0 +1,9 @@
+package sensors
+
+import "errors"
+
+var (
+	ErrInvalidZone = errors.New("invalid thermal zone")
+	ErrInvalidChip = errors.New("invalid hwmon chip")
+	ErrUnknown     = errors.New("unknown error")
+)
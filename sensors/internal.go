@@ -0,0 +1,195 @@
+package sensors
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/markamdev/repico/sysfs"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathZonePrefix      = "/thermal_zone"
+	pathCoolingPrefix   = "/cooling_device"
+	pathChipPrefix      = "/hwmon"
+	pathTypeSuffix      = "/type"
+	pathTempSuffix      = "/temp"
+	pathCurStateSuffix  = "/cur_state"
+	pathMaxStateSuffix  = "/max_state"
+	pathNameSuffix      = "/name"
+	pathDeviceSuffix    = "/device"
+	pathTripPointPrefix = "/trip_point_"
+
+	// thermal framework reports temperatures in millidegrees
+	thermalDivider = 1000
+
+	attrInput = "input"
+	attrLabel = "label"
+)
+
+// sensorAttribute matches hwmon attribute name, ex. "temp1_crit_hyst"
+var sensorAttribute = regexp.MustCompile(`^([a-z]+)([0-9]+)_([a-z_]+)$`)
+
+// skippedAttributes are not numeric values or are not thresholds
+var skippedAttributes = map[string]bool{"label": true, "type": true, "enable": true, "alarm": true, "fault": true, "beep": true}
+
+func readNumber(path string) (float64, error) {
+	value, err := sysfs.ReadAttribute(path)
+	if err != nil {
+		return 0, ErrUnknown
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		logrus.Traceln("readNumber() invalid value:", err)
+		return 0, ErrUnknown
+	}
+	return result, nil
+}
+
+// listNumbered returns sorted numbers of directory entries having given prefix
+func listNumbered(basePath, prefix string) ([]int, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		logrus.Traceln("listNumbered() reading directory failed:", err)
+		return nil, ErrUnknown
+	}
+	result := []int{}
+	for _, ent := range entries {
+		if !strings.HasPrefix(ent.Name(), prefix[1:]) {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimPrefix(ent.Name(), prefix[1:]))
+		if err == nil {
+			result = append(result, number)
+		}
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+func readZone(basePath string, zone int) (ThermalZone, error) {
+	zonePath := basePath + pathZonePrefix + strconv.Itoa(zone)
+	zoneType, err := sysfs.ReadAttribute(zonePath + pathTypeSuffix)
+	if err != nil {
+		return ThermalZone{}, ErrUnknown
+	}
+	temperature, err := readNumber(zonePath + pathTempSuffix)
+	if err != nil {
+		return ThermalZone{}, err
+	}
+	result := ThermalZone{Number: zone, Type: zoneType, Temperature: temperature / thermalDivider, Trips: []TripPoint{}}
+
+	for trip := 0; ; trip++ {
+		tripPath := zonePath + pathTripPointPrefix + strconv.Itoa(trip)
+		tripType, err := sysfs.ReadAttribute(tripPath + "_type")
+		if err != nil {
+			break
+		}
+		tripTemp, err := readNumber(tripPath + "_temp")
+		if err != nil {
+			return ThermalZone{}, err
+		}
+		tp := TripPoint{Type: tripType, Temperature: tripTemp / thermalDivider}
+		if hyst, err := readNumber(tripPath + "_hyst"); err == nil {
+			hyst /= thermalDivider
+			tp.Hysteresis = &hyst
+		}
+		result.Trips = append(result.Trips, tp)
+	}
+	return result, nil
+}
+
+func readCoolingDevice(basePath string, device int) (CoolingDevice, error) {
+	devPath := basePath + pathCoolingPrefix + strconv.Itoa(device)
+	devType, err := sysfs.ReadAttribute(devPath + pathTypeSuffix)
+	if err != nil {
+		return CoolingDevice{}, ErrUnknown
+	}
+	state, err := readNumber(devPath + pathCurStateSuffix)
+	if err != nil {
+		return CoolingDevice{}, err
+	}
+	maxState, err := readNumber(devPath + pathMaxStateSuffix)
+	if err != nil {
+		return CoolingDevice{}, err
+	}
+	return CoolingDevice{Number: device, Type: devType, State: int(state), MaxState: int(maxState)}, nil
+}
+
+func readChip(basePath string, chip int) (Chip, error) {
+	chipPath := basePath + pathChipPrefix + strconv.Itoa(chip)
+	name, err := sysfs.ReadAttribute(chipPath + pathNameSuffix)
+	if err != nil {
+		// older drivers keep all attributes in device directory
+		chipPath += pathDeviceSuffix
+		name, err = sysfs.ReadAttribute(chipPath + pathNameSuffix)
+		if err != nil {
+			return Chip{}, ErrUnknown
+		}
+	}
+	sensors, err := readSensors(chipPath)
+	if err != nil {
+		return Chip{}, err
+	}
+	return Chip{Number: chip, Name: name, Sensors: sensors}, nil
+}
+
+// readSensors returns sensors having _input attribute with all their numeric attributes
+func readSensors(chipPath string) ([]Sensor, error) {
+	entries, err := os.ReadDir(chipPath)
+	if err != nil {
+		logrus.Traceln("readSensors() reading directory failed:", err)
+		return nil, ErrUnknown
+	}
+
+	found := map[string]*Sensor{}
+	names := []string{}
+	for _, ent := range entries {
+		match := sensorAttribute.FindStringSubmatch(ent.Name())
+		if match == nil || match[3] != attrInput {
+			continue
+		}
+		st, known := sensorTypes[match[1]]
+		if !known {
+			continue
+		}
+		name := match[1] + match[2]
+		value, err := readNumber(chipPath + "/" + ent.Name())
+		if err != nil {
+			logrus.Warnf("Failed to read hwmon sensor '%s': %v\n", chipPath+"/"+name, err)
+			continue
+		}
+		label, _ := sysfs.ReadAttribute(chipPath + "/" + name + "_" + attrLabel)
+		found[name] = &Sensor{Name: name, Label: label, Type: match[1], Unit: st.unit, Value: value / st.divider,
+			Thresholds: map[string]float64{}}
+		names = append(names, name)
+	}
+
+	for _, ent := range entries {
+		match := sensorAttribute.FindStringSubmatch(ent.Name())
+		if match == nil || match[3] == attrInput || skippedAttributes[match[3]] || strings.HasSuffix(match[3], "_alarm") {
+			continue
+		}
+		sensor, exists := found[match[1]+match[2]]
+		if !exists {
+			continue
+		}
+		value, err := readNumber(chipPath + "/" + ent.Name())
+		if err != nil {
+			continue
+		}
+		sensor.Thresholds[match[3]] = value / sensorTypes[sensor.Type].divider
+	}
+
+	sort.Strings(names)
+	result := make([]Sensor, 0, len(names))
+	for _, name := range names {
+		result = append(result, *found[name])
+	}
+	return result, nil
+}
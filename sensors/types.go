@@ -0,0 +1,70 @@
+package sensors
+
+// TripPoint is a temperature (in Celsius degrees) at which kernel takes action defined by type
+// ("active", "passive", "hot" or "critical")
+type TripPoint struct {
+	Type        string
+	Temperature float64
+	Hysteresis  *float64
+}
+
+// ThermalZone describes temperature sensor handled by kernel thermal framework
+type ThermalZone struct {
+	Number      int
+	Type        string
+	Temperature float64
+	Trips       []TripPoint
+}
+
+// CoolingDevice describes device used to lower temperature, ex. fan or CPU frequency limit
+// (state greater than 0 means throttling for "cpufreq" devices)
+type CoolingDevice struct {
+	Number   int
+	Type     string
+	State    int
+	MaxState int
+}
+
+// Sensor describes single hwmon channel (ex. "temp1", "in0", "fan1") with values converted to Unit
+type Sensor struct {
+	Name  string
+	Label string
+	Type  string
+	Unit  string
+	Value float64
+	// Thresholds contain limits and extra values of sensor (ex. "max", "crit", "crit_hyst")
+	Thresholds map[string]float64
+}
+
+// Chip describes hwmon device with all its sensors
+type Chip struct {
+	Number  int
+	Name    string
+	Sensors []Sensor
+}
+
+// Controller is an interface of object giving read-only access to system health sensors
+type Controller interface {
+	ListThermalZones() ([]ThermalZone, error)
+	GetThermalZone(zone int) (ThermalZone, error)
+	ListCoolingDevices() ([]CoolingDevice, error)
+	ListChips() ([]Chip, error)
+	GetChip(chip int) (Chip, error)
+}
+
+// sensorType describes unit of sysfs values of hwmon sensor type and divider converting them to Unit
+type sensorType struct {
+	unit    string
+	divider float64
+}
+
+// sensorTypes lists hwmon sensor types (see sysfs-interface document of hwmon subsystem)
+var sensorTypes = map[string]sensorType{
+	"temp":     {"°C", 1000},
+	"in":       {"V", 1000},
+	"curr":     {"A", 1000},
+	"power":    {"W", 1000000},
+	"energy":   {"J", 1000000},
+	"fan":      {"RPM", 1},
+	"humidity": {"%", 1000},
+}
@@ -0,0 +1,181 @@
+package serial
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type controller struct {
+	devPath string
+
+	mtx   sync.Mutex
+	ports map[string]*port
+}
+
+// ListPorts returns names of devices being serial ports (ex. ttyAMA0, ttyUSB0)
+func (c *controller) ListPorts() ([]string, error) {
+	logrus.Traceln("serial.controller.ListPorts()")
+	entries, err := os.ReadDir(c.devPath)
+	if err != nil {
+		logrus.Errorln("Failed to read devices directory:", err)
+		return []string{}, ErrUnknown
+	}
+
+	result := []string{}
+	for _, ent := range entries {
+		if isPortName(ent.Name()) {
+			result = append(result, ent.Name())
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (c *controller) OpenPort(name string, cfg Config) error {
+	logrus.Traceln("serial.controller.OpenPort()")
+	// only serial ports can be opened, other devices (ex. watchdog) could be harmed by opening them
+	if strings.Contains(name, "/") || !isPortName(name) {
+		return ErrInvalidPort
+	}
+	if !validConfig(cfg) {
+		return ErrInvalidConfig
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, exists := c.ports[name]; exists {
+		return ErrAlreadyOpened
+	}
+
+	file, err := openPort(c.devPath + "/" + name)
+	if os.IsNotExist(err) {
+		return ErrInvalidPort
+	}
+	if err != nil {
+		return ErrUnknown
+	}
+	err = setTermios(file, cfg)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	c.ports[name] = newPort(name, file, cfg)
+	return nil
+}
+
+func (c *controller) ClosePort(name string) error {
+	logrus.Traceln("serial.controller.ClosePort()")
+	c.mtx.Lock()
+	p, exists := c.ports[name]
+	delete(c.ports, name)
+	c.mtx.Unlock()
+	if !exists {
+		return ErrNotOpened
+	}
+
+	err := p.close()
+	if err != nil {
+		logrus.Warnf("Closing port '%s' failed: %v\n", name, err)
+	}
+	return nil
+}
+
+func (c *controller) ListOpenedPorts() (map[string]Config, error) {
+	logrus.Traceln("serial.controller.ListOpenedPorts()")
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	result := map[string]Config{}
+	for name, p := range c.ports {
+		p.mtx.Lock()
+		result[name] = p.cfg
+		p.mtx.Unlock()
+	}
+	return result, nil
+}
+
+func (c *controller) Configure(name string, cfg Config) error {
+	logrus.Traceln("serial.controller.Configure()")
+	if !validConfig(cfg) {
+		return ErrInvalidConfig
+	}
+	p, err := c.port(name)
+	if err != nil {
+		return err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	err = setTermios(p.file, cfg)
+	if err != nil {
+		return err
+	}
+	p.cfg = cfg
+	return nil
+}
+
+func (c *controller) Write(name string, data []byte) error {
+	logrus.Traceln("serial.controller.Write()")
+	if len(data) == 0 {
+		return ErrInvalidValue
+	}
+	p, err := c.port(name)
+	if err != nil {
+		return err
+	}
+	return p.write(data)
+}
+
+func (c *controller) Request(name string, data []byte, resp Response) ([]byte, error) {
+	logrus.Traceln("serial.controller.Request()")
+	if len(data) == 0 || resp.Timeout < 0 || resp.MaxLength < 0 || resp.MaxLength > MaxLength {
+		return nil, ErrInvalidValue
+	}
+	if resp.Timeout == 0 {
+		resp.Timeout = DefaultTimeout
+	}
+	if resp.MaxLength == 0 {
+		resp.MaxLength = MaxLength
+	}
+	p, err := c.port(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.request(data, resp)
+}
+
+func (c *controller) Subscribe(name string) (<-chan []byte, error) {
+	logrus.Traceln("serial.controller.Subscribe()")
+	p, err := c.port(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.subscribe()
+}
+
+func (c *controller) Unsubscribe(name string, data <-chan []byte) {
+	logrus.Traceln("serial.controller.Unsubscribe()")
+	p, err := c.port(name)
+	if err != nil {
+		return
+	}
+	p.unsubscribe(data)
+}
+
+func (c *controller) port(name string) (*port, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	p, exists := c.ports[name]
+	if !exists {
+		return nil, ErrNotOpened
+	}
+	return p, nil
+}
+
+func CreateController(devPath string) Controller {
+	logrus.Traceln("serial.CreateController()")
+	return &controller{devPath: devPath, ports: map[string]*port{}}
+}
@@ -0,0 +1,143 @@
+package serial
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openPty returns master side of pseudoterminal and directory with its slave linked as ttyUSB0
+func openPty(t *testing.T) (*os.File, string, string) {
+	master, name, err := OpenPty()
+	if err != nil {
+		t.Skip("pseudoterminals not available:", err)
+	}
+	t.Cleanup(func() { master.Close() })
+	devPath := t.TempDir()
+	require.NoError(t, os.Symlink(filepath.Join(PtyPath, name), filepath.Join(devPath, "ttyUSB0")))
+	return master, devPath, "ttyUSB0"
+}
+
+// respond reads single request on master side (emulating connected device) and writes response
+func respond(t *testing.T, master *os.File, request, response string) {
+	go func() {
+		buffer := make([]byte, len(request))
+		_, err := master.Read(buffer)
+		assert.NoError(t, err)
+		assert.Equal(t, request, string(buffer))
+		master.Write([]byte(response))
+	}()
+}
+
+func TestListPorts(t *testing.T) {
+	devPath := t.TempDir()
+	for _, name := range []string{"ttyUSB1", "ttyAMA0", "tty0", "null", "ttyACM0"} {
+		require.NoError(t, os.WriteFile(filepath.Join(devPath, name), nil, 0644))
+	}
+	ctrl := CreateController(devPath)
+
+	ports, err := ctrl.ListPorts()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ttyACM0", "ttyAMA0", "ttyUSB1"}, ports)
+}
+
+func TestController(t *testing.T) {
+	master, devPath, name := openPty(t)
+	ctrl := CreateController(devPath)
+
+	t.Run("open port - invalid", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidConfig, ctrl.OpenPort(name, Config{Baud: 1234, DataBits: 8, Parity: NoParity, StopBits: 1}))
+		assert.Equal(t, ErrInvalidConfig, ctrl.OpenPort(name, Config{Baud: 9600, DataBits: 9, Parity: NoParity, StopBits: 1}))
+		assert.Equal(t, ErrInvalidConfig, ctrl.OpenPort(name, Config{Baud: 9600, DataBits: 8, StopBits: 1}))
+		assert.Equal(t, ErrInvalidPort, ctrl.OpenPort("../ptmx", DefaultConfig))
+		assert.Equal(t, ErrInvalidPort, ctrl.OpenPort("ttyUSB9", DefaultConfig))
+		require.NoError(t, os.WriteFile(filepath.Join(devPath, "watchdog"), nil, 0644))
+		assert.Equal(t, ErrInvalidPort, ctrl.OpenPort("watchdog", DefaultConfig), "non serial devices should not be opened")
+		assert.Equal(t, ErrNotOpened, ctrl.Write(name, []byte{1}))
+	})
+
+	require.NoError(t, ctrl.OpenPort(name, DefaultConfig))
+
+	t.Run("open port - already opened", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyOpened, ctrl.OpenPort(name, DefaultConfig))
+	})
+
+	t.Run("configure", func(t *testing.T) {
+		cfg := Config{Baud: 9600, DataBits: 7, Parity: EvenParity, StopBits: 2}
+		require.NoError(t, ctrl.Configure(name, cfg))
+		ports, err := ctrl.ListOpenedPorts()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]Config{name: cfg}, ports)
+
+		assert.Equal(t, ErrInvalidConfig, ctrl.Configure(name, Config{Baud: 9600, DataBits: 8, Parity: NoParity, StopBits: 3}))
+		require.NoError(t, ctrl.Configure(name, DefaultConfig))
+	})
+
+	t.Run("write", func(t *testing.T) {
+		require.NoError(t, ctrl.Write(name, []byte("AT\r")))
+		buffer := make([]byte, 3)
+		_, err := master.Read(buffer)
+		assert.NoError(t, err)
+		assert.Equal(t, "AT\r", string(buffer))
+	})
+
+	t.Run("request with delimiter", func(t *testing.T) {
+		respond(t, master, "AT+GMR\r", "1.7.4\r\nOK\r\n")
+		data, err := ctrl.Request(name, []byte("AT+GMR\r"), Response{Delimiter: []byte("OK\r\n")})
+		assert.NoError(t, err)
+		assert.Equal(t, "1.7.4\r\nOK\r\n", string(data))
+	})
+
+	t.Run("request with max length", func(t *testing.T) {
+		respond(t, master, "?", "0123456789")
+		data, err := ctrl.Request(name, []byte("?"), Response{MaxLength: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, "0123", string(data))
+	})
+
+	t.Run("request until timeout", func(t *testing.T) {
+		respond(t, master, "?", "42")
+		data, err := ctrl.Request(name, []byte("?"), Response{Timeout: 100 * time.Millisecond})
+		assert.NoError(t, err)
+		assert.Equal(t, "42", string(data))
+	})
+
+	t.Run("request - timeout", func(t *testing.T) {
+		respond(t, master, "?", "ERR")
+		_, err := ctrl.Request(name, []byte("?"), Response{Timeout: 50 * time.Millisecond, Delimiter: []byte("OK")})
+		assert.Equal(t, ErrTimeout, err)
+
+		_, err = ctrl.Request(name, nil, Response{})
+		assert.Equal(t, ErrInvalidValue, err)
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		data, err := ctrl.Subscribe(name)
+		require.NoError(t, err)
+		master.Write([]byte("$GPGGA"))
+
+		received := []byte{}
+		for len(received) < 6 {
+			select {
+			case chunk := <-data:
+				received = append(received, chunk...)
+			case <-time.After(time.Second):
+				t.Fatal("data not received")
+			}
+		}
+		assert.Equal(t, "$GPGGA", string(received))
+
+		require.NoError(t, ctrl.ClosePort(name))
+		_, ok := <-data
+		assert.False(t, ok)
+		ctrl.Unsubscribe(name, data)
+	})
+
+	t.Run("close port - not opened", func(t *testing.T) {
+		assert.Equal(t, ErrNotOpened, ctrl.ClosePort(name))
+	})
+}
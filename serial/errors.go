@@ -0,0 +1,13 @@
+package serial
+
+import "errors"
+
+var (
+	ErrInvalidPort   = errors.New("invalid port")
+	ErrInvalidConfig = errors.New("invalid configuration")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrAlreadyOpened = errors.New("already opened")
+	ErrNotOpened     = errors.New("not opened")
+	ErrTimeout       = errors.New("timeout")
+	ErrUnknown       = errors.New("unknown error")
+)
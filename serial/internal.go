@@ -0,0 +1,125 @@
+package serial
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+// cbaud masks baud rate bits in c_cflag (CBAUD | CBAUDEX), not defined by syscall package
+const cbaud = 0x100f
+
+// portPrefixes are names of devices listed as serial ports
+var portPrefixes = []string{"ttyAMA", "ttyS", "ttyUSB", "ttyACM", "serial"}
+
+func isPortName(name string) bool {
+	for _, prefix := range portPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var bauds = map[int]uint32{
+	1200:    syscall.B1200,
+	2400:    syscall.B2400,
+	4800:    syscall.B4800,
+	9600:    syscall.B9600,
+	19200:   syscall.B19200,
+	38400:   syscall.B38400,
+	57600:   syscall.B57600,
+	115200:  syscall.B115200,
+	230400:  syscall.B230400,
+	460800:  syscall.B460800,
+	500000:  syscall.B500000,
+	576000:  syscall.B576000,
+	921600:  syscall.B921600,
+	1000000: syscall.B1000000,
+	1500000: syscall.B1500000,
+	2000000: syscall.B2000000,
+	3000000: syscall.B3000000,
+	4000000: syscall.B4000000,
+}
+
+var dataBits = map[int]uint32{5: syscall.CS5, 6: syscall.CS6, 7: syscall.CS7, 8: syscall.CS8}
+
+func validConfig(cfg Config) bool {
+	_, baudOk := bauds[cfg.Baud]
+	_, bitsOk := dataBits[cfg.DataBits]
+	return baudOk && bitsOk && cfg.Parity != InvalidParity && cfg.Parity <= OddParity &&
+		(cfg.StopBits == 1 || cfg.StopBits == 2)
+}
+
+// openPort opens terminal device without making it controlling terminal, file is non-blocking
+// (handled by runtime poller) so it can be closed while being read
+func openPort(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		logrus.Traceln("openPort() opening failed:", err)
+		return nil, err
+	}
+	return file, nil
+}
+
+// setTermios configures port in raw mode with given line settings
+func setTermios(file *os.File, cfg Config) error {
+	var tio syscall.Termios
+	err := ioctl(file, syscall.TCGETS, unsafe.Pointer(&tio))
+	if err != nil {
+		logrus.Traceln("setTermios() cannot get terminal attributes:", err)
+		return ErrUnknown
+	}
+
+	tio.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR |
+		syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.IXOFF | syscall.INPCK
+	tio.Oflag &^= syscall.OPOST
+	tio.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	tio.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.PARODD | syscall.CSTOPB | cbaud
+	tio.Cflag |= syscall.CREAD | syscall.CLOCAL | dataBits[cfg.DataBits] | bauds[cfg.Baud]
+	switch cfg.Parity {
+	case EvenParity:
+		tio.Cflag |= syscall.PARENB
+		tio.Iflag |= syscall.INPCK
+	case OddParity:
+		tio.Cflag |= syscall.PARENB | syscall.PARODD
+		tio.Iflag |= syscall.INPCK
+	}
+	if cfg.StopBits == 2 {
+		tio.Cflag |= syscall.CSTOPB
+	}
+	tio.Ispeed = bauds[cfg.Baud]
+	tio.Ospeed = bauds[cfg.Baud]
+	tio.Cc[syscall.VMIN] = 1
+	tio.Cc[syscall.VTIME] = 0
+
+	err = ioctl(file, syscall.TCSETS, unsafe.Pointer(&tio))
+	if err != nil {
+		logrus.Traceln("setTermios() cannot set terminal attributes:", err)
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+func ioctl(file *os.File, request uintptr, arg unsafe.Pointer) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
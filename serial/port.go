@@ -0,0 +1,149 @@
+package serial
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	readBufferSize = 256
+	dataQueueSize  = 64
+)
+
+// port keeps opened serial device with goroutine reading it and passing data to subscribers
+type port struct {
+	name string
+	file *os.File
+
+	// reqMtx serializes requests so responses are not mixed
+	reqMtx sync.Mutex
+
+	mtx         sync.Mutex
+	cfg         Config
+	closed      bool
+	subscribers []chan []byte
+	done        chan struct{}
+}
+
+func newPort(name string, file *os.File, cfg Config) *port {
+	p := &port{name: name, file: file, cfg: cfg, done: make(chan struct{})}
+	go p.read()
+	return p
+}
+
+func (p *port) read() {
+	defer close(p.done)
+	buffer := make([]byte, readBufferSize)
+	for {
+		n, err := p.file.Read(buffer)
+		if n > 0 {
+			chunk := append([]byte(nil), buffer[:n]...)
+			p.mtx.Lock()
+			for _, sub := range p.subscribers {
+				select {
+				case sub <- chunk:
+				default:
+					logrus.Warnf("Data queue full, dropping %d bytes read from port '%s'\n", n, p.name)
+				}
+			}
+			p.mtx.Unlock()
+		}
+		if err != nil {
+			// port closed or device removed (ex. USB adapter unplugged)
+			logrus.Debugf("Reading port '%s' finished: %v\n", p.name, err)
+			break
+		}
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.closed = true
+	for _, sub := range p.subscribers {
+		close(sub)
+	}
+	p.subscribers = nil
+}
+
+func (p *port) subscribe() (chan []byte, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.closed {
+		return nil, ErrNotOpened
+	}
+	data := make(chan []byte, dataQueueSize)
+	p.subscribers = append(p.subscribers, data)
+	return data, nil
+}
+
+func (p *port) unsubscribe(data <-chan []byte) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for i, sub := range p.subscribers {
+		if (<-chan []byte)(sub) == data {
+			close(sub)
+			p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *port) write(data []byte) error {
+	_, err := p.file.Write(data)
+	if err != nil {
+		logrus.Traceln("port.write() writing failed:", err)
+		return ErrUnknown
+	}
+	return nil
+}
+
+func (p *port) request(data []byte, resp Response) ([]byte, error) {
+	p.reqMtx.Lock()
+	defer p.reqMtx.Unlock()
+
+	received, err := p.subscribe()
+	if err != nil {
+		return nil, err
+	}
+	defer p.unsubscribe(received)
+	err = p.write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(resp.Timeout)
+	defer timer.Stop()
+	result := []byte{}
+	for {
+		select {
+		case chunk, ok := <-received:
+			if !ok {
+				return nil, ErrNotOpened
+			}
+			result = append(result, chunk...)
+			if len(resp.Delimiter) > 0 {
+				if pos := bytes.Index(result, resp.Delimiter); pos >= 0 {
+					return result[:pos+len(resp.Delimiter)], nil
+				}
+			}
+			if len(result) >= resp.MaxLength {
+				return result[:resp.MaxLength], nil
+			}
+		case <-timer.C:
+			if len(resp.Delimiter) > 0 || len(result) == 0 {
+				return nil, ErrTimeout
+			}
+			return result, nil
+		}
+	}
+}
+
+// close stops reading goroutine, all subscriptions are closed by it
+func (p *port) close() error {
+	err := p.file.Close()
+	<-p.done
+	return err
+}
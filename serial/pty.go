@@ -0,0 +1,35 @@
+package serial
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// PtyPath is a directory containing slave sides of pseudoterminals
+const PtyPath = "/dev/pts"
+
+// OpenPty creates pseudoterminal pair and returns its master side with name of slave in PtyPath.
+// Slave linked under serial port name (ex. ttyUSB0) can be opened as serial port with master side
+// emulating connected device (for tests and development without hardware)
+func OpenPty() (*os.File, string, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	unlock := 0
+	err = ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock))
+	if err != nil {
+		master.Close()
+		return nil, "", err
+	}
+	var number uint32
+	err = ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&number))
+	if err != nil {
+		master.Close()
+		return nil, "", err
+	}
+	return master, strconv.Itoa(int(number)), nil
+}
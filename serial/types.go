@@ -0,0 +1,93 @@
+package serial
+
+import "time"
+
+// Parity defines parity bit mode
+// Possible values are InvalidParity, NoParity, EvenParity and OddParity
+type Parity int
+
+const (
+	// InvalidParity - default value, not set
+	InvalidParity Parity = iota
+	// NoParity - parity bit is not sent
+	NoParity
+	// EvenParity - parity bit makes number of ones even
+	EvenParity
+	// OddParity - parity bit makes number of ones odd
+	OddParity
+)
+
+const (
+	parityNone = "none"
+	parityEven = "even"
+	parityOdd  = "odd"
+)
+
+func ParityToString(pr Parity) string {
+	switch pr {
+	case NoParity:
+		return parityNone
+	case EvenParity:
+		return parityEven
+	case OddParity:
+		return parityOdd
+	default:
+		return "-"
+	}
+}
+
+func StringToParity(pr string) Parity {
+	switch pr {
+	case parityNone:
+		return NoParity
+	case parityEven:
+		return EvenParity
+	case parityOdd:
+		return OddParity
+	default:
+		return InvalidParity
+	}
+}
+
+const (
+	// MaxLength - maximum number of bytes returned by single request
+	MaxLength = 64 * 1024
+	// DefaultTimeout - time of waiting for response if not set in request
+	DefaultTimeout = time.Second
+)
+
+// Config describes serial line settings
+type Config struct {
+	Baud     int
+	DataBits int
+	Parity   Parity
+	StopBits int
+}
+
+// DefaultConfig is 115200 baud, 8 data bits, no parity and 1 stop bit
+var DefaultConfig = Config{Baud: 115200, DataBits: 8, Parity: NoParity, StopBits: 1}
+
+// Response defines when response to request is complete: when Delimiter is received, MaxLength bytes
+// are received or Timeout passes (without Delimiter set data received until timeout is a response)
+type Response struct {
+	Timeout   time.Duration
+	Delimiter []byte
+	MaxLength int
+}
+
+// Controller is an interface of object giving access to serial ports (/dev/ttyAMA0, /dev/ttyUSB0, ...)
+type Controller interface {
+	// ListPorts returns names of serial devices
+	ListPorts() ([]string, error)
+	OpenPort(name string, cfg Config) error
+	ClosePort(name string) error
+	// ListOpenedPorts returns configuration of all opened ports
+	ListOpenedPorts() (map[string]Config, error)
+	Configure(name string, cfg Config) error
+	Write(name string, data []byte) error
+	// Request writes data and waits for response, data received by port before request is not returned
+	Request(name string, data []byte, resp Response) ([]byte, error)
+	// Subscribe returns channel receiving all data read from port, channel is closed when port is closed
+	Subscribe(name string) (<-chan []byte, error)
+	Unsubscribe(name string, data <-chan []byte)
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"strconv"
 
@@ -12,12 +13,18 @@ import (
 type muxWrapper struct {
 	router *mux.Router
 	server http.Server
+	cancel context.CancelFunc
 }
 
 func NewHandler() Handler {
 	result := muxWrapper{router: mux.NewRouter(), server: http.Server{}}
 	result.router.StrictSlash(true)
 	result.server.Handler = result.router
+
+	// long living requests (ex. event streams) are informed about shutdown with context cancellation
+	baseCtx, cancel := context.WithCancel(context.Background())
+	result.cancel = cancel
+	result.server.BaseContext = func(net.Listener) context.Context { return baseCtx }
 	return &result
 }
 
@@ -34,5 +41,6 @@ func (mw *muxWrapper) ServeHTTP(port int) error {
 }
 
 func (mw *muxWrapper) Shutdown(ctx context.Context) {
+	mw.cancel()
 	mw.server.Shutdown(ctx)
 }
@@ -0,0 +1,12 @@
+package servo
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid servo configuration")
+	ErrInvalidName   = errors.New("invalid name")
+	ErrInvalidAngle  = errors.New("invalid angle")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound      = errors.New("not found")
+	ErrUnknown       = errors.New("unknown error")
+)
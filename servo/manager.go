@@ -0,0 +1,80 @@
+package servo
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/markamdev/repico/pwm"
+	"github.com/sirupsen/logrus"
+)
+
+type manager struct {
+	ctrl   pwm.Controller
+	mtx    sync.Mutex
+	servos map[string]Servo
+}
+
+func (m *manager) Add(name string, cfg Config) error {
+	logrus.Traceln("servo.manager.Add()")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, exists := m.servos[name]; exists {
+		return ErrAlreadyExists
+	}
+	for _, srv := range m.servos {
+		used := srv.Status().Config
+		if used.Chip == cfg.Chip && used.Channel == cfg.Channel {
+			return ErrAlreadyExists
+		}
+	}
+
+	srv, err := CreateServo(m.ctrl, name, cfg)
+	if err != nil {
+		return err
+	}
+	m.servos[name] = srv
+	return nil
+}
+
+func (m *manager) Remove(name string) error {
+	logrus.Traceln("servo.manager.Remove()")
+	m.mtx.Lock()
+	srv, exists := m.servos[name]
+	delete(m.servos, name)
+	m.mtx.Unlock()
+
+	if !exists {
+		return ErrNotFound
+	}
+	return srv.Close()
+}
+
+func (m *manager) Get(name string) (Servo, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	srv, exists := m.servos[name]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return srv, nil
+}
+
+func (m *manager) List() []Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	result := make([]Status, 0, len(m.servos))
+	for _, srv := range m.servos {
+		result = append(result, srv.Status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func CreateManager(ctrl pwm.Controller) Manager {
+	logrus.Traceln("servo.CreateManager()")
+	return &manager{ctrl: ctrl, servos: map[string]Servo{}}
+}
@@ -0,0 +1,248 @@
+package servo
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/pwm"
+	"github.com/sirupsen/logrus"
+)
+
+type servo struct {
+	name     string
+	cfg      Config
+	ctrl     pwm.Controller
+	exported bool
+
+	mtx     sync.Mutex
+	angle   float64
+	target  float64
+	enabled bool
+	cancel  chan struct{}
+	done    chan struct{}
+}
+
+// SetAngle moves servo to angle, if servo is already moving its target is just changed
+// so concurrent calls never start more than one move
+func (s *servo) SetAngle(angle float64) error {
+	logrus.Traceln("servo.servo.SetAngle()")
+	if math.IsNaN(angle) || angle < 0 || angle > s.cfg.Travel {
+		return ErrInvalidAngle
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.cancel != nil {
+		s.target = angle
+		return nil
+	}
+
+	// first position is applied directly as current servo position is unknown
+	if s.cfg.Speed == 0 || !s.enabled {
+		err := s.apply(angle)
+		if err != nil {
+			return err
+		}
+		s.target = angle
+		return nil
+	}
+
+	s.target = angle
+	s.cancel = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.move(s.cancel, s.done)
+	return nil
+}
+
+// Stop interrupts move leaving servo at its current angle
+func (s *servo) Stop() {
+	s.mtx.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.target = s.angle
+	s.mtx.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	close(cancel)
+	<-done
+}
+
+func (s *servo) Status() Status {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return Status{
+		Name:   s.name,
+		Config: s.cfg,
+		Angle:  s.angle,
+		Target: s.target,
+		Moving: s.cancel != nil,
+	}
+}
+
+func (s *servo) Close() error {
+	logrus.Traceln("servo.servo.Close()")
+	s.Stop()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	err := s.ctrl.SetEnabled(s.cfg.Chip, s.cfg.Channel, false)
+	if err != nil {
+		logrus.Warnln("Failed to disable servo output:", err)
+	}
+	s.enabled = false
+	if s.exported {
+		return s.ctrl.UnexportChannel(s.cfg.Chip, s.cfg.Channel)
+	}
+	return err
+}
+
+// move changes angle by small steps (one per PWM period) until target is reached
+func (s *servo) move(cancel, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.cfg.Period)
+	defer ticker.Stop()
+	step := s.cfg.Speed * s.cfg.Period.Seconds()
+
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-ticker.C:
+		}
+
+		s.mtx.Lock()
+		if s.cancel != cancel {
+			// stopped, new move (if any) is done by other goroutine
+			s.mtx.Unlock()
+			return
+		}
+		next := s.target
+		if diff := s.target - s.angle; math.Abs(diff) > step {
+			next = s.angle + math.Copysign(step, diff)
+		}
+		err := s.apply(next)
+		finished := err != nil || next == s.target
+		if err != nil {
+			logrus.Errorln("Servo move interrupted:", err)
+			s.target = s.angle
+		}
+		if finished {
+			s.cancel, s.done = nil, nil
+		}
+		s.mtx.Unlock()
+
+		if finished {
+			return
+		}
+	}
+}
+
+// apply sets pulse width for given angle, must be called with mutex locked
+func (s *servo) apply(angle float64) error {
+	err := s.ctrl.SetDutyCycle(s.cfg.Chip, s.cfg.Channel, s.pulse(angle))
+	if err != nil {
+		return err
+	}
+	if !s.enabled {
+		err = s.ctrl.SetEnabled(s.cfg.Chip, s.cfg.Channel, true)
+		if err != nil {
+			return err
+		}
+		s.enabled = true
+	}
+	s.angle = angle
+	return nil
+}
+
+func (s *servo) pulse(angle float64) time.Duration {
+	span := float64(s.cfg.MaxPulse - s.cfg.MinPulse)
+	return s.cfg.MinPulse + time.Duration(math.Round(span*angle/s.cfg.Travel))
+}
+
+func applyDefaults(cfg Config) Config {
+	if cfg.Period == 0 {
+		cfg.Period = DefaultPeriod
+	}
+	if cfg.MinPulse == 0 {
+		cfg.MinPulse = DefaultMinPulse
+	}
+	if cfg.MaxPulse == 0 {
+		cfg.MaxPulse = DefaultMaxPulse
+	}
+	if cfg.Travel == 0 {
+		cfg.Travel = DefaultTravel
+	}
+	return cfg
+}
+
+func validateConfig(cfg Config) error {
+	if cfg.Chip < 0 || cfg.Channel < 0 || cfg.Period < 0 || cfg.Travel < 0 || cfg.Speed < 0 {
+		return ErrInvalidConfig
+	}
+	if cfg.MinPulse <= 0 || cfg.MaxPulse <= 0 || cfg.MaxPulse > cfg.Period {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// CreateServo prepares PWM channel and returns servo object using it
+// Output stays disabled until first angle is set
+func CreateServo(ctrl pwm.Controller, name string, cfg Config) (Servo, error) {
+	logrus.Traceln("servo.CreateServo()")
+	cfg = applyDefaults(cfg)
+	err := validateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &servo{name: name, cfg: cfg, ctrl: ctrl}
+
+	err = ctrl.ExportChannel(cfg.Chip, cfg.Channel)
+	switch err {
+	case nil:
+		result.exported = true
+	case pwm.ErrAlreadyExported:
+		logrus.Debugln("Servo uses already exported PWM channel")
+	default:
+		return nil, err
+	}
+
+	err = result.configureOutput()
+	if err != nil {
+		if result.exported {
+			ctrl.UnexportChannel(cfg.Chip, cfg.Channel)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *servo) configureOutput() error {
+	err := s.ctrl.SetEnabled(s.cfg.Chip, s.cfg.Channel, false)
+	if err != nil {
+		return err
+	}
+	state, err := s.ctrl.GetState(s.cfg.Chip, s.cfg.Channel)
+	if err != nil {
+		return err
+	}
+	if state.Period != s.cfg.Period {
+		// duty cycle has to be reduced first as it cannot be longer than period
+		err = s.ctrl.SetDutyCycle(s.cfg.Chip, s.cfg.Channel, 0)
+		if err != nil {
+			return err
+		}
+		err = s.ctrl.SetPeriod(s.cfg.Chip, s.cfg.Channel, s.cfg.Period)
+		if err != nil {
+			return err
+		}
+	}
+	if state.Polarity != pwm.Normal {
+		return s.ctrl.SetPolarity(s.cfg.Chip, s.cfg.Channel, pwm.Normal)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package servo
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/pwm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServo(t *testing.T) {
+	ctrl := &fakePWM{}
+	mgr := CreateManager(ctrl)
+
+	t.Run("add - invalid calibration", func(t *testing.T) {
+		err := mgr.Add("pan", Config{MinPulse: time.Millisecond, MaxPulse: 30 * time.Millisecond})
+		assert.Equal(t, ErrInvalidConfig, err)
+	})
+
+	t.Run("add - correct case", func(t *testing.T) {
+		require.NoError(t, mgr.Add("pan", Config{Chip: 0, Channel: 0, Travel: 90}))
+		assert.Equal(t, DefaultPeriod, ctrl.period)
+		assert.False(t, ctrl.enabled, "output should stay disabled until angle is set")
+	})
+
+	t.Run("add - duplicated name or channel", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, mgr.Add("pan", Config{Channel: 1}))
+		assert.Equal(t, ErrAlreadyExists, mgr.Add("tilt", Config{Channel: 0}))
+	})
+
+	srv, err := mgr.Get("pan")
+	require.NoError(t, err)
+
+	t.Run("set angle - out of range", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidAngle, srv.SetAngle(-1))
+		assert.Equal(t, ErrInvalidAngle, srv.SetAngle(91))
+	})
+
+	t.Run("set angle - calibrated pulse", func(t *testing.T) {
+		assert.NoError(t, srv.SetAngle(45))
+		assert.Equal(t, 1500*time.Microsecond, ctrl.duty)
+		assert.True(t, ctrl.enabled)
+		assert.Equal(t, 45.0, srv.Status().Angle)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.NoError(t, mgr.Remove("pan"))
+		assert.Equal(t, ErrNotFound, mgr.Remove("pan"))
+		assert.False(t, ctrl.exported)
+	})
+
+	t.Run("speed limited move", func(t *testing.T) {
+		require.NoError(t, mgr.Add("slow", Config{Period: 2 * time.Millisecond, Speed: 1000}))
+		slow, _ := mgr.Get("slow")
+		require.NoError(t, slow.SetAngle(0))
+		ctrl.mtx.Lock()
+		ctrl.dutyWrites = 0
+		ctrl.mtx.Unlock()
+		require.NoError(t, slow.SetAngle(20))
+
+		assert.True(t, slow.Status().Moving)
+		assert.Eventually(t, func() bool { return !slow.Status().Moving }, time.Second, time.Millisecond)
+		assert.Equal(t, 20.0, slow.Status().Angle)
+		assert.Equal(t, 10, ctrl.dutyWrites, "move should be split into steps of 2 degrees")
+	})
+
+	t.Run("concurrent set angle", func(t *testing.T) {
+		slow, _ := mgr.Get("slow")
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(angle float64) {
+				defer wg.Done()
+				assert.NoError(t, slow.SetAngle(angle))
+			}(float64(40 + i*10))
+		}
+		wg.Wait()
+		target := slow.Status().Target
+		assert.Eventually(t, func() bool { return !slow.Status().Moving }, time.Second, time.Millisecond)
+		assert.Equal(t, target, slow.Status().Angle)
+
+		ctrl.mtx.Lock()
+		writes := ctrl.dutyWrites
+		ctrl.mtx.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		ctrl.mtx.Lock()
+		defer ctrl.mtx.Unlock()
+		assert.Equal(t, writes, ctrl.dutyWrites, "no move should be left running")
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		slow, _ := mgr.Get("slow")
+		require.NoError(t, slow.SetAngle(0))
+		slow.Stop()
+		slow.Stop()
+		status := slow.Status()
+		assert.False(t, status.Moving)
+		assert.Equal(t, status.Angle, status.Target)
+	})
+}
+
+type fakePWM struct {
+	mtx        sync.Mutex
+	exported   bool
+	period     time.Duration
+	duty       time.Duration
+	enabled    bool
+	dutyWrites int
+}
+
+func (fp *fakePWM) ExportChannel(chip, channel int) error {
+	fp.mtx.Lock()
+	defer fp.mtx.Unlock()
+	fp.exported = true
+	return nil
+}
+
+func (fp *fakePWM) UnexportChannel(chip, channel int) error {
+	fp.mtx.Lock()
+	defer fp.mtx.Unlock()
+	fp.exported = false
+	return nil
+}
+
+func (fp *fakePWM) ListExportedChannels() ([]pwm.Channel, error) {
+	return []pwm.Channel{}, nil
+}
+
+func (fp *fakePWM) GetState(chip, channel int) (pwm.State, error) {
+	fp.mtx.Lock()
+	defer fp.mtx.Unlock()
+	return pwm.State{Period: fp.period, DutyCycle: fp.duty, Polarity: pwm.Normal, Enabled: fp.enabled}, nil
+}
+
+func (fp *fakePWM) SetPeriod(chip, channel int, period time.Duration) error {
+	fp.mtx.Lock()
+	defer fp.mtx.Unlock()
+	fp.period = period
+	return nil
+}
+
+func (fp *fakePWM) SetDutyCycle(chip, channel int, duty time.Duration) error {
+	fp.mtx.Lock()
+	defer fp.mtx.Unlock()
+	fp.duty = duty
+	fp.dutyWrites++
+	return nil
+}
+
+func (fp *fakePWM) SetPolarity(chip, channel int, polarity pwm.Polarity) error {
+	return nil
+}
+
+func (fp *fakePWM) SetEnabled(chip, channel int, enabled bool) error {
+	fp.mtx.Lock()
+	defer fp.mtx.Unlock()
+	fp.enabled = enabled
+	return nil
+}
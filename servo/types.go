@@ -0,0 +1,52 @@
+package servo
+
+import "time"
+
+const (
+	// DefaultPeriod - standard hobby servo frame length
+	DefaultPeriod = 20 * time.Millisecond
+	// DefaultMinPulse - pulse width for angle 0
+	DefaultMinPulse = time.Millisecond
+	// DefaultMaxPulse - pulse width for full travel angle
+	DefaultMaxPulse = 2 * time.Millisecond
+	// DefaultTravel - full servo travel in degrees
+	DefaultTravel = 180.0
+)
+
+// Config describes servo connection and calibration
+// Zero values of Period, MinPulse, MaxPulse and Travel are replaced with defaults
+// Speed is given in degrees per second, zero means moving without limit
+type Config struct {
+	Chip     int
+	Channel  int
+	Period   time.Duration
+	MinPulse time.Duration
+	MaxPulse time.Duration
+	Travel   float64
+	Speed    float64
+}
+
+// Status describes current servo state
+type Status struct {
+	Name   string
+	Config Config
+	Angle  float64
+	Target float64
+	Moving bool
+}
+
+// Servo is an interface of single servo controlling object
+type Servo interface {
+	SetAngle(angle float64) error
+	Stop()
+	Status() Status
+	Close() error
+}
+
+// Manager is an interface of object keeping named servos
+type Manager interface {
+	Add(name string, cfg Config) error
+	Remove(name string) error
+	Get(name string) (Servo, error)
+	List() []Status
+}
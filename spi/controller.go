@@ -0,0 +1,199 @@
+package spi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultConfig is applied to each device when it is opened
+var defaultConfig = bus.SPIConfig{Mode: bus.Mode0, BitsPerWord: 8, SpeedHz: DefaultSpeed}
+
+type device struct {
+	spi bus.SPI
+	cfg bus.SPIConfig
+}
+
+type controller struct {
+	devPath string
+
+	mtx     sync.Mutex
+	devices map[Device]*device
+}
+
+// ListDevices returns devices available as /dev/spidevB.C and added ones
+func (c *controller) ListDevices() ([]Device, error) {
+	logrus.Traceln("spi.controller.ListDevices()")
+	entries, err := os.ReadDir(c.devPath)
+	if err != nil {
+		logrus.Errorln("Failed to read devices directory:", err)
+		return []Device{}, ErrUnknown
+	}
+
+	found := map[Device]bool{}
+	for _, ent := range entries {
+		if !strings.HasPrefix(ent.Name(), pathDevicePrefix[1:]) {
+			continue
+		}
+		dev, err := StringToDevice(strings.TrimPrefix(ent.Name(), pathDevicePrefix[1:]))
+		if err == nil {
+			found[dev] = true
+		}
+	}
+	c.mtx.Lock()
+	for dev := range c.devices {
+		found[dev] = true
+	}
+	c.mtx.Unlock()
+
+	result := make([]Device, 0, len(found))
+	for dev := range found {
+		result = append(result, dev)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bus != result[j].Bus {
+			return result[i].Bus < result[j].Bus
+		}
+		return result[i].ChipSelect < result[j].ChipSelect
+	})
+	return result, nil
+}
+
+func (c *controller) AddDevice(dev Device, spi bus.SPI) error {
+	logrus.Traceln("spi.controller.AddDevice()")
+	if dev.Bus < 0 || dev.ChipSelect < 0 || spi == nil {
+		return ErrInvalidDevice
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, exists := c.devices[dev]; exists {
+		return ErrAlreadyExists
+	}
+	err := spi.Configure(defaultConfig)
+	if err != nil {
+		return ErrInvalidValue
+	}
+	c.devices[dev] = &device{spi: spi, cfg: defaultConfig}
+	return nil
+}
+
+func (c *controller) Configure(dev Device, cfg bus.SPIConfig) error {
+	logrus.Traceln("spi.controller.Configure()")
+	if cfg.Mode < bus.Mode0 || cfg.Mode > bus.Mode3 || cfg.BitsPerWord <= 0 || cfg.BitsPerWord > 32 || cfg.SpeedHz == 0 {
+		return ErrInvalidValue
+	}
+	d, err := c.device(dev)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	err = d.spi.Configure(cfg)
+	if err != nil {
+		return ErrInvalidValue
+	}
+	d.cfg = cfg
+	return nil
+}
+
+func (c *controller) GetConfig(dev Device) (bus.SPIConfig, error) {
+	logrus.Traceln("spi.controller.GetConfig()")
+	d, err := c.device(dev)
+	if err != nil {
+		return bus.SPIConfig{}, err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return d.cfg, nil
+}
+
+func (c *controller) Transfer(dev Device, data []byte) ([]byte, error) {
+	logrus.Traceln("spi.controller.Transfer()")
+	if len(data) == 0 || len(data) > MaxLength {
+		return nil, ErrInvalidValue
+	}
+	d, err := c.device(dev)
+	if err != nil {
+		return nil, err
+	}
+	c.mtx.Lock()
+	size := wordSize(d.cfg.BitsPerWord)
+	c.mtx.Unlock()
+	if len(data)%size != 0 {
+		return nil, ErrInvalidValue
+	}
+
+	result := make([]byte, len(data))
+	err = d.spi.Tx(data, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// wordSize returns number of bytes used by spidev for single word of given size
+func wordSize(bitsPerWord int) int {
+	switch {
+	case bitsPerWord <= 8:
+		return 1
+	case bitsPerWord <= 16:
+		return 2
+	default:
+		return 4
+	}
+}
+
+// device returns already opened (or added) device or opens /dev/spidevB.C
+func (c *controller) device(dev Device) (*device, error) {
+	if dev.Bus < 0 || dev.ChipSelect < 0 {
+		return nil, ErrInvalidDevice
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if d, exists := c.devices[dev]; exists {
+		return d, nil
+	}
+
+	hw, err := openDevice(c.devPath, DeviceToString(dev))
+	if os.IsNotExist(err) {
+		return nil, ErrInvalidDevice
+	}
+	if err != nil {
+		return nil, ErrUnknown
+	}
+	err = hw.Configure(defaultConfig)
+	if err != nil {
+		hw.Close()
+		return nil, ErrUnknown
+	}
+	d := &device{spi: hw, cfg: defaultConfig}
+	c.devices[dev] = d
+	return d, nil
+}
+
+// DeviceToString returns device name in "B.C" format used by spidev
+func DeviceToString(dev Device) string {
+	return fmt.Sprintf("%d.%d", dev.Bus, dev.ChipSelect)
+}
+
+// StringToDevice parses device name in "B.C" format
+func StringToDevice(name string) (Device, error) {
+	var dev Device
+	var rest string
+	n, _ := fmt.Sscanf(name, "%d.%d%s", &dev.Bus, &dev.ChipSelect, &rest)
+	if n != 2 || dev.Bus < 0 || dev.ChipSelect < 0 {
+		return Device{}, ErrInvalidDevice
+	}
+	return dev, nil
+}
+
+func CreateController(devPath string) Controller {
+	logrus.Traceln("spi.CreateController()")
+	return &controller{devPath: devPath, devices: map[Device]*device{}}
+}
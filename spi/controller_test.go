@@ -0,0 +1,83 @@
+package spi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	devPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "spidev0.1"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "spidev"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "ttyS0"), nil, 0644))
+
+	ctrl := CreateController(devPath)
+	require.NoError(t, ctrl.AddDevice(Device{Bus: 2, ChipSelect: 0}, CreateLoopback()))
+
+	t.Run("add device - already exists", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, ctrl.AddDevice(Device{Bus: 2, ChipSelect: 0}, CreateLoopback()))
+		assert.Equal(t, ErrInvalidDevice, ctrl.AddDevice(Device{Bus: -1, ChipSelect: 0}, CreateLoopback()))
+	})
+
+	t.Run("list devices", func(t *testing.T) {
+		devices, err := ctrl.ListDevices()
+		assert.NoError(t, err)
+		assert.Equal(t, []Device{{Bus: 0, ChipSelect: 1}, {Bus: 2, ChipSelect: 0}}, devices)
+	})
+
+	t.Run("configure", func(t *testing.T) {
+		dev := Device{Bus: 2, ChipSelect: 0}
+		cfg, err := ctrl.GetConfig(dev)
+		assert.NoError(t, err)
+		assert.Equal(t, bus.SPIConfig{Mode: bus.Mode0, BitsPerWord: 8, SpeedHz: DefaultSpeed}, cfg)
+
+		expected := bus.SPIConfig{Mode: bus.Mode3, BitsPerWord: 16, SpeedHz: 8000000}
+		require.NoError(t, ctrl.Configure(dev, expected))
+		cfg, err = ctrl.GetConfig(dev)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, cfg)
+
+		assert.Equal(t, ErrInvalidValue, ctrl.Configure(dev, bus.SPIConfig{Mode: 4, BitsPerWord: 8, SpeedHz: 1}))
+		assert.Equal(t, ErrInvalidValue, ctrl.Configure(dev, bus.SPIConfig{Mode: bus.Mode0, BitsPerWord: 0, SpeedHz: 1}))
+		assert.Equal(t, ErrInvalidValue, ctrl.Configure(dev, bus.SPIConfig{Mode: bus.Mode0, BitsPerWord: 8}))
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		data, err := ctrl.Transfer(Device{Bus: 2, ChipSelect: 0}, []byte{0xde, 0xad, 0xbe, 0xef})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, data)
+
+		_, err = ctrl.Transfer(Device{Bus: 2, ChipSelect: 0}, nil)
+		assert.Equal(t, ErrInvalidValue, err)
+		_, err = ctrl.Transfer(Device{Bus: 2, ChipSelect: 0}, make([]byte, MaxLength+1))
+		assert.Equal(t, ErrInvalidValue, err)
+		// 16 bits per word set by configure test needs even number of bytes
+		_, err = ctrl.Transfer(Device{Bus: 2, ChipSelect: 0}, []byte{0xde, 0xad, 0xbe})
+		assert.Equal(t, ErrInvalidValue, err)
+	})
+
+	t.Run("invalid device", func(t *testing.T) {
+		_, err := ctrl.Transfer(Device{Bus: 3, ChipSelect: 0}, []byte{1})
+		assert.Equal(t, ErrInvalidDevice, err)
+		// regular file does not accept SPI ioctls
+		_, err = ctrl.GetConfig(Device{Bus: 0, ChipSelect: 1})
+		assert.Equal(t, ErrUnknown, err)
+	})
+}
+
+func TestStringToDevice(t *testing.T) {
+	dev, err := StringToDevice("1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, Device{Bus: 1, ChipSelect: 2}, dev)
+	assert.Equal(t, "1.2", DeviceToString(dev))
+
+	for _, name := range []string{"", "1", "1.", "1.2x", "-1.0", "a.b"} {
+		_, err = StringToDevice(name)
+		assert.Equal(t, ErrInvalidDevice, err, name)
+	}
+}
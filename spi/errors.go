@@ -0,0 +1,10 @@
+package spi
+
+import "errors"
+
+var (
+	ErrInvalidDevice = errors.New("invalid device")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrUnknown       = errors.New("unknown error")
+)
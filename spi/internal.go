@@ -0,0 +1,123 @@
+package spi
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/sirupsen/logrus"
+)
+
+// functions in this file are only used internally so param validation is omited
+
+const (
+	pathDevicePrefix = "/spidev"
+
+	// ioctl requests from linux/spi/spidev.h
+	ioctlWrMode        = 0x40016b01
+	ioctlWrBitsPerWord = 0x40016b03
+	ioctlWrMaxSpeedHz  = 0x40046b04
+	ioctlMessage1      = 0x40206b00
+)
+
+// transfer mirrors struct spi_ioc_transfer
+type transfer struct {
+	txBuf       uint64
+	rxBuf       uint64
+	length      uint32
+	speedHz     uint32
+	delayUsecs  uint16
+	bitsPerWord uint8
+	csChange    uint8
+	txNbits     uint8
+	rxNbits     uint8
+	wordDelay   uint8
+	pad         uint8
+}
+
+func ioctl(fd, request, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// hwDevice is SPI device handled by kernel driver through /dev/spidevB.C character device
+type hwDevice struct {
+	mtx  sync.Mutex
+	file *os.File
+	cfg  bus.SPIConfig
+}
+
+func openDevice(devPath, name string) (*hwDevice, error) {
+	file, err := os.OpenFile(devPath+pathDevicePrefix+name, os.O_RDWR, 0)
+	if err != nil {
+		logrus.Traceln("openDevice() cannot open SPI device:", err)
+		return nil, err
+	}
+	return &hwDevice{file: file}, nil
+}
+
+func (d *hwDevice) Configure(cfg bus.SPIConfig) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.file == nil {
+		return bus.ErrClosed
+	}
+
+	mode := uint8(cfg.Mode)
+	bits := uint8(cfg.BitsPerWord)
+	speed := cfg.SpeedHz
+	for _, setting := range []struct {
+		request uintptr
+		arg     unsafe.Pointer
+	}{{ioctlWrMode, unsafe.Pointer(&mode)}, {ioctlWrBitsPerWord, unsafe.Pointer(&bits)}, {ioctlWrMaxSpeedHz, unsafe.Pointer(&speed)}} {
+		err := ioctl(d.file.Fd(), setting.request, uintptr(setting.arg))
+		if err != nil {
+			logrus.Traceln("Configure() SPI setting rejected:", err)
+			return bus.ErrInvalidConfig
+		}
+	}
+	d.cfg = cfg
+	return nil
+}
+
+func (d *hwDevice) Tx(w, r []byte) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.file == nil {
+		return bus.ErrClosed
+	}
+	if len(w) == 0 {
+		return nil
+	}
+
+	tr := transfer{txBuf: uint64(uintptr(unsafe.Pointer(&w[0]))), length: uint32(len(w)),
+		speedHz: d.cfg.SpeedHz, bitsPerWord: uint8(d.cfg.BitsPerWord)}
+	if r != nil {
+		tr.rxBuf = uint64(uintptr(unsafe.Pointer(&r[0])))
+	}
+	err := ioctl(d.file.Fd(), ioctlMessage1, uintptr(unsafe.Pointer(&tr)))
+	runtime.KeepAlive(w)
+	runtime.KeepAlive(r)
+	if err != nil {
+		logrus.Traceln("Tx() SPI transfer failed:", err)
+		return ErrUnknown
+	}
+	return nil
+}
+
+func (d *hwDevice) Close() error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.file == nil {
+		return bus.ErrClosed
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
@@ -0,0 +1,49 @@
+package spi
+
+import (
+	"sync"
+
+	"github.com/markamdev/repico/bus"
+)
+
+// loopback emulates device with MOSI connected to MISO, every sent byte is received back
+type loopback struct {
+	mtx    sync.Mutex
+	cfg    bus.SPIConfig
+	closed bool
+}
+
+func (l *loopback) Configure(cfg bus.SPIConfig) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.closed {
+		return bus.ErrClosed
+	}
+	l.cfg = cfg
+	return nil
+}
+
+func (l *loopback) Tx(w, r []byte) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.closed {
+		return bus.ErrClosed
+	}
+	if r != nil && len(r) != len(w) {
+		return bus.ErrInvalidValue
+	}
+	copy(r, w)
+	return nil
+}
+
+func (l *loopback) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.closed = true
+	return nil
+}
+
+// CreateLoopback creates in-memory device returning sent data (for tests and development without hardware)
+func CreateLoopback() bus.SPI {
+	return &loopback{}
+}
@@ -0,0 +1,27 @@
+package spi
+
+import "github.com/markamdev/repico/bus"
+
+const (
+	// DefaultSpeed - clock frequency set when device is opened
+	DefaultSpeed = 1000000
+	// MaxLength - maximum number of bytes in single transfer (default spidev buffer size)
+	MaxLength = 4096
+)
+
+// Device identifies SPI device by bus number and chip select line
+type Device struct {
+	Bus        int
+	ChipSelect int
+}
+
+// Controller is an interface of object giving access to SPI devices
+type Controller interface {
+	ListDevices() ([]Device, error)
+	// AddDevice registers additional (ex. software or loopback) device
+	AddDevice(dev Device, spi bus.SPI) error
+	Configure(dev Device, cfg bus.SPIConfig) error
+	GetConfig(dev Device) (bus.SPIConfig, error)
+	// Transfer sends data and returns the same number of bytes received at the same time
+	Transfer(dev Device, data []byte) ([]byte, error)
+}
@@ -0,0 +1,14 @@
+package stepper
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid stepper configuration")
+	ErrInvalidName   = errors.New("invalid name")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound      = errors.New("not found")
+	ErrBusy          = errors.New("stepper is moving")
+	ErrNoLimitSwitch = errors.New("no limit switch configured")
+	ErrHomingFailed  = errors.New("limit switch not reached")
+	ErrStopped       = errors.New("stopped")
+)
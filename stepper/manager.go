@@ -0,0 +1,99 @@
+package stepper
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+type manager struct {
+	ctrl     gpio.Claims
+	mtx      sync.Mutex
+	steppers map[string]Stepper
+}
+
+func (m *manager) Add(name string, cfg Config) error {
+	logrus.Traceln("stepper.manager.Add()")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, exists := m.steppers[name]; exists {
+		return ErrAlreadyExists
+	}
+	requested := usedPins(cfg)
+	for _, stp := range m.steppers {
+		for pin := range usedPins(stp.Status().Config) {
+			if requested[pin] {
+				return ErrAlreadyExists
+			}
+		}
+	}
+
+	stp, err := CreateStepper(m.ctrl, name, cfg)
+	if err != nil {
+		return err
+	}
+	m.steppers[name] = stp
+	return nil
+}
+
+func (m *manager) Remove(name string) error {
+	logrus.Traceln("stepper.manager.Remove()")
+	m.mtx.Lock()
+	stp, exists := m.steppers[name]
+	delete(m.steppers, name)
+	m.mtx.Unlock()
+
+	if !exists {
+		return ErrNotFound
+	}
+	return stp.Close()
+}
+
+func (m *manager) Get(name string) (Stepper, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	stp, exists := m.steppers[name]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return stp, nil
+}
+
+func (m *manager) List() []Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	result := make([]Status, 0, len(m.steppers))
+	for _, stp := range m.steppers {
+		result = append(result, stp.Status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func usedPins(cfg Config) map[int]bool {
+	result := map[int]bool{}
+	switch cfg.Driver {
+	case StepDir:
+		result[cfg.StepPin] = true
+		result[cfg.DirPin] = true
+	case FourPhase:
+		for _, pin := range cfg.PhasePins {
+			result[pin] = true
+		}
+	}
+	if cfg.LimitPin != NoPin {
+		result[cfg.LimitPin] = true
+	}
+	return result
+}
+
+func CreateManager(ctrl gpio.Claims) Manager {
+	logrus.Traceln("stepper.CreateManager()")
+	return &manager{ctrl: ctrl, steppers: map[string]Stepper{}}
+}
@@ -0,0 +1,325 @@
+package stepper
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/sirupsen/logrus"
+)
+
+// fourPhaseSequence - full step sequence with two coils energized at a time
+var fourPhaseSequence = [4][4]int{
+	{1, 1, 0, 0},
+	{0, 1, 1, 0},
+	{0, 0, 1, 1},
+	{1, 0, 0, 1},
+}
+
+type stepper struct {
+	name    string
+	cfg     Config
+	ctrl    gpio.Owned
+	claimed map[int]bool
+
+	mtx      sync.Mutex
+	position int
+	target   int
+	homing   bool
+	homed    bool
+	lastErr  error
+	cancel   chan struct{}
+	done     chan struct{}
+}
+
+func (s *stepper) MoveTo(position int) error {
+	logrus.Traceln("stepper.stepper.MoveTo()")
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.done != nil {
+		return ErrBusy
+	}
+
+	s.target = position
+	s.start(func(cancel chan struct{}) error {
+		return s.run(cancel, s.cfg.MaxSpeed, func() bool {
+			return s.position == s.target
+		})
+	})
+	return nil
+}
+
+func (s *stepper) Move(steps int) error {
+	logrus.Traceln("stepper.stepper.Move()")
+	s.mtx.Lock()
+	position := s.position + steps
+	s.mtx.Unlock()
+	return s.MoveTo(position)
+}
+
+func (s *stepper) Home() error {
+	logrus.Traceln("stepper.stepper.Home()")
+	if s.cfg.LimitPin == NoPin {
+		return ErrNoLimitSwitch
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.done != nil {
+		return ErrBusy
+	}
+
+	s.homing = true
+	s.homed = false
+	s.target = s.position - s.cfg.HomingSteps
+	s.start(func(cancel chan struct{}) error {
+		reached := false
+		err := s.run(cancel, s.cfg.HomeSpeed, func() bool {
+			value, err := s.ctrl.GetValue(s.cfg.LimitPin)
+			if err != nil {
+				logrus.Errorln("Failed to read limit switch:", err)
+				return true
+			}
+			reached = value == s.cfg.LimitActive
+			return reached || s.position == s.target
+		})
+		if err == nil && !reached {
+			err = ErrHomingFailed
+		}
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if err == nil {
+			s.position = 0
+			s.homed = true
+		}
+		s.target = s.position
+		s.homing = false
+		return err
+	})
+	return nil
+}
+
+// Stop cancels motion and waits until it finishes, only the first of concurrent calls
+// closes cancel channel while motion is still considered running until its worker exits
+func (s *stepper) Stop() {
+	logrus.Traceln("stepper.stepper.Stop()")
+	s.mtx.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mtx.Unlock()
+
+	if done == nil {
+		return
+	}
+	if cancel != nil {
+		close(cancel)
+	}
+	<-done
+}
+
+func (s *stepper) Status() Status {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return Status{
+		Name:      s.name,
+		Config:    s.cfg,
+		Position:  s.position,
+		Target:    s.target,
+		Moving:    s.done != nil,
+		Homing:    s.homing,
+		Homed:     s.homed,
+		LastError: s.lastErr,
+	}
+}
+
+func (s *stepper) Close() error {
+	logrus.Traceln("stepper.stepper.Close()")
+	s.Stop()
+
+	return gpio.ReleasePins(s.ctrl, s.claimed)
+}
+
+// start launches motion in background, must be called with mutex locked
+func (s *stepper) start(motion func(cancel chan struct{}) error) {
+	cancel := make(chan struct{})
+	done := make(chan struct{})
+	s.cancel, s.done = cancel, done
+	s.lastErr = nil
+
+	go func() {
+		defer close(done)
+		err := motion(cancel)
+		if s.cfg.Driver == FourPhase {
+			// coils are released when motor stands still to avoid overheating
+			s.writePhase([4]int{})
+		}
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if err != nil {
+			logrus.Warnf("Stepper '%s' motion finished with error: %v\n", s.name, err)
+			s.target = s.position
+			s.homing = false
+		}
+		s.lastErr = err
+		s.cancel, s.done = nil, nil
+	}()
+}
+
+// run executes steps towards target using trapezoidal speed profile until finished returns true
+// Position and target are accessed with mutex locked, it is released only for step delays
+func (s *stepper) run(cancel chan struct{}, maxSpeed float64, finished func() bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	speed := 0.0
+	for !finished() {
+		remaining := s.target - s.position
+		direction := 1
+		if remaining < 0 {
+			direction = -1
+			remaining = -remaining
+		}
+
+		speed = nextSpeed(speed, float64(remaining), maxSpeed, s.cfg.Acceleration)
+		err := s.step(direction)
+		if err != nil {
+			return err
+		}
+		s.position += direction
+
+		s.mtx.Unlock()
+		select {
+		case <-cancel:
+			s.mtx.Lock()
+			return ErrStopped
+		case <-time.After(time.Duration(float64(time.Second) / speed)):
+		}
+		s.mtx.Lock()
+	}
+	return nil
+}
+
+// nextSpeed returns speed for next step accelerating or braking depending on remaining distance
+func nextSpeed(current, remaining, maxSpeed, acceleration float64) float64 {
+	if acceleration <= 0 {
+		return maxSpeed
+	}
+	minSpeed := math.Min(math.Sqrt(2*acceleration), maxSpeed)
+	brakingDistance := current * current / (2 * acceleration)
+	if remaining <= brakingDistance {
+		return math.Max(math.Sqrt(math.Max(current*current-2*acceleration, 0)), minSpeed)
+	}
+	return math.Min(math.Max(math.Sqrt(current*current+2*acceleration), minSpeed), maxSpeed)
+}
+
+func (s *stepper) step(direction int) error {
+	switch s.cfg.Driver {
+	case StepDir:
+		dirValue := 0
+		if direction > 0 {
+			dirValue = 1
+		}
+		err := s.ctrl.SetValue(s.cfg.DirPin, dirValue)
+		if err != nil {
+			return err
+		}
+		err = s.ctrl.SetValue(s.cfg.StepPin, 1)
+		if err != nil {
+			return err
+		}
+		return s.ctrl.SetValue(s.cfg.StepPin, 0)
+	case FourPhase:
+		index := ((s.position+direction)%4 + 4) % 4
+		return s.writePhase(fourPhaseSequence[index])
+	default:
+		return ErrInvalidConfig
+	}
+}
+
+func (s *stepper) writePhase(phase [4]int) error {
+	for i, pin := range s.cfg.PhasePins {
+		err := s.ctrl.SetValue(pin, phase[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stepper) outputPins() []int {
+	if s.cfg.Driver == StepDir {
+		return []int{s.cfg.StepPin, s.cfg.DirPin}
+	}
+	return s.cfg.PhasePins
+}
+
+func applyDefaults(cfg Config) Config {
+	if cfg.HomeSpeed == 0 {
+		cfg.HomeSpeed = cfg.MaxSpeed
+	}
+	if cfg.HomingSteps == 0 {
+		cfg.HomingSteps = DefaultHomingSteps
+	}
+	return cfg
+}
+
+func validateConfig(cfg Config) error {
+	switch cfg.Driver {
+	case StepDir:
+		if cfg.StepPin < 0 || cfg.DirPin < 0 || cfg.StepPin == cfg.DirPin {
+			return ErrInvalidConfig
+		}
+	case FourPhase:
+		if len(cfg.PhasePins) != 4 {
+			return ErrInvalidConfig
+		}
+		for _, pin := range cfg.PhasePins {
+			if pin < 0 {
+				return ErrInvalidConfig
+			}
+		}
+	default:
+		return ErrInvalidConfig
+	}
+	if cfg.LimitPin < NoPin || (cfg.LimitActive != 0 && cfg.LimitActive != 1) {
+		return ErrInvalidConfig
+	}
+	if cfg.MaxSpeed <= 0 || cfg.HomeSpeed <= 0 || cfg.Acceleration < 0 || cfg.HomingSteps < 0 {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// CreateStepper claims all pins used by stepper (as "stepper:{name}" owner) and returns object controlling it
+func CreateStepper(ctrl gpio.Claims, name string, cfg Config) (Stepper, error) {
+	logrus.Traceln("stepper.CreateStepper()")
+	cfg = applyDefaults(cfg)
+	err := validateConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &stepper{name: name, cfg: cfg, ctrl: ctrl.WithOwner(gpio.DriverOwner(ownerKind, name))}
+	pins := map[int]gpio.Direction{}
+	for _, pin := range result.outputPins() {
+		pins[pin] = gpio.Output
+	}
+	if len(pins) != len(result.outputPins()) {
+		return nil, ErrInvalidConfig
+	}
+	if cfg.LimitPin != NoPin {
+		if _, used := pins[cfg.LimitPin]; used {
+			return nil, ErrInvalidConfig
+		}
+		pins[cfg.LimitPin] = gpio.Input
+	}
+
+	result.claimed, err = gpio.ClaimPins(result.ctrl, pins)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,198 @@
+package stepper
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForStop(t *testing.T, stp Stepper) Status {
+	require.Eventually(t, func() bool { return !stp.Status().Moving }, 2*time.Second, time.Millisecond)
+	return stp.Status()
+}
+
+// phases returns values of four phase stepper coils connected to pins 1-4
+func phases(ctrl *gpiotest.Fake) [4]int {
+	return [4]int{ctrl.Value(1), ctrl.Value(2), ctrl.Value(3), ctrl.Value(4)}
+}
+
+func TestStepDir(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	mgr := CreateManager(ctrl)
+
+	t.Run("add - invalid config", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Driver: StepDir, StepPin: 1, DirPin: 1, MaxSpeed: 10}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Driver: StepDir, StepPin: 1, DirPin: 2}))
+		assert.Equal(t, ErrInvalidConfig, mgr.Add("x", Config{Driver: FourPhase, PhasePins: []int{1, 2}, MaxSpeed: 10}))
+	})
+
+	require.NoError(t, mgr.Add("x", Config{Driver: StepDir, StepPin: 1, DirPin: 2, LimitPin: 3,
+		MaxSpeed: 5000, Acceleration: 100000}))
+	stp, _ := mgr.Get("x")
+
+	t.Run("add - pin already used", func(t *testing.T) {
+		assert.Equal(t, ErrAlreadyExists, mgr.Add("y", Config{Driver: StepDir, StepPin: 3, DirPin: 4,
+			LimitPin: NoPin, MaxSpeed: 10}))
+	})
+
+	t.Run("relative and absolute moves", func(t *testing.T) {
+		require.NoError(t, stp.Move(20))
+		assert.Equal(t, 20, waitForStop(t, stp).Position)
+		assert.Equal(t, 20, ctrl.Pulses(1))
+		assert.Equal(t, 1, ctrl.Value(2))
+
+		require.NoError(t, stp.MoveTo(5))
+		assert.Equal(t, 5, waitForStop(t, stp).Position)
+		assert.Equal(t, 35, ctrl.Pulses(1))
+		assert.Equal(t, 0, ctrl.Value(2))
+	})
+
+	t.Run("homing", func(t *testing.T) {
+		ctrl.OnWrite(func(pin, value int) {
+			if pin == 1 && value == 1 && ctrl.Pulses(1) == 42 {
+				ctrl.Drive(3, 0)
+			}
+		})
+		ctrl.Drive(3, 1)
+		require.NoError(t, stp.Home())
+		status := waitForStop(t, stp)
+		assert.NoError(t, status.LastError)
+		assert.True(t, status.Homed)
+		assert.Equal(t, 0, status.Position)
+	})
+
+	t.Run("emergency stop", func(t *testing.T) {
+		ctrl.OnWrite(nil)
+		require.NoError(t, stp.MoveTo(1000000))
+		assert.Equal(t, ErrBusy, stp.MoveTo(0))
+		stp.Stop()
+		status := stp.Status()
+		assert.False(t, status.Moving)
+		assert.Equal(t, ErrStopped, status.LastError)
+		assert.Equal(t, status.Position, status.Target)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.NoError(t, mgr.Remove("x"))
+		assert.Empty(t, ctrl.Exported())
+	})
+}
+
+func TestFourPhase(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	stp, err := CreateStepper(ctrl, "x", Config{Driver: FourPhase, PhasePins: []int{1, 2, 3, 4},
+		LimitPin: NoPin, MaxSpeed: 5000})
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrNoLimitSwitch, stp.Home())
+
+	var mtx sync.Mutex
+	history := [][4]int{}
+	ctrl.OnWrite(func(pin, value int) {
+		if coils := phases(ctrl); coils[0]+coils[1]+coils[2]+coils[3] == 2 {
+			mtx.Lock()
+			history = append(history, coils)
+			mtx.Unlock()
+		}
+	})
+	require.NoError(t, stp.Move(-2))
+	waitForStop(t, stp)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	assert.Contains(t, history, fourPhaseSequence[3])
+	assert.Contains(t, history, fourPhaseSequence[2])
+	assert.Equal(t, [4]int{}, phases(ctrl), "coils should be released")
+}
+
+func TestExternalPin(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	ctrl.ExportExternal(2, gpio.Output)
+
+	_, err := CreateStepper(ctrl, "x", Config{Driver: StepDir, StepPin: 1, DirPin: 2, LimitPin: NoPin, MaxSpeed: 10})
+	assert.Equal(t, gpio.ErrExternalPin, err)
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Output}, ctrl.Exported())
+}
+
+func TestAlreadyExportedPin(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	require.NoError(t, ctrl.ExportPin(2, gpio.Input))
+	require.NoError(t, ctrl.ExportPin(3, gpio.Output))
+
+	_, err := CreateStepper(ctrl, "x", Config{Driver: StepDir, StepPin: 1, DirPin: 2, LimitPin: NoPin, MaxSpeed: 10})
+	assert.Equal(t, gpio.ErrInvalidDirection, err)
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Input, 3: gpio.Output}, ctrl.Exported(), "input should not be taken over")
+
+	stp, err := CreateStepper(ctrl, "x", Config{Driver: StepDir, StepPin: 1, DirPin: 3, LimitPin: NoPin, MaxSpeed: 10})
+	require.NoError(t, err)
+	require.NoError(t, stp.Close())
+	assert.Equal(t, map[int]gpio.Direction{2: gpio.Input, 3: gpio.Output}, ctrl.Exported(), "reused pin should stay exported")
+}
+
+func TestClaimedPins(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	stp, err := CreateStepper(ctrl, "x", Config{Driver: StepDir, StepPin: 1, DirPin: 2, LimitPin: 3, MaxSpeed: 10})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]gpio.Claim{
+		1: {Owners: []string{"stepper:x"}, Exclusive: true},
+		2: {Owners: []string{"stepper:x"}, Exclusive: true},
+		3: {Owners: []string{"stepper:x"}, Exclusive: true},
+	}, ctrl.ListClaims())
+	assert.Equal(t, gpio.ErrPinClaimed, ctrl.SetValue(2, 1))
+	assert.Equal(t, gpio.ErrPinClaimed, ctrl.UnexportPin(1))
+
+	_, err = CreateStepper(ctrl, "y", Config{Driver: StepDir, StepPin: 4, DirPin: 2, LimitPin: NoPin, MaxSpeed: 10})
+	assert.Equal(t, gpio.ErrPinClaimed, err)
+	assert.NotContains(t, ctrl.Exported(), 4)
+
+	require.NoError(t, stp.Close())
+	assert.Empty(t, ctrl.ListClaims())
+	assert.Empty(t, ctrl.Exported())
+}
+
+func TestConcurrentStop(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	stp, err := CreateStepper(ctrl, "x", Config{Driver: FourPhase, PhasePins: []int{1, 2, 3, 4},
+		LimitPin: NoPin, MaxSpeed: 5000})
+	require.NoError(t, err)
+
+	// releasing coils after stop blocks until second Stop is called
+	released := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	require.NoError(t, stp.MoveTo(1000000))
+	require.Eventually(t, func() bool { return stp.Status().Position > 1 }, time.Second, time.Millisecond)
+	ctrl.OnWrite(func(pin, value int) {
+		if phases(ctrl) == [4]int{} {
+			once.Do(func() { close(released) })
+			<-release
+		}
+	})
+
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		stp.Stop()
+	}()
+	<-released
+
+	second := make(chan struct{})
+	go func() {
+		defer close(second)
+		stp.Stop()
+	}()
+	// give second Stop time to reach motion it waits for
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-first
+	<-second
+
+	status := stp.Status()
+	assert.False(t, status.Moving)
+	assert.Equal(t, ErrStopped, status.LastError)
+}
@@ -0,0 +1,97 @@
+package stepper
+
+// Driver defines how stepper motor is connected
+// Possible values are InvalidDriver, StepDir and FourPhase
+type Driver int
+
+const (
+	// InvalidDriver - default value, not set
+	InvalidDriver Driver = iota
+	// StepDir - external driver controlled with step and direction pins
+	StepDir
+	// FourPhase - unipolar motor with four coils driven directly (ex. ULN2003)
+	FourPhase
+)
+
+const (
+	driverStepDir   = "step-dir"
+	driverFourPhase = "4-phase"
+)
+
+// ownerKind is a kind of driver identity used by steppers to claim pins
+const ownerKind = "stepper"
+
+// NoPin marks optional pin as not connected
+const NoPin = -1
+
+// DefaultHomingSteps - maximum number of steps done while looking for limit switch
+const DefaultHomingSteps = 100000
+
+func DriverToString(dr Driver) string {
+	switch dr {
+	case StepDir:
+		return driverStepDir
+	case FourPhase:
+		return driverFourPhase
+	default:
+		return "-"
+	}
+}
+
+func StringToDriver(dr string) Driver {
+	switch dr {
+	case driverStepDir:
+		return StepDir
+	case driverFourPhase:
+		return FourPhase
+	default:
+		return InvalidDriver
+	}
+}
+
+// Config describes stepper connection and motion profile
+// Speeds are given in steps per second and acceleration in steps per second squared,
+// zero acceleration means moving with maximum speed from the very first step
+type Config struct {
+	Driver       Driver
+	StepPin      int
+	DirPin       int
+	PhasePins    []int
+	LimitPin     int
+	LimitActive  int
+	MaxSpeed     float64
+	Acceleration float64
+	HomeSpeed    float64
+	HomingSteps  int
+}
+
+// Status describes current stepper state
+type Status struct {
+	Name      string
+	Config    Config
+	Position  int
+	Target    int
+	Moving    bool
+	Homing    bool
+	Homed     bool
+	LastError error
+}
+
+// Stepper is an interface of single stepper motor controlling object
+// Moves are executed in background, Stop interrupts them immediately
+type Stepper interface {
+	MoveTo(position int) error
+	Move(steps int) error
+	Home() error
+	Stop()
+	Status() Status
+	Close() error
+}
+
+// Manager is an interface of object keeping named steppers
+type Manager interface {
+	Add(name string, cfg Config) error
+	Remove(name string) error
+	Get(name string) (Stepper, error)
+	List() []Status
+}
@@ -0,0 +1,65 @@
+// Package sysfs contains helpers for accessing attribute files of kernel devices
+// shared by packages handling different device classes
+package sysfs
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// waitInterval is a time between checks done by WaitFor
+const waitInterval = time.Millisecond
+
+// ReadAttribute returns content of attribute file without trailing new line characters
+func ReadAttribute(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Traceln("sysfs.ReadAttribute() reading failed:", err)
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// WriteAttribute replaces content of attribute file with value
+func WriteAttribute(path, value string) error {
+	fAttr, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		logrus.Traceln("sysfs.WriteAttribute() opening failed:", err)
+		return err
+	}
+	defer fAttr.Close()
+
+	_, err = fAttr.WriteString(value)
+	if err != nil {
+		logrus.Traceln("sysfs.WriteAttribute() writing error:", err)
+		return err
+	}
+	return nil
+}
+
+// Exists returns true if file or directory exists
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	if err != nil {
+		logrus.Traceln("sysfs.Exists() Stat() error:", err)
+		return false
+	}
+	return true
+}
+
+// WaitFor waits until path exists (or not) as kernel (and udev setting permissions) may need some time
+// to create (or remove) files, returns false on timeout
+func WaitFor(path string, exists bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for Exists(path) != exists {
+		if time.Now().After(deadline) {
+			logrus.Traceln("sysfs.WaitFor() timeout for path:", path)
+			return false
+		}
+		time.Sleep(waitInterval)
+	}
+	return true
+}
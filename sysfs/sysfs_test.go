@@ -0,0 +1,43 @@
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brightness")
+	require.NoError(t, os.WriteFile(path, []byte("255\n"), 0644))
+
+	value, err := ReadAttribute(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "255", value)
+
+	require.NoError(t, WriteAttribute(path, "7"))
+	value, err = ReadAttribute(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "7", value)
+
+	_, err = ReadAttribute(path + "_missing")
+	assert.True(t, os.IsNotExist(err))
+	assert.Error(t, WriteAttribute(path+"_missing", "1"))
+}
+
+func TestWaitFor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "delay_on")
+	assert.False(t, Exists(path))
+	assert.False(t, WaitFor(path, true, 5*time.Millisecond))
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		os.WriteFile(path, []byte("500\n"), 0644)
+	}()
+	assert.True(t, WaitFor(path, true, time.Second))
+	assert.True(t, Exists(path))
+	assert.True(t, WaitFor(path, true, 0))
+}
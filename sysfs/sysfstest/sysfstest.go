@@ -0,0 +1,40 @@
+// Package sysfstest builds fake sysfs trees for tests of packages reading and writing kernel attributes
+package sysfstest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CreateDir creates directory with attribute files like ones created by kernel and returns its path,
+// attribute names may contain subdirectories, non empty values get trailing new line (as printed by kernel)
+// and empty ones create empty files (ex. write only export attributes)
+func CreateDir(t testing.TB, dirPath string, attributes map[string]string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dirPath, 0755))
+	for name, content := range attributes {
+		if content != "" {
+			content += "\n"
+		}
+		WriteFile(t, filepath.Join(dirPath, name), content)
+	}
+	return dirPath
+}
+
+// WriteFile replaces content of file with given one as is, creating missing parent directories
+func WriteFile(t testing.TB, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// ReadFile returns content of file as is, without trimming new line characters
+func ReadFile(t testing.TB, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,158 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/iio"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type analogHandler struct {
+	ctrl iio.Controller
+}
+
+func (ah *analogHandler) getAllDevices(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllDevices() handler")
+	devices, err := ah.ctrl.ListDevices()
+	if err != nil {
+		logrus.Errorln("Failed to list IIO devices:", err)
+		writeAnalogError(wr, err)
+		return
+	}
+	if len(devices) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]analogDevice, 0, len(devices))
+	for _, dev := range devices {
+		result = append(result, analogDevice{Device: dev.Number, Name: dev.Name, Channels: dev.Channels, Buffered: dev.Buffered})
+	}
+	writeJSON(wr, result)
+}
+
+func (ah *analogHandler) getDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getDevice() handler")
+	device, ok := intParam(wr, req, "device")
+	if !ok {
+		return
+	}
+
+	readings, err := ah.ctrl.ReadAll(device)
+	if err != nil {
+		logrus.Errorf("Failed to read IIO device '%d': %v\n", device, err)
+		writeAnalogError(wr, err)
+		return
+	}
+	result := make([]analogReading, 0, len(readings))
+	for _, reading := range readings {
+		result = append(result, newAnalogReading(device, reading))
+	}
+	writeJSON(wr, result)
+}
+
+func (ah *analogHandler) getChannel(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getChannel() handler")
+	device, ok := intParam(wr, req, "device")
+	if !ok {
+		return
+	}
+	channel := mux.Vars(req)["channel"]
+
+	reading, err := ah.ctrl.ReadChannel(device, channel)
+	if err != nil {
+		logrus.Errorf("Failed to read IIO channel '%d/%s': %v\n", device, channel, err)
+		writeAnalogError(wr, err)
+		return
+	}
+	writeJSON(wr, newAnalogReading(device, reading))
+}
+
+func (ah *analogHandler) capture(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("capture() handler")
+	device, ok := intParam(wr, req, "device")
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData analogCapturePointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(requestData.Channels) == 0 || requestData.Samples == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+	timeout := time.Duration(0)
+	if requestData.Timeout != nil {
+		timeout = time.Duration(*requestData.Timeout) * time.Millisecond
+	}
+
+	capture, err := ah.ctrl.Capture(device, requestData.Channels, *requestData.Samples, timeout)
+	if err != nil {
+		logrus.Errorf("Failed to capture IIO device '%d': %v\n", device, err)
+		writeAnalogError(wr, err)
+		return
+	}
+	writeJSON(wr, analogCapture{Device: device, Channels: capture.Channels, Units: capture.Units, Scans: capture.Scans})
+}
+
+func writeAnalogError(wr http.ResponseWriter, err error) {
+	switch err {
+	case iio.ErrInvalidDevice, iio.ErrInvalidChannel:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case iio.ErrInvalidValue, iio.ErrNotSupported:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	case iio.ErrBusy:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case iio.ErrTimeout:
+		server.WriteMessage(wr, http.StatusGatewayTimeout, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func newAnalogReading(device int, reading iio.Reading) analogReading {
+	return analogReading{Device: device, Channel: reading.Channel, Raw: reading.Raw, Value: reading.Value, Unit: reading.Unit}
+}
+
+type analogDevice struct {
+	Device   int      `json:"device"`
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Buffered bool     `json:"buffered"`
+}
+
+type analogReading struct {
+	Device  int     `json:"device"`
+	Channel string  `json:"channel"`
+	Raw     int64   `json:"raw"`
+	Value   float64 `json:"value"`
+	Unit    string  `json:"unit,omitempty"`
+}
+
+// analogCapturePointer describes buffered capture with timeout given in milliseconds
+type analogCapturePointer struct {
+	Channels []string `json:"channels"`
+	Samples  *int     `json:"samples"`
+	Timeout  *int     `json:"timeout"`
+}
+
+type analogCapture struct {
+	Device   int         `json:"device"`
+	Channels []string    `json:"channels"`
+	Units    []string    `json:"units"`
+	Scans    [][]float64 `json:"scans"`
+}
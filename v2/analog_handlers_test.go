@@ -0,0 +1,79 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/iio"
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalogHandlers(t *testing.T) {
+	basePath := t.TempDir()
+	devPath := t.TempDir()
+	ctrl := iio.CreateController(basePath, devPath)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachAnalogHandlers(subRtr, ctrl)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list devices - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/analog", "").Code)
+	})
+
+	attributes := map[string]string{
+		"name":                            "mcp3008",
+		"in_voltage0_raw":                 "512",
+		"in_voltage_scale":                "3.2226",
+		"buffer/enable":                   "0",
+		"buffer/length":                   "0",
+		"scan_elements/in_voltage0_en":    "0",
+		"scan_elements/in_voltage0_index": "0",
+		"scan_elements/in_voltage0_type":  "be:u10/16>>0",
+	}
+	sysfstest.CreateDir(t, filepath.Join(basePath, "iio:device0"), attributes)
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "iio:device0"), []byte{0x02, 0x00, 0x03, 0xff}, 0644))
+
+	t.Run("list devices", func(t *testing.T) {
+		res := serve("GET", "/v2/analog", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"device":0,"name":"mcp3008","channels":["voltage0"],"buffered":true}]`, res.Body.String())
+	})
+
+	t.Run("read channels", func(t *testing.T) {
+		res := serve("GET", "/v2/analog/0", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"device":0,"channel":"voltage0","raw":512,"value":1649.9712,"unit":"mV"}]`, res.Body.String())
+
+		res = serve("GET", "/v2/analog/0/voltage0", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"device":0,"channel":"voltage0","raw":512,"value":1649.9712,"unit":"mV"}`, res.Body.String())
+
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/analog/0/voltage1", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/analog/1", "").Code)
+	})
+
+	t.Run("capture", func(t *testing.T) {
+		res := serve("POST", "/v2/analog/0/capture", `{"channels":["voltage0"],"samples":2,"timeout":100}`)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"device":0,"channels":["voltage0"],"units":["mV"],"scans":[[1649.9712],[3296.7198]]}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/analog/0/capture", `{"channels":["voltage0"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/analog/0/capture", `{"channels":["voltage0"],"samples":-1}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("POST", "/v2/analog/0/capture", `{"channels":["voltage3"],"samples":1}`).Code)
+	})
+}
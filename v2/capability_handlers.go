@@ -0,0 +1,60 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+// getCapabilities describes all GPIO lines, not only exported ones
+func (gh *gpioHandler) getCapabilities(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getCapabilities() handler")
+	lines, err := gh.ctrl.Capabilities()
+	if err != nil {
+		logrus.Errorln("Error when describing GPIO lines:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	if len(lines) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]lineInfo, 0, len(lines))
+	for _, line := range lines {
+		info := lineInfo{Pin: line.Pin, Chip: line.Chip, Offset: line.Offset, Name: line.Name,
+			Consumer: line.Consumer, Usage: gpio.UsageToString(line.Usage), ActiveLow: line.ActiveLow,
+			Features: lineFeatures{EdgeDetection: line.Features.EdgeDetection}}
+		if line.Direction != gpio.Invalid {
+			info.Direction = gpio.DirectionToString(line.Direction)
+		}
+		if line.Features.Bias != gpio.BiasUnknown {
+			info.Features.Bias = gpio.BiasToString(line.Features.Bias)
+		}
+		if line.Features.Drive != gpio.DriveUnknown {
+			info.Features.Drive = gpio.DriveToString(line.Features.Drive)
+		}
+		result = append(result, info)
+	}
+	writeJSON(wr, result)
+}
+
+type lineFeatures struct {
+	EdgeDetection bool   `json:"edge_detection"`
+	Bias          string `json:"bias,omitempty"`
+	Drive         string `json:"drive,omitempty"`
+}
+
+type lineInfo struct {
+	Pin       int          `json:"pin"`
+	Chip      string       `json:"chip"`
+	Offset    int          `json:"offset"`
+	Name      string       `json:"name,omitempty"`
+	Consumer  string       `json:"consumer,omitempty"`
+	Usage     string       `json:"usage"`
+	Direction string       `json:"direction,omitempty"`
+	ActiveLow bool         `json:"active_low"`
+	Features  lineFeatures `json:"features"`
+}
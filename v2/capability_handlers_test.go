@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/gpio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilityHandlers(t *testing.T) {
+	ctrl := &controllerStub{}
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachHandlers(subRtr, ctrl)
+
+	serve := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/v2/gpio/capabilities", nil)
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("no lines", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve().Code)
+	})
+
+	t.Run("list lines", func(t *testing.T) {
+		ctrl.linesToReturn = []gpio.LineInfo{
+			{Pin: 512, Chip: "pinctrl-bcm2711", Offset: 0, Name: "ID_SDA"},
+			{Pin: 530, Chip: "pinctrl-bcm2711", Offset: 18, Name: "GPIO18", Consumer: "sysfs", Usage: gpio.Exported,
+				Direction: gpio.Output,
+				Features:  gpio.Features{EdgeDetection: true, Bias: gpio.PullDown, Drive: gpio.OpenDrain}},
+		}
+
+		res := serve()
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[
+			{"pin":512,"chip":"pinctrl-bcm2711","offset":0,"name":"ID_SDA","usage":"free","active_low":false,
+			 "features":{"edge_detection":false}},
+			{"pin":530,"chip":"pinctrl-bcm2711","offset":18,"name":"GPIO18","consumer":"sysfs","usage":"exported",
+			 "direction":"out","active_low":false,"features":{"edge_detection":true,"bias":"pull-down","drive":"open-drain"}}
+		]`, res.Body.String())
+	})
+
+	t.Run("controller error", func(t *testing.T) {
+		ctrl.errorToReturn = gpio.ErrUnknown
+		assert.Equal(t, http.StatusInternalServerError, serve().Code)
+	})
+}
@@ -0,0 +1,99 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+// claimPin claims pin for owner given in request header, claim is exclusive unless requested otherwise
+func (gh *gpioHandler) claimPin(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("claimPin() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData claimPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	exclusive := true
+	if requestData.Exclusive != nil {
+		exclusive = *requestData.Exclusive
+	}
+
+	owner, ok := requestOwner(wr, req)
+	if !ok {
+		return
+	}
+	err = gh.ctrl.WithOwner(owner).Claim(pin, exclusive)
+	if err != nil {
+		logrus.Errorf("Failed to claim pin '%d': %v\n", pin, err)
+		writeClaimError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *gpioHandler) releasePin(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("releasePin() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	owner, ok := requestOwner(wr, req)
+	if !ok {
+		return
+	}
+	err := gh.ctrl.WithOwner(owner).Release(pin)
+	if err != nil {
+		logrus.Errorf("Failed to release pin '%d': %v\n", pin, err)
+		writeClaimError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+// adoptPin takes over pin exported by other process so it can be changed and unexported
+func (gh *gpioHandler) adoptPin(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("adoptPin() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	err := gh.ctrl.Adopt(pin)
+	if err != nil {
+		logrus.Errorf("Failed to adopt pin '%d': %v\n", pin, err)
+		writeClaimError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func writeClaimError(wr http.ResponseWriter, err error) {
+	switch err {
+	case gpio.ErrPinClaimed, gpio.ErrExternalPin, gpio.ErrAlreadyAdopted:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case gpio.ErrNoOwner, gpio.ErrNotClaimed, gpio.ErrNotExported:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+type claimPointer struct {
+	Exclusive *bool `json:"exclusive"`
+}
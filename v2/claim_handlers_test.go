@@ -0,0 +1,101 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/gpio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimHandlers(t *testing.T) {
+	ctrl := &controllerStub{}
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachHandlers(subRtr, ctrl)
+
+	serve := func(method, url, owner, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		if owner != "" {
+			req.Header.Set(ownerHeader, owner)
+		}
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("claim pin", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/gpio/4/claim", "alice", `{"exclusive":false}`).Code)
+		assert.Equal(t, "alice", ctrl.ownerGiven)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/gpio/4/claim", "alice", ``).Code)
+
+		ctrl.errorToReturn = gpio.ErrPinClaimed
+		assert.Equal(t, http.StatusConflict, serve("POST", "/v2/gpio/4/claim", "bob", `{}`).Code)
+		ctrl.errorToReturn = gpio.ErrNoOwner
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/gpio/4/claim", "", `{}`).Code)
+	})
+
+	t.Run("release pin", func(t *testing.T) {
+		ctrl.errorToReturn = nil
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/gpio/4/claim", "alice", "").Code)
+		ctrl.errorToReturn = gpio.ErrNotClaimed
+		assert.Equal(t, http.StatusBadRequest, serve("DELETE", "/v2/gpio/4/claim", "bob", "").Code)
+	})
+
+	t.Run("claimed pin operations", func(t *testing.T) {
+		ctrl.errorToReturn = gpio.ErrPinClaimed
+		assert.Equal(t, http.StatusConflict, serve("PATCH", "/v2/gpio/4", "bob", `{"value":1}`).Code)
+		assert.Equal(t, http.StatusConflict, serve("POST", "/v2/gpio/4/toggle", "bob", "").Code)
+		assert.Equal(t, http.StatusConflict, serve("DELETE", "/v2/gpio/4", "", "").Code)
+	})
+
+	t.Run("owner reserved for drivers", func(t *testing.T) {
+		ctrl.errorToReturn = nil
+		ctrl.ownerGiven = ""
+		assert.Equal(t, http.StatusForbidden, serve("POST", "/v2/gpio/4/claim", "device:relay1", `{}`).Code)
+		assert.Equal(t, http.StatusForbidden, serve("DELETE", "/v2/gpio/4/claim", "device:relay1", "").Code)
+		assert.Equal(t, http.StatusForbidden, serve("PATCH", "/v2/gpio/4", "stepper:x", `{"value":1}`).Code)
+		assert.Equal(t, http.StatusForbidden, serve("DELETE", "/v2/gpio/4", "device:relay1", "").Code)
+		assert.Equal(t, http.StatusForbidden, serve("DELETE", "/v2/gpio/4/counter", "device:relay1", "").Code)
+		assert.Empty(t, ctrl.ownerGiven, "controller should not be used on behalf of driver")
+	})
+
+	t.Run("list pins with owners", func(t *testing.T) {
+		ctrl.errorToReturn = nil
+		ctrl.mapToReturn = map[int]gpio.Direction{4: gpio.Output}
+		ctrl.claimsToReturn = map[int]gpio.Claim{4: {Owners: []string{"alice"}, Exclusive: true}}
+
+		res := serve("GET", "/v2/gpio", "", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"pin":4,"direction":"out","owners":["alice"],"exclusive":true}]`, res.Body.String())
+	})
+
+	t.Run("external pins", func(t *testing.T) {
+		ctrl.errorToReturn = nil
+		ctrl.mapToReturn = map[int]gpio.Direction{9: gpio.Input}
+		ctrl.claimsToReturn = map[int]gpio.Claim{}
+		ctrl.externalPins = []int{9}
+
+		res := serve("GET", "/v2/gpio", "", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"pin":9,"direction":"in","owner":"external"}]`, res.Body.String())
+
+		ctrl.errorToReturn = gpio.ErrExternalPin
+		assert.Equal(t, http.StatusConflict, serve("PATCH", "/v2/gpio/9", "", `{"value":1}`).Code)
+		assert.Equal(t, http.StatusConflict, serve("DELETE", "/v2/gpio/9", "", "").Code)
+		assert.Equal(t, http.StatusConflict, serve("POST", "/v2/gpio", "", `{"pin":9,"direction":"out"}`).Code)
+	})
+
+	t.Run("adopt pin", func(t *testing.T) {
+		ctrl.errorToReturn = nil
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/gpio/9/adopt", "", "").Code)
+		ctrl.errorToReturn = gpio.ErrAlreadyAdopted
+		assert.Equal(t, http.StatusConflict, serve("POST", "/v2/gpio/9/adopt", "", "").Code)
+		ctrl.errorToReturn = gpio.ErrNotExported
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/gpio/10/adopt", "", "").Code)
+	})
+}
@@ -0,0 +1,195 @@
+package v2
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/devices"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type deviceHandler struct {
+	reg devices.Registry
+}
+
+func (dh *deviceHandler) addDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addDevice() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var deviceDesc devicePointer
+	err := json.Unmarshal(data, &deviceDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if deviceDesc.Name == nil || deviceDesc.Type == nil {
+		logrus.Error("No proper device description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect device description")
+		return
+	}
+
+	err = dh.reg.Add(*deviceDesc.Name, *deviceDesc.Type, deviceDesc.Config)
+	if err != nil {
+		logrus.Warning("Device creation error:", err)
+		writeDeviceError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *deviceHandler) configureDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("configureDevice() handler")
+	name := mux.Vars(req)["name"]
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var deviceDesc devicePointer
+	err := json.Unmarshal(data, &deviceDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if deviceDesc.Config == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	err = dh.reg.Configure(name, deviceDesc.Config)
+	if err != nil {
+		logrus.Errorf("Failed to configure device '%s': %v\n", name, err)
+		writeDeviceError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *deviceHandler) deleteDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteDevice() handler")
+	name := mux.Vars(req)["name"]
+
+	err := dh.reg.Remove(name)
+	if err != nil {
+		logrus.Errorf("Failed to remove device '%s': %v\n", name, err)
+		writeDeviceError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *deviceHandler) getDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getDevice() handler")
+	name := mux.Vars(req)["name"]
+
+	info, err := dh.reg.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to get device '%s': %v\n", name, err)
+		writeDeviceError(wr, err)
+		return
+	}
+	writeJSON(wr, deviceInfoToJSON(info))
+}
+
+func (dh *deviceHandler) getAllDevices(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllDevices() handler")
+	infos := dh.reg.List()
+	if len(infos) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]deviceInfo, 0, len(infos))
+	for _, info := range infos {
+		result = append(result, deviceInfoToJSON(info))
+	}
+	writeJSON(wr, result)
+}
+
+func (dh *deviceHandler) getDeviceTypes(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getDeviceTypes() handler")
+	writeJSON(wr, dh.reg.Types())
+}
+
+// handleCommand passes request body (if any) as command arguments and returns command result
+func (dh *deviceHandler) handleCommand(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("handleCommand() handler")
+	name := mux.Vars(req)["name"]
+	command := mux.Vars(req)["command"]
+	// commands without arguments are sent with empty body so readBody is not used
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		logrus.Errorln("Failed to read request body:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "body reading error")
+		return
+	}
+
+	var args json.RawMessage
+	if len(data) > 0 {
+		if !json.Valid(data) {
+			logrus.Errorln("Invalid command arguments")
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		args = data
+	}
+
+	result, err := dh.reg.Command(name, command, args)
+	if err != nil {
+		logrus.Errorf("Command '%s' of device '%s' failed: %v\n", command, name, err)
+		writeDeviceError(wr, err)
+		return
+	}
+	if result == nil {
+		wr.WriteHeader(http.StatusOK)
+		return
+	}
+	writeJSON(wr, result)
+}
+
+func writeDeviceError(wr http.ResponseWriter, err error) {
+	switch err {
+	case devices.ErrNotFound:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case devices.ErrBusy, devices.ErrPinClaimed:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case devices.ErrInvalidName, devices.ErrInvalidType, devices.ErrInvalidConfig, devices.ErrInvalidCommand,
+		devices.ErrAlreadyExists, gpio.ErrInvalidPin, gpio.ErrInvalidDirection:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func deviceInfoToJSON(info devices.Info) deviceInfo {
+	return deviceInfo{
+		Name:  info.Name,
+		Type:  info.Type,
+		Pins:  info.Pins,
+		State: info.State,
+	}
+}
+
+// devicePointer describes device, configuration format is defined by device type
+type devicePointer struct {
+	Name   *string         `json:"name"`
+	Type   *string         `json:"type"`
+	Config json.RawMessage `json:"config"`
+}
+
+type deviceInfo struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Pins  []int       `json:"pins"`
+	State interface{} `json:"state"`
+}
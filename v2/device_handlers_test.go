@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/devices"
+	"github.com/markamdev/repico/gpio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceHandlers(t *testing.T) {
+	ctrl := &controllerStub{}
+	reg := devices.CreateRegistry(ctrl)
+	reg.Register(devices.RelayType, devices.NewRelay)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachDeviceHandlers(subRtr, reg)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list devices - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/devices", "").Code)
+
+		res := serve("GET", "/v2/device-types", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `["relay"]`, res.Body.String())
+	})
+
+	t.Run("add device", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/devices", `{"name":"pump"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/devices", `{"name":"pump","type":"valve","config":{"pin":5}}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/devices", `{"name":"pump","type":"relay","config":{}}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/devices", `{"name":"pump","type":"relay","config":{"pin":5}}`).Code)
+		// pin used by other device or user
+		ctrl.errorToReturn = gpio.ErrAlreadyExported
+		assert.Equal(t, http.StatusConflict, serve("POST", "/v2/devices", `{"name":"fan","type":"relay","config":{"pin":5}}`).Code)
+		ctrl.errorToReturn = nil
+
+		res := serve("GET", "/v2/devices", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"name":"pump","type":"relay","pins":[5],"state":{"pin":5,"active_low":false,"on":false}}]`, res.Body.String())
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		res := serve("POST", "/v2/devices/pump/on", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"pin":5,"active_low":false,"on":true}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/devices/pump/blink", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/devices/pump/on", "{").Code)
+		assert.Equal(t, http.StatusNotFound, serve("POST", "/v2/devices/fan/on", "").Code)
+	})
+
+	t.Run("configure device", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/devices/pump", `{"config":{"pin":6,"active_low":true}}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/devices/pump", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/devices/pump", `{"config":{"pin":-1}}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("PATCH", "/v2/devices/fan", `{"config":{"pin":7}}`).Code)
+
+		res := serve("GET", "/v2/devices/pump", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"name":"pump","type":"relay","pins":[6],"state":{"pin":6,"active_low":true,"on":false}}`, res.Body.String())
+	})
+
+	t.Run("remove device", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/devices/pump", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/v2/devices/pump", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/devices/pump", "").Code)
+	})
+}
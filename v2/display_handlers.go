@@ -0,0 +1,349 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/display"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/i2c"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type displayHandler struct {
+	mgr display.Manager
+}
+
+func (dh *displayHandler) addDisplay(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addDisplay() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var displayDesc displayConfigPointer
+	err := json.Unmarshal(data, &displayDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if displayDesc.Name == nil || displayDesc.Type == nil {
+		logrus.Error("No proper display description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect display description")
+		return
+	}
+
+	err = dh.mgr.Add(*displayDesc.Name, displayDesc.toConfig())
+	if err != nil {
+		logrus.Warning("Display creation error:", err)
+		writeDisplayError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *displayHandler) deleteDisplay(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteDisplay() handler")
+	name := mux.Vars(req)["name"]
+
+	err := dh.mgr.Remove(name)
+	if err != nil {
+		logrus.Errorf("Failed to remove display '%s': %v\n", name, err)
+		writeDisplayError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *displayHandler) getDisplay(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getDisplay() handler")
+	name := mux.Vars(req)["name"]
+
+	dsp, err := dh.mgr.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to get display '%s': %v\n", name, err)
+		writeDisplayError(wr, err)
+		return
+	}
+	writeJSON(wr, displayStatusToJSON(dsp.Status()))
+}
+
+func (dh *displayHandler) getAllDisplays(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllDisplays() handler")
+	displays := dh.mgr.List()
+	if len(displays) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]displayStatus, 0, len(displays))
+	for _, st := range displays {
+		result = append(result, displayStatusToJSON(st))
+	}
+	writeJSON(wr, result)
+}
+
+// writeText puts text at cursor position, cursor is moved first if column and row are given
+func (dh *displayHandler) writeText(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("writeText() handler")
+	name := mux.Vars(req)["name"]
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData displayTextPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Text == nil || (requestData.Column == nil) != (requestData.Row == nil) {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	dsp, err := dh.mgr.Get(name)
+	if err == nil && requestData.Column != nil {
+		err = dsp.SetCursor(*requestData.Column, *requestData.Row)
+	}
+	if err == nil {
+		err = dsp.Write(*requestData.Text)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to write text on display '%s': %v\n", name, err)
+		writeDisplayError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *displayHandler) setCursor(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setCursor() handler")
+	name := mux.Vars(req)["name"]
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData displayTextPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Column == nil || requestData.Row == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	dsp, err := dh.mgr.Get(name)
+	if err == nil {
+		err = dsp.SetCursor(*requestData.Column, *requestData.Row)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to set cursor of display '%s': %v\n", name, err)
+		writeDisplayError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *displayHandler) clearDisplay(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("clearDisplay() handler")
+	name := mux.Vars(req)["name"]
+
+	dsp, err := dh.mgr.Get(name)
+	if err == nil {
+		err = dsp.Clear()
+	}
+	if err != nil {
+		logrus.Errorf("Failed to clear display '%s': %v\n", name, err)
+		writeDisplayError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (dh *displayHandler) defineChar(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("defineChar() handler")
+	name := mux.Vars(req)["name"]
+	index, ok := intParam(wr, req, "index")
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData displayCharPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Pattern == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	pattern := make([]byte, 0, len(requestData.Pattern))
+	for _, row := range requestData.Pattern {
+		if row < 0 || row > 0xff {
+			logrus.Debug("Invalid pattern row:", row)
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid pattern")
+			return
+		}
+		pattern = append(pattern, byte(row))
+	}
+
+	dsp, err := dh.mgr.Get(name)
+	if err == nil {
+		err = dsp.DefineChar(index, pattern)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to define character of display '%s': %v\n", name, err)
+		writeDisplayError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func writeDisplayError(wr http.ResponseWriter, err error) {
+	switch err {
+	case display.ErrNotFound:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case gpio.ErrExternalPin, gpio.ErrPinClaimed:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case display.ErrInvalidConfig, display.ErrInvalidName, display.ErrInvalidValue, display.ErrAlreadyExists,
+		display.ErrNotSupported, gpio.ErrInvalidPin, gpio.ErrInvalidDirection, i2c.ErrInvalidBus, bus.ErrNack:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func displayStatusToJSON(st display.Status) displayStatus {
+	result := displayStatus{
+		Name:    st.Name,
+		Type:    display.KindToString(st.Config.Kind),
+		Columns: st.Config.Columns,
+		Rows:    st.Config.Rows,
+		Lines:   st.Lines,
+		Column:  st.Column,
+		Row:     st.Row,
+	}
+	switch {
+	case st.Config.Kind == display.SevenSegment:
+		result.SegmentPins = st.Config.SegmentPins
+		result.DigitPins = st.Config.DigitPins
+		result.CommonAnode = st.Config.CommonAnode
+	case st.Config.I2CAddress != 0:
+		result.I2CBus = &st.Config.I2CBus
+		result.I2CAddress = &st.Config.I2CAddress
+	default:
+		result.RSPin = &st.Config.RSPin
+		result.EnablePin = &st.Config.EnablePin
+		result.DataPins = st.Config.DataPins
+		if st.Config.BacklightPin != display.NoPin {
+			result.BacklightPin = &st.Config.BacklightPin
+		}
+	}
+	return result
+}
+
+// displayConfigPointer describes character LCD (connected with pins or I2C backpack) or 7-segment display
+type displayConfigPointer struct {
+	Name         *string `json:"name"`
+	Type         *string `json:"type"`
+	Columns      *int    `json:"columns"`
+	Rows         *int    `json:"rows"`
+	RSPin        *int    `json:"rs_pin"`
+	EnablePin    *int    `json:"enable_pin"`
+	DataPins     []int   `json:"data_pins"`
+	BacklightPin *int    `json:"backlight_pin"`
+	I2CBus       *int    `json:"i2c_bus"`
+	I2CAddress   *uint16 `json:"i2c_address"`
+	SegmentPins  []int   `json:"segment_pins"`
+	DigitPins    []int   `json:"digit_pins"`
+	CommonAnode  *bool   `json:"common_anode"`
+}
+
+func (dc displayConfigPointer) toConfig() display.Config {
+	cfg := display.Config{
+		Kind:         display.StringToKind(*dc.Type),
+		RSPin:        display.NoPin,
+		EnablePin:    display.NoPin,
+		DataPins:     dc.DataPins,
+		BacklightPin: display.NoPin,
+		SegmentPins:  dc.SegmentPins,
+		DigitPins:    dc.DigitPins,
+	}
+	if dc.Columns != nil {
+		cfg.Columns = *dc.Columns
+	}
+	if dc.Rows != nil {
+		cfg.Rows = *dc.Rows
+	}
+	if dc.RSPin != nil {
+		cfg.RSPin = *dc.RSPin
+	}
+	if dc.EnablePin != nil {
+		cfg.EnablePin = *dc.EnablePin
+	}
+	if dc.BacklightPin != nil {
+		cfg.BacklightPin = *dc.BacklightPin
+	}
+	if dc.I2CBus != nil {
+		cfg.I2CBus = *dc.I2CBus
+	}
+	if dc.I2CAddress != nil {
+		cfg.I2CAddress = *dc.I2CAddress
+	}
+	if dc.CommonAnode != nil {
+		cfg.CommonAnode = *dc.CommonAnode
+	}
+	return cfg
+}
+
+type displayStatus struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Columns      int      `json:"columns"`
+	Rows         int      `json:"rows"`
+	RSPin        *int     `json:"rs_pin,omitempty"`
+	EnablePin    *int     `json:"enable_pin,omitempty"`
+	DataPins     []int    `json:"data_pins,omitempty"`
+	BacklightPin *int     `json:"backlight_pin,omitempty"`
+	I2CBus       *int     `json:"i2c_bus,omitempty"`
+	I2CAddress   *uint16  `json:"i2c_address,omitempty"`
+	SegmentPins  []int    `json:"segment_pins,omitempty"`
+	DigitPins    []int    `json:"digit_pins,omitempty"`
+	CommonAnode  bool     `json:"common_anode,omitempty"`
+	Lines        []string `json:"lines"`
+	Column       int      `json:"column"`
+	Row          int      `json:"row"`
+}
+
+type displayTextPointer struct {
+	Text   *string `json:"text"`
+	Column *int    `json:"column"`
+	Row    *int    `json:"row"`
+}
+
+// displayCharPointer describes custom character, each of 8 rows uses 5 lowest bits
+type displayCharPointer struct {
+	Pattern []int `json:"pattern"`
+}
@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/display"
+	"github.com/markamdev/repico/i2c"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayHandlers(t *testing.T) {
+	i2cCtrl := i2c.CreateController(t.TempDir())
+	i2cCtrl.AddBus(1, i2c.CreateFakeBus(map[uint16]i2c.Device{0x27: &i2c.Registers{}}))
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachDisplayHandlers(subRtr, display.CreateManager(&controllerStub{}, i2cCtrl))
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list displays - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/displays", "").Code)
+	})
+
+	t.Run("add display", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays", `{"name":"panel"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays", `{"name":"panel","type":"oled"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays",
+			`{"name":"panel","type":"hd44780","columns":16,"rows":2,"i2c_bus":1,"i2c_address":38}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/displays",
+			`{"name":"panel","type":"hd44780","columns":16,"rows":2,"i2c_bus":1,"i2c_address":39}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays",
+			`{"name":"other","type":"hd44780","columns":16,"rows":2,"i2c_bus":1,"i2c_address":39}`).Code)
+	})
+
+	t.Run("write text", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/displays/panel/text", `{"text":"Temp:"}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/displays/panel/text", `{"text":"21°C","column":12,"row":1}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/text", `{"text":"x","column":1}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/text", `{"text":"€"}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("POST", "/v2/displays/other/text", `{"text":"x"}`).Code)
+
+		res := serve("GET", "/v2/displays/panel", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"name":"panel","type":"hd44780","columns":16,"rows":2,"i2c_bus":1,"i2c_address":39,
+			"lines":["Temp:           ","            21°C"],"column":16,"row":1}`, res.Body.String())
+	})
+
+	t.Run("cursor and clear", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/displays/panel/cursor", `{"column":3,"row":1}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/cursor", `{"column":16,"row":1}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/cursor", `{"column":1}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/displays/panel/clear", "").Code)
+
+		res := serve("GET", "/v2/displays", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"name":"panel","type":"hd44780","columns":16,"rows":2,"i2c_bus":1,"i2c_address":39,
+			"lines":["                ","                "],"column":0,"row":0}]`, res.Body.String())
+	})
+
+	t.Run("define character", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/displays/panel/chars/0", `{"pattern":[0,10,31,31,14,4,0,0]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/chars/8", `{"pattern":[0,10,31,31,14,4,0,0]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/chars/0", `{"pattern":[0,10,31]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/chars/0", `{"pattern":[256,0,0,0,0,0,0,0]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/displays/panel/chars/0", `{}`).Code)
+	})
+
+	t.Run("remove display", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/displays/panel", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/v2/displays/panel", "").Code)
+	})
+}
@@ -0,0 +1,227 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/encoder"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type encoderHandler struct {
+	mgr encoder.Manager
+}
+
+func (eh *encoderHandler) addEncoder(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addEncoder() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var encoderDesc encoderConfigPointer
+	err := json.Unmarshal(data, &encoderDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if encoderDesc.Name == nil || encoderDesc.PinA == nil || encoderDesc.PinB == nil ||
+		(encoderDesc.Min == nil) != (encoderDesc.Max == nil) {
+		logrus.Error("No proper encoder description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect encoder description")
+		return
+	}
+
+	err = eh.mgr.Add(*encoderDesc.Name, encoderDesc.toConfig())
+	if err != nil {
+		logrus.Warning("Encoder creation error:", err)
+		writeEncoderError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (eh *encoderHandler) deleteEncoder(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteEncoder() handler")
+	name := mux.Vars(req)["name"]
+
+	err := eh.mgr.Remove(name)
+	if err != nil {
+		logrus.Errorf("Failed to remove encoder '%s': %v\n", name, err)
+		writeEncoderError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (eh *encoderHandler) setEncoder(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setEncoder() handler")
+	name := mux.Vars(req)["name"]
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData encoderPositionPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Position == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	enc, err := eh.mgr.Get(name)
+	if err == nil {
+		err = enc.SetPosition(*requestData.Position)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to set encoder '%s' position: %v\n", name, err)
+		writeEncoderError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (eh *encoderHandler) getEncoder(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getEncoder() handler")
+	name := mux.Vars(req)["name"]
+
+	enc, err := eh.mgr.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to get encoder '%s': %v\n", name, err)
+		writeEncoderError(wr, err)
+		return
+	}
+
+	buffer, err := json.Marshal(encoderStatusToJSON(enc.Status()))
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func (eh *encoderHandler) getAllEncoders(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllEncoders() handler")
+	encoders := eh.mgr.List()
+	if len(encoders) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]encoderStatus, 0, len(encoders))
+	for _, st := range encoders {
+		result = append(result, encoderStatusToJSON(st))
+	}
+
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Error when marshalling encoder data:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func writeEncoderError(wr http.ResponseWriter, err error) {
+	switch err {
+	case encoder.ErrNotFound:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case gpio.ErrExternalPin, gpio.ErrPinClaimed:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case encoder.ErrInvalidConfig, encoder.ErrInvalidName, encoder.ErrInvalidPosition, encoder.ErrAlreadyExists,
+		gpio.ErrInvalidPin, gpio.ErrInvalidDirection:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func encoderStatusToJSON(st encoder.Status) encoderStatus {
+	result := encoderStatus{
+		Name:           st.Name,
+		PinA:           st.Config.PinA,
+		PinB:           st.Config.PinB,
+		StepsPerDetent: st.Config.StepsPerDetent,
+		Wrap:           st.Config.Wrap,
+		Position:       st.Position,
+		Pressed:        st.Pressed,
+	}
+	if st.Config.ButtonPin != encoder.NoPin {
+		result.ButtonPin = &st.Config.ButtonPin
+	}
+	if st.Config.Limited {
+		result.Min = &st.Config.Min
+		result.Max = &st.Config.Max
+	}
+	return result
+}
+
+// encoderConfigPointer describes encoder with button debounce given in milliseconds
+type encoderConfigPointer struct {
+	Name           *string `json:"name"`
+	PinA           *int    `json:"pin_a"`
+	PinB           *int    `json:"pin_b"`
+	ButtonPin      *int    `json:"button_pin"`
+	ButtonActive   *int    `json:"button_active"`
+	ButtonDebounce *int    `json:"button_debounce"`
+	StepsPerDetent *int    `json:"steps_per_detent"`
+	Min            *int    `json:"min"`
+	Max            *int    `json:"max"`
+	Wrap           *bool   `json:"wrap"`
+}
+
+func (ec encoderConfigPointer) toConfig() encoder.Config {
+	cfg := encoder.Config{PinA: *ec.PinA, PinB: *ec.PinB, ButtonPin: encoder.NoPin}
+	if ec.ButtonPin != nil {
+		cfg.ButtonPin = *ec.ButtonPin
+	}
+	if ec.ButtonActive != nil {
+		cfg.ButtonActive = *ec.ButtonActive
+	}
+	if ec.ButtonDebounce != nil {
+		cfg.ButtonDebounce = time.Duration(*ec.ButtonDebounce) * time.Millisecond
+	}
+	if ec.StepsPerDetent != nil {
+		cfg.StepsPerDetent = *ec.StepsPerDetent
+	}
+	if ec.Min != nil && ec.Max != nil {
+		cfg.Limited = true
+		cfg.Min = *ec.Min
+		cfg.Max = *ec.Max
+	}
+	if ec.Wrap != nil {
+		cfg.Wrap = *ec.Wrap
+	}
+	return cfg
+}
+
+type encoderStatus struct {
+	Name           string `json:"name"`
+	PinA           int    `json:"pin_a"`
+	PinB           int    `json:"pin_b"`
+	ButtonPin      *int   `json:"button_pin,omitempty"`
+	StepsPerDetent int    `json:"steps_per_detent"`
+	Min            *int   `json:"min,omitempty"`
+	Max            *int   `json:"max,omitempty"`
+	Wrap           bool   `json:"wrap"`
+	Position       int    `json:"position"`
+	Pressed        bool   `json:"pressed"`
+}
+
+type encoderPositionPointer struct {
+	Position *int `json:"position"`
+}
@@ -0,0 +1,106 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+// streamEvents sends pin and device events as server-sent events stream
+// Pins given with 'pin' query parameters are watched for the stream lifetime,
+// without them events of all already watched pins are sent
+func (gh *gpioHandler) streamEvents(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("streamEvents() handler")
+	flusher, ok := wr.(http.Flusher)
+	if !ok {
+		logrus.Errorln("Streaming not supported by response writer")
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+
+	pins := map[int]bool{}
+	for _, param := range req.URL.Query()["pin"] {
+		pin, err := strconv.Atoi(param)
+		if err != nil || pin < 0 {
+			logrus.Errorln("Invalid pin number:", param)
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid pin selection")
+			return
+		}
+		pins[pin] = true
+	}
+
+	all := gh.ctrl.SubscribeAll()
+	defer gh.ctrl.UnsubscribeAll(all)
+
+	for pin := range pins {
+		events, err := gh.ctrl.Subscribe(pin)
+		if err != nil {
+			logrus.Errorf("Failed to subscribe pin '%d': %v\n", pin, err)
+			if err == gpio.ErrNotExported || err == gpio.ErrInvalidDirection {
+				server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+			} else {
+				server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+			}
+			return
+		}
+		defer gh.ctrl.Unsubscribe(pin, events)
+		// events are received through 'all' subscription, this one only keeps pin watched
+		go func() {
+			for range events {
+			}
+		}()
+	}
+
+	wr.Header().Set("Content-Type", "text/event-stream")
+	wr.Header().Set("Cache-Control", "no-cache")
+	wr.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			logrus.Debugln("Event stream closed by client")
+			return
+		case event, ok := <-all:
+			if !ok {
+				return
+			}
+			if event.Device == "" && len(pins) > 0 && !pins[event.Pin] {
+				continue
+			}
+			buffer, err := json.Marshal(eventToJSON(event))
+			if err != nil {
+				logrus.Errorln("Failed to marshal event:", err)
+				continue
+			}
+			_, err = fmt.Fprintf(wr, "data: %s\n\n", buffer)
+			if err != nil {
+				logrus.Debugln("Event stream writing failed:", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func eventToJSON(event gpio.Event) eventData {
+	result := eventData{Value: event.Value, Time: event.Time.Format(time.RFC3339Nano), Device: event.Device}
+	if event.Pin != gpio.NoPin {
+		pin := event.Pin
+		result.Pin = &pin
+	}
+	return result
+}
+
+type eventData struct {
+	Pin    *int   `json:"pin,omitempty"`
+	Device string `json:"device,omitempty"`
+	Value  int    `json:"value"`
+	Time   string `json:"time"`
+}
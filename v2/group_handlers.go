@@ -0,0 +1,199 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/groups"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type groupHandler struct {
+	mgr groups.Manager
+}
+
+func (gh *groupHandler) addGroup(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addGroup() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var groupDesc groupConfigPointer
+	err := json.Unmarshal(data, &groupDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if groupDesc.Name == nil || groupDesc.Pins == nil || groupDesc.Direction == nil {
+		logrus.Error("No proper group description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect group description")
+		return
+	}
+
+	err = gh.mgr.Add(*groupDesc.Name, groupDesc.toConfig())
+	if err != nil {
+		logrus.Warning("Group creation error:", err)
+		writeGroupError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *groupHandler) deleteGroup(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteGroup() handler")
+	name := mux.Vars(req)["name"]
+
+	err := gh.mgr.Remove(name)
+	if err != nil {
+		logrus.Errorf("Failed to remove group '%s': %v\n", name, err)
+		writeGroupError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *groupHandler) setGroup(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setGroup() handler")
+	name := mux.Vars(req)["name"]
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData groupValuePointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Value == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	grp, err := gh.mgr.Get(name)
+	if err == nil {
+		err = grp.Write(*requestData.Value)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to write group '%s': %v\n", name, err)
+		writeGroupError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *groupHandler) getGroup(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getGroup() handler")
+	name := mux.Vars(req)["name"]
+
+	grp, err := gh.mgr.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to get group '%s': %v\n", name, err)
+		writeGroupError(wr, err)
+		return
+	}
+
+	value, err := grp.Read()
+	if err != nil {
+		logrus.Errorf("Failed to read group '%s': %v\n", name, err)
+		writeGroupError(wr, err)
+		return
+	}
+
+	result := groupStatusToJSON(grp.Status())
+	result.Value = &value
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func (gh *groupHandler) getAllGroups(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllGroups() handler")
+	list := gh.mgr.List()
+	if len(list) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]groupStatus, 0, len(list))
+	for _, st := range list {
+		result = append(result, groupStatusToJSON(st))
+	}
+
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Error when marshalling group data:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func writeGroupError(wr http.ResponseWriter, err error) {
+	if _, ok := err.(gpio.PinErrors); ok {
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch err {
+	case groups.ErrNotFound:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case groups.ErrInvalidConfig, groups.ErrInvalidName, groups.ErrInvalidValue, groups.ErrAlreadyExists,
+		gpio.ErrInvalidPin, gpio.ErrInvalidDirection, gpio.ErrNotExported:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	case gpio.ErrUnstableValue, gpio.ErrExternalPin, gpio.ErrPinClaimed:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func groupStatusToJSON(st groups.Status) groupStatus {
+	return groupStatus{
+		Name:      st.Name,
+		Pins:      st.Config.Pins,
+		Order:     groups.OrderToString(st.Config.Order),
+		Direction: gpio.DirectionToString(st.Config.Direction),
+	}
+}
+
+// groupConfigPointer describes pin group, order is LSB first if not given
+type groupConfigPointer struct {
+	Name      *string `json:"name"`
+	Pins      []int   `json:"pins"`
+	Order     *string `json:"order"`
+	Direction *string `json:"direction"`
+}
+
+func (gc groupConfigPointer) toConfig() groups.Config {
+	cfg := groups.Config{Pins: gc.Pins, Order: groups.LSBFirst, Direction: gpio.StringToDirection(*gc.Direction)}
+	if gc.Order != nil {
+		cfg.Order = groups.StringToOrder(*gc.Order)
+	}
+	return cfg
+}
+
+type groupStatus struct {
+	Name      string  `json:"name"`
+	Pins      []int   `json:"pins"`
+	Order     string  `json:"order"`
+	Direction string  `json:"direction"`
+	Value     *uint32 `json:"value,omitempty"`
+}
+
+type groupValuePointer struct {
+	Value *uint32 `json:"value"`
+}
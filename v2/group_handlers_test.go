@@ -0,0 +1,96 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/gpio/gpiotest"
+	"github.com/markamdev/repico/groups"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupHandlers(t *testing.T) {
+	ctrl := gpiotest.NewFake()
+	require.NoError(t, ctrl.ExportPin(9, gpio.Input))
+	ctrl.ExportExternal(10, gpio.Output)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachGroupHandlers(subRtr, groups.CreateManager(ctrl))
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list groups - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/groups", "").Code)
+	})
+
+	t.Run("add group", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/groups", ``).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/groups", `{"name":"leds","pins":[1,2]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/groups",
+			`{"name":"leds","pins":[1,1],"direction":"out"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/groups",
+			`{"name":"leds","pins":[1,2],"order":"middle","direction":"out"}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/groups",
+			`{"name":"leds","pins":[1,2,3],"order":"msb","direction":"out"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/groups",
+			`{"name":"leds","pins":[4],"direction":"out"}`).Code)
+	})
+
+	t.Run("add group - pins used by others", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/groups",
+			`{"name":"bus","pins":[5,9],"direction":"out"}`).Code)
+		assert.Equal(t, http.StatusConflict, serve("POST", "/v2/groups",
+			`{"name":"bus","pins":[5,10],"direction":"out"}`).Code)
+		assert.NotContains(t, ctrl.Exported(), 5)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/groups",
+			`{"name":"switches","pins":[8,9],"direction":"in"}`).Code)
+	})
+
+	t.Run("set group", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/groups/leds", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/groups/leds", `{"value":8}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("PATCH", "/v2/groups/unknown", `{"value":1}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/groups/switches", `{"value":1}`).Code)
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/groups/leds", `{"value":6}`).Code)
+		assert.Equal(t, []int{1, 1, 0}, []int{ctrl.Value(1), ctrl.Value(2), ctrl.Value(3)})
+	})
+
+	t.Run("get group", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/groups/unknown", "").Code)
+
+		res := serve("GET", "/v2/groups/leds", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"name":"leds","pins":[1,2,3],"order":"msb","direction":"out","value":6}`, res.Body.String())
+
+		ctrl.Drive(9, 1)
+		res = serve("GET", "/v2/groups/switches", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"name":"switches","pins":[8,9],"order":"lsb","direction":"in","value":2}`, res.Body.String())
+	})
+
+	t.Run("list groups", func(t *testing.T) {
+		res := serve("GET", "/v2/groups", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"name":"leds","pins":[1,2,3],"order":"msb","direction":"out"},
+			{"name":"switches","pins":[8,9],"order":"lsb","direction":"in"}]`, res.Body.String())
+	})
+
+	t.Run("delete group", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/v2/groups/unknown", "").Code)
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/groups/leds", "").Code)
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/groups/switches", "").Code)
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/groups", "").Code)
+		assert.Equal(t, map[int]gpio.Direction{9: gpio.Input, 10: gpio.Output}, ctrl.Exported())
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/markamdev/repico/gpio"
@@ -12,8 +13,51 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultTriggerPulse   = 10 * time.Microsecond
+	defaultTriggerTimeout = 100 * time.Millisecond
+)
+
+// ownerHeader identifies client, pins claimed by client can be changed only with this header given
+const ownerHeader = "X-Repico-Owner"
+
+// ownerExternal is reported as owner of pins exported by other process and not adopted yet
+const ownerExternal = "external"
+
 type gpioHandler struct {
-	ctrl gpio.Controller
+	ctrl gpio.FullController
+}
+
+// pinController is a part of controller used on behalf of client
+type pinController interface {
+	gpio.Controller
+	gpio.Bulk
+	gpio.Counters
+}
+
+// controller returns controller acting on behalf of owner given in request (if any),
+// on invalid owner error response is written and false returned
+func (gh *gpioHandler) controller(wr http.ResponseWriter, req *http.Request) (pinController, bool) {
+	owner, ok := requestOwner(wr, req)
+	if !ok {
+		return nil, false
+	}
+	if owner == "" {
+		return gh.ctrl, true
+	}
+	return gh.ctrl.WithOwner(owner), true
+}
+
+// requestOwner returns client identity given in owner header, identities reserved for drivers
+// running in daemon (ex. "device:relay1") are refused so clients can not act on behalf of drivers
+func requestOwner(wr http.ResponseWriter, req *http.Request) (string, bool) {
+	owner := req.Header.Get(ownerHeader)
+	if gpio.IsDriverOwner(owner) {
+		logrus.Warnf("Owner '%s' reserved for drivers used by client\n", owner)
+		server.WriteMessage(wr, http.StatusForbidden, "owner reserved for drivers")
+		return "", false
+	}
+	return owner, true
 }
 
 func (gh *gpioHandler) addPin(wr http.ResponseWriter, req *http.Request) {
@@ -45,13 +89,39 @@ func (gh *gpioHandler) addPin(wr http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	filter, filtered := pinDesc.filter()
+	if filtered && gpio.StringToDirection(*pinDesc.Direction) != gpio.Input {
+		logrus.Error("Filter requested for non-input pin")
+		server.WriteMessage(wr, http.StatusBadRequest, "filter allowed only for input pins")
+		return
+	}
+	if filter.Debounce < 0 || filter.MinStable < 0 {
+		logrus.Error("Negative filter duration requested")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid filter duration")
+		return
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
 	err = gh.ctrl.ExportPin(*pinDesc.Pin, gpio.StringToDirection(*pinDesc.Direction))
+	if err == nil && filtered {
+		err = gh.ctrl.SetFilter(*pinDesc.Pin, filter)
+		if err != nil {
+			logrus.Error("Failed to set pin filter:", err)
+			ctrl.UnexportPin(*pinDesc.Pin)
+		}
+	}
 
 	switch err {
 	case nil:
 		wr.WriteHeader(http.StatusOK)
 	case gpio.ErrNotImplemented:
 		server.WriteMessage(wr, http.StatusNotImplemented, "not implemented")
+	case gpio.ErrExternalPin:
+		logrus.Warning("GPIO pin exporting error:", err)
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
 	case gpio.ErrAlreadyExported:
 		fallthrough
 	case gpio.ErrInvalidDirection:
@@ -76,11 +146,17 @@ func (gh *gpioHandler) deletePin(wr http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err = gh.ctrl.UnexportPin(pin)
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err = ctrl.UnexportPin(pin)
 	if err != nil {
 		logrus.Errorf("Failed to unexport pin '%d': %v\n", pin, err.Error())
 		if err == gpio.ErrNotExported {
 			server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+		} else if err == gpio.ErrPinClaimed || err == gpio.ErrExternalPin {
+			server.WriteMessage(wr, http.StatusConflict, err.Error())
 		} else {
 			server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
 		}
@@ -127,12 +203,21 @@ func (gh *gpioHandler) setPin(wr http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err = gh.ctrl.SetValue(pin, *requestData.Value)
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err = ctrl.SetValue(pin, *requestData.Value)
 	if err == nil {
 		wr.WriteHeader(http.StatusOK)
 		return
 	}
 
+	if err == gpio.ErrPinClaimed || err == gpio.ErrExternalPin {
+		logrus.Warnln("Pin claimed by other owner or exported by other process")
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+		return
+	}
 	if err == gpio.ErrInvalidDirection {
 		logrus.Warnln("Invalid pin direction")
 		server.WriteMessage(wr, http.StatusBadRequest, "invalid pin direction")
@@ -163,6 +248,8 @@ func (gh *gpioHandler) getPin(wr http.ResponseWriter, req *http.Request) {
 		logrus.Errorf("Failed to get pin '%d' value: %v\n", pin, err.Error())
 		if err == gpio.ErrNotExported {
 			server.WriteMessage(wr, http.StatusBadRequest, "pin not exported")
+		} else if err == gpio.ErrUnstableValue {
+			server.WriteMessage(wr, http.StatusConflict, err.Error())
 		} else {
 			server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
 		}
@@ -181,6 +268,42 @@ func (gh *gpioHandler) getPin(wr http.ResponseWriter, req *http.Request) {
 	server.WriteResponse(wr, http.StatusOK, buffer)
 }
 
+func (gh *gpioHandler) togglePin(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("togglePin() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	val, err := ctrl.Toggle(pin)
+	if err != nil {
+		logrus.Errorf("Failed to toggle pin '%d': %v\n", pin, err)
+		switch err {
+		case gpio.ErrNotExported:
+			server.WriteMessage(wr, http.StatusBadRequest, "pin not exported")
+		case gpio.ErrInvalidDirection:
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid pin direction")
+		case gpio.ErrPinClaimed, gpio.ErrExternalPin:
+			server.WriteMessage(wr, http.StatusConflict, err.Error())
+		default:
+			server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		}
+		return
+	}
+
+	buffer, err := json.Marshal(pinValue{Pin: pin, Value: val})
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
 func (gh *gpioHandler) getAllPins(wr http.ResponseWriter, req *http.Request) {
 	logrus.Debugln("getAllPins() handler")
 	pins, err := gh.ctrl.ListExportedPins()
@@ -194,9 +317,47 @@ func (gh *gpioHandler) getAllPins(wr http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	external, err := gh.ctrl.ListExternalPins()
+	if err != nil {
+		logrus.Errorln("Error when listing external GPIO pins:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	isExternal := make(map[int]bool, len(external))
+	for _, pin := range external {
+		isExternal[pin] = true
+	}
+
+	claims := gh.ctrl.ListClaims()
 	result := make([]pinConfig, 0, len(pins))
 	for k, v := range pins {
-		result = append(result, pinConfig{Pin: k, Direction: gpio.DirectionToString(v)})
+		pc := pinConfig{Pin: k, Direction: gpio.DirectionToString(v),
+			Owners: claims[k].Owners, Exclusive: claims[k].Exclusive}
+		if isExternal[k] {
+			pc.Owner = ownerExternal
+		}
+		result = append(result, pc)
+	}
+
+	if req.URL.Query().Get("values") == "true" {
+		pinList := make([]int, 0, len(result))
+		for _, pc := range result {
+			pinList = append(pinList, pc.Pin)
+		}
+		values, err := gh.ctrl.GetValues(pinList)
+		failed, _ := err.(gpio.PinErrors)
+		if err != nil && failed == nil {
+			logrus.Errorln("Error when reading GPIO pin values:", err)
+			server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+			return
+		}
+		for i := range result {
+			if pinErr, exists := failed[result[i].Pin]; exists {
+				result[i].Error = pinErr.Error()
+			} else if value, exists := values[result[i].Pin]; exists {
+				result[i].Value = &value
+			}
+		}
 	}
 
 	buffer, err := json.Marshal(result)
@@ -213,15 +374,398 @@ func (gh *gpioHandler) getAllPins(wr http.ResponseWriter, req *http.Request) {
 	server.WriteResponse(wr, http.StatusOK, buffer)
 }
 
+func (gh *gpioHandler) setPins(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setPins() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	requestData := []pinValuePointer{}
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(requestData) == 0 {
+		logrus.Debug("Empty pin list in request")
+		server.WriteMessage(wr, http.StatusBadRequest, "empty pin list")
+		return
+	}
+
+	values := make(map[int]int, len(requestData))
+	for _, pv := range requestData {
+		if pv.Pin == nil || pv.Value == nil {
+			logrus.Debug("Incomplete request data")
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+			return
+		}
+		if _, duplicated := values[*pv.Pin]; duplicated {
+			logrus.Debugf("Pin '%d' given more than once\n", *pv.Pin)
+			server.WriteMessage(wr, http.StatusBadRequest, "duplicated pin "+strconv.Itoa(*pv.Pin))
+			return
+		}
+		values[*pv.Pin] = *pv.Value
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err = ctrl.SetValues(values)
+	failed, _ := err.(gpio.PinErrors)
+	if err != nil && failed == nil {
+		logrus.Errorln("Failed to set pin values:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+
+	code := http.StatusOK
+	result := make([]pinResult, 0, len(requestData))
+	for _, pv := range requestData {
+		res := pinResult{Pin: *pv.Pin, Value: *pv.Value}
+		if pinErr, exists := failed[*pv.Pin]; exists {
+			res.Error = pinErr.Error()
+			if pinErr == gpio.ErrNotExported || pinErr == gpio.ErrInvalidDirection || pinErr == gpio.ErrInvalidValue {
+				if code == http.StatusOK {
+					code = http.StatusBadRequest
+				}
+			} else if pinErr == gpio.ErrPinClaimed || pinErr == gpio.ErrExternalPin {
+				if code != http.StatusInternalServerError {
+					code = http.StatusConflict
+				}
+			} else {
+				code = http.StatusInternalServerError
+			}
+		}
+		result = append(result, res)
+	}
+	if code != http.StatusOK {
+		logrus.Warnln("Failed to set pin values:", err)
+	}
+
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, code, buffer)
+}
+
+func (gh *gpioHandler) startCounter(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("startCounter() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var counterDesc counterConfigPointer
+	err := json.Unmarshal(data, &counterDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if counterDesc.Edge == nil {
+		logrus.Error("No edge given in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect counter description")
+		return
+	}
+
+	window := time.Duration(0)
+	if counterDesc.Window != nil {
+		window = time.Duration(*counterDesc.Window) * time.Millisecond
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err = ctrl.StartCounter(pin, gpio.StringToEdge(*counterDesc.Edge), window)
+	if err != nil {
+		logrus.Errorf("Failed to start counter on pin '%d': %v\n", pin, err)
+		writeCounterError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *gpioHandler) stopCounter(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("stopCounter() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err := ctrl.StopCounter(pin)
+	if err != nil {
+		logrus.Errorf("Failed to stop counter on pin '%d': %v\n", pin, err)
+		writeCounterError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *gpioHandler) getCounter(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getCounter() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	state, err := gh.ctrl.GetCounter(pin)
+	if err != nil {
+		logrus.Errorf("Failed to get counter of pin '%d': %v\n", pin, err)
+		writeCounterError(wr, err)
+		return
+	}
+	writeCounterState(wr, state)
+}
+
+func (gh *gpioHandler) resetCounter(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("resetCounter() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	state, err := ctrl.ResetCounter(pin)
+	if err != nil {
+		logrus.Errorf("Failed to reset counter of pin '%d': %v\n", pin, err)
+		writeCounterError(wr, err)
+		return
+	}
+	writeCounterState(wr, state)
+}
+
+func writeCounterState(wr http.ResponseWriter, state gpio.CounterState) {
+	respData := counterState{
+		Pin:    state.Pin,
+		Edge:   gpio.EdgeToString(state.Edge),
+		Window: state.Window.Milliseconds(),
+		Count:  state.Count,
+		Rate:   state.Rate,
+	}
+	buffer, err := json.Marshal(respData)
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func writeCounterError(wr http.ResponseWriter, err error) {
+	switch err {
+	case gpio.ErrNotRunning:
+		server.WriteMessage(wr, http.StatusNotFound, "counter not running")
+	case gpio.ErrPinClaimed, gpio.ErrExternalPin:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case gpio.ErrNotExported, gpio.ErrInvalidDirection, gpio.ErrInvalidEdge, gpio.ErrInvalidValue,
+		gpio.ErrAlreadyRunning:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func (gh *gpioHandler) startCapture(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("startCapture() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err := ctrl.StartCapture(pin)
+	if err != nil {
+		logrus.Errorf("Failed to start capture on pin '%d': %v\n", pin, err)
+		writeCaptureError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *gpioHandler) stopCapture(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("stopCapture() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	err := ctrl.StopCapture(pin)
+	if err != nil {
+		logrus.Errorf("Failed to stop capture on pin '%d': %v\n", pin, err)
+		writeCaptureError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (gh *gpioHandler) getCapture(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getCapture() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	state, err := gh.ctrl.GetCapture(pin)
+	if err != nil {
+		logrus.Errorf("Failed to get capture of pin '%d': %v\n", pin, err)
+		writeCaptureError(wr, err)
+		return
+	}
+
+	respData := captureState{
+		Pin:       state.Pin,
+		High:      pulseStatsToJSON(state.High),
+		Low:       pulseStatsToJSON(state.Low),
+		DutyCycle: state.DutyCycle,
+	}
+	buffer, err := json.Marshal(respData)
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func (gh *gpioHandler) triggerCapture(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("triggerCapture() handler")
+	pin, ok := intParam(wr, req, "pin")
+	if !ok {
+		return
+	}
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var triggerDesc triggerConfigPointer
+	err := json.Unmarshal(data, &triggerDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if triggerDesc.Trigger == nil {
+		logrus.Error("No trigger pin given in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect trigger description")
+		return
+	}
+
+	pulse := defaultTriggerPulse
+	if triggerDesc.Pulse != nil {
+		pulse = time.Duration(*triggerDesc.Pulse) * time.Microsecond
+	}
+	timeout := defaultTriggerTimeout
+	if triggerDesc.Timeout != nil {
+		timeout = time.Duration(*triggerDesc.Timeout) * time.Millisecond
+	}
+
+	ctrl, ok := gh.controller(wr, req)
+	if !ok {
+		return
+	}
+	width, err := ctrl.TriggerAndMeasure(*triggerDesc.Trigger, pin, pulse, timeout)
+	if err != nil {
+		logrus.Errorf("Failed to measure echo on pin '%d': %v\n", pin, err)
+		writeCaptureError(wr, err)
+		return
+	}
+
+	buffer, err := json.Marshal(echoWidth{Pin: pin, Trigger: *triggerDesc.Trigger, Width: width.Microseconds()})
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func writeCaptureError(wr http.ResponseWriter, err error) {
+	switch err {
+	case gpio.ErrNotRunning:
+		server.WriteMessage(wr, http.StatusNotFound, "capture not running")
+	case gpio.ErrTimeout:
+		server.WriteMessage(wr, http.StatusGatewayTimeout, "no echo received")
+	case gpio.ErrPinClaimed, gpio.ErrExternalPin:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case gpio.ErrNotExported, gpio.ErrInvalidDirection, gpio.ErrInvalidValue, gpio.ErrAlreadyRunning:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func pulseStatsToJSON(stats gpio.PulseStats) pulseStats {
+	return pulseStats{
+		Count:   stats.Count,
+		Last:    stats.Last.Microseconds(),
+		Min:     stats.Min.Microseconds(),
+		Max:     stats.Max.Microseconds(),
+		Average: stats.Average.Microseconds(),
+	}
+}
+
 // TODO re-think this and maybe unify structures used in code
+// Debounce and MinStable are given in milliseconds
 type pinConfigPointer struct {
 	Pin       *int    `json:"pin"`
 	Direction *string `json:"direction"`
+	Debounce  *int    `json:"debounce"`
+	MinStable *int    `json:"min_stable"`
+}
+
+func (pc pinConfigPointer) filter() (gpio.Filter, bool) {
+	result := gpio.Filter{}
+	if pc.Debounce != nil {
+		result.Debounce = time.Duration(*pc.Debounce) * time.Millisecond
+	}
+	if pc.MinStable != nil {
+		result.MinStable = time.Duration(*pc.MinStable) * time.Millisecond
+	}
+	return result, pc.Debounce != nil || pc.MinStable != nil
 }
 
 type pinConfig struct {
-	Pin       int    `json:"pin"`
-	Direction string `json:"direction"`
+	Pin       int      `json:"pin"`
+	Direction string   `json:"direction"`
+	Owner     string   `json:"owner,omitempty"`
+	Owners    []string `json:"owners,omitempty"`
+	Exclusive bool     `json:"exclusive,omitempty"`
+	Value     *int     `json:"value,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type pinResult struct {
+	Pin   int    `json:"pin"`
+	Value int    `json:"value"`
+	Error string `json:"error,omitempty"`
 }
 
 type pinValue struct {
@@ -233,3 +777,46 @@ type pinValuePointer struct {
 	Pin   *int `json:"pin"`
 	Value *int `json:"value"`
 }
+
+// counterConfigPointer describes counter with rate window given in milliseconds
+type counterConfigPointer struct {
+	Edge   *string `json:"edge"`
+	Window *int    `json:"window"`
+}
+
+type counterState struct {
+	Pin    int     `json:"pin"`
+	Edge   string  `json:"edge"`
+	Window int64   `json:"window"`
+	Count  uint64  `json:"count"`
+	Rate   float64 `json:"rate"`
+}
+
+// pulseStats describes captured pulses with widths given in microseconds
+type pulseStats struct {
+	Count   int   `json:"count"`
+	Last    int64 `json:"last"`
+	Min     int64 `json:"min"`
+	Max     int64 `json:"max"`
+	Average int64 `json:"average"`
+}
+
+type captureState struct {
+	Pin       int        `json:"pin"`
+	High      pulseStats `json:"high"`
+	Low       pulseStats `json:"low"`
+	DutyCycle float64    `json:"duty_cycle"`
+}
+
+// triggerConfigPointer describes trigger pulse (in microseconds) and echo timeout (in milliseconds)
+type triggerConfigPointer struct {
+	Trigger *int `json:"trigger"`
+	Pulse   *int `json:"pulse"`
+	Timeout *int `json:"timeout"`
+}
+
+type echoWidth struct {
+	Pin     int   `json:"pin"`
+	Trigger int   `json:"trigger"`
+	Width   int64 `json:"width"`
+}
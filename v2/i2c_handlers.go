@@ -0,0 +1,227 @@
+package v2
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/i2c"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type i2cHandler struct {
+	ctrl i2c.Controller
+}
+
+func (ih *i2cHandler) getAllBuses(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllBuses() handler")
+	buses, err := ih.ctrl.ListBuses()
+	if err != nil {
+		logrus.Errorln("Failed to list I2C buses:", err)
+		writeI2CError(wr, err)
+		return
+	}
+	if len(buses) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]i2cBus, 0, len(buses))
+	for _, number := range buses {
+		result = append(result, i2cBus{Bus: number})
+	}
+	writeJSON(wr, result)
+}
+
+func (ih *i2cHandler) scanBus(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("scanBus() handler")
+	number, ok := intParam(wr, req, "bus")
+	if !ok {
+		return
+	}
+
+	devices, err := ih.ctrl.Scan(number)
+	if err != nil {
+		logrus.Errorf("Failed to scan I2C bus '%d': %v\n", number, err)
+		writeI2CError(wr, err)
+		return
+	}
+	writeJSON(wr, i2cScan{Bus: number, Devices: devices})
+}
+
+func (ih *i2cHandler) readRegister(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("readRegister() handler")
+	number, addr, ok := i2cParams(wr, req)
+	if !ok {
+		return
+	}
+
+	reg, err := strconv.ParseUint(req.URL.Query().Get("register"), 0, 8)
+	if err != nil {
+		logrus.Errorln("Invalid register:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid register")
+		return
+	}
+	length := 1
+	if value := req.URL.Query().Get("length"); value != "" {
+		length, err = strconv.Atoi(value)
+		if err != nil {
+			logrus.Errorln("Invalid length:", err)
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid length")
+			return
+		}
+	}
+
+	data, err := ih.ctrl.ReadRegister(number, addr, byte(reg), length)
+	if err != nil {
+		logrus.Errorf("Failed to read register of device '%d/0x%02x': %v\n", number, addr, err)
+		writeI2CError(wr, err)
+		return
+	}
+	register := int(reg)
+	writeJSON(wr, i2cData{Bus: number, Address: addr, Register: &register, Data: hex.EncodeToString(data)})
+}
+
+func (ih *i2cHandler) writeRegister(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("writeRegister() handler")
+	number, addr, ok := i2cParams(wr, req)
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData i2cRegisterPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Register == nil || requestData.Data == nil || *requestData.Register < 0 || *requestData.Register > 0xff {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+	payload, err := hex.DecodeString(*requestData.Data)
+	if err != nil {
+		logrus.Errorln("Invalid data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid data")
+		return
+	}
+
+	err = ih.ctrl.WriteRegister(number, addr, byte(*requestData.Register), payload)
+	if err != nil {
+		logrus.Errorf("Failed to write register of device '%d/0x%02x': %v\n", number, addr, err)
+		writeI2CError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (ih *i2cHandler) transfer(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("transfer() handler")
+	number, addr, ok := i2cParams(wr, req)
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData i2cTransferPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var w []byte
+	if requestData.Write != nil {
+		w, err = hex.DecodeString(*requestData.Write)
+		if err != nil {
+			logrus.Errorln("Invalid data:", err)
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid data")
+			return
+		}
+	}
+	var r []byte
+	if requestData.Read != nil {
+		if *requestData.Read < 0 || *requestData.Read > i2c.MaxLength {
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid read length")
+			return
+		}
+		r = make([]byte, *requestData.Read)
+	}
+
+	err = ih.ctrl.Tx(number, addr, w, r)
+	if err != nil {
+		logrus.Errorf("Failed to transfer data with device '%d/0x%02x': %v\n", number, addr, err)
+		writeI2CError(wr, err)
+		return
+	}
+	writeJSON(wr, i2cData{Bus: number, Address: addr, Data: hex.EncodeToString(r)})
+}
+
+// i2cParams returns bus number and device address (decimal or 0x prefixed hex) from path
+func i2cParams(wr http.ResponseWriter, req *http.Request) (int, uint16, bool) {
+	number, ok := intParam(wr, req, "bus")
+	if !ok {
+		return 0, 0, false
+	}
+	addr, err := strconv.ParseUint(mux.Vars(req)["addr"], 0, 16)
+	if err != nil {
+		logrus.Errorln("Invalid device address:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid address selection")
+		return 0, 0, false
+	}
+	return number, uint16(addr), true
+}
+
+func writeI2CError(wr http.ResponseWriter, err error) {
+	switch err {
+	case i2c.ErrInvalidBus, bus.ErrNack:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case i2c.ErrInvalidAddress, i2c.ErrInvalidValue:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	case bus.ErrTimeout:
+		server.WriteMessage(wr, http.StatusGatewayTimeout, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+type i2cBus struct {
+	Bus int `json:"bus"`
+}
+
+type i2cScan struct {
+	Bus     int      `json:"bus"`
+	Devices []uint16 `json:"devices"`
+}
+
+// i2cData describes data read from device, encoded as hex string
+type i2cData struct {
+	Bus      int    `json:"bus"`
+	Address  uint16 `json:"address"`
+	Register *int   `json:"register,omitempty"`
+	Data     string `json:"data"`
+}
+
+type i2cRegisterPointer struct {
+	Register *int    `json:"register"`
+	Data     *string `json:"data"`
+}
+
+// i2cTransferPointer describes raw transaction: hex encoded data written and number of bytes read
+type i2cTransferPointer struct {
+	Write *string `json:"write"`
+	Read  *int    `json:"read"`
+}
@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/i2c"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestI2CHandlers(t *testing.T) {
+	sensor := &i2c.Registers{}
+	sensor.Set(0x10, 0xab)
+	ctrl := i2c.CreateController(t.TempDir())
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachI2CHandlers(subRtr, ctrl)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list buses - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/i2c", "").Code)
+	})
+
+	ctrl.AddBus(1, i2c.CreateFakeBus(map[uint16]i2c.Device{0x48: sensor}))
+
+	t.Run("list buses", func(t *testing.T) {
+		res := serve("GET", "/v2/i2c", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"bus":1}]`, res.Body.String())
+	})
+
+	t.Run("scan bus", func(t *testing.T) {
+		res := serve("GET", "/v2/i2c/1", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"bus":1,"devices":[72]}`, res.Body.String())
+
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/i2c/3", "").Code)
+	})
+
+	t.Run("read register", func(t *testing.T) {
+		res := serve("GET", "/v2/i2c/1/0x48?register=0x10&length=2", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"bus":1,"address":72,"register":16,"data":"ab00"}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("GET", "/v2/i2c/1/0x48", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("GET", "/v2/i2c/1/xyz?register=1", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/i2c/1/0x49?register=1", "").Code)
+	})
+
+	t.Run("write register", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/i2c/1/72", `{"register":32,"data":"0102"}`).Code)
+		assert.Equal(t, byte(2), sensor.Get(33))
+
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/i2c/1/72", `{"register":32,"data":"xyz"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/i2c/1/72", `{"data":"01"}`).Code)
+	})
+
+	t.Run("raw transaction", func(t *testing.T) {
+		res := serve("POST", "/v2/i2c/1/72", `{"write":"20","read":2}`)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"bus":1,"address":72,"data":"0102"}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/i2c/1/72", `{}`).Code)
+	})
+}
@@ -0,0 +1,136 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/leds"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type ledHandler struct {
+	ctrl leds.Controller
+}
+
+func (lh *ledHandler) getAllLEDs(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllLEDs() handler")
+	list, err := lh.ctrl.ListLEDs()
+	if err != nil {
+		logrus.Errorln("Failed to list LEDs:", err)
+		writeLEDError(wr, err)
+		return
+	}
+	if len(list) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]ledState, 0, len(list))
+	for _, led := range list {
+		result = append(result, newLEDState(led))
+	}
+	writeJSON(wr, result)
+}
+
+func (lh *ledHandler) getLED(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getLED() handler")
+	name := mux.Vars(req)["name"]
+
+	led, err := lh.ctrl.GetLED(name)
+	if err != nil {
+		logrus.Errorf("Failed to read LED '%s': %v\n", name, err)
+		writeLEDError(wr, err)
+		return
+	}
+	writeJSON(wr, newLEDState(led))
+}
+
+// setLED sets brightness first as setting it to 0 disables trigger
+func (lh *ledHandler) setLED(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setLED() handler")
+	name := mux.Vars(req)["name"]
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData ledStatePointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	timer := requestData.DelayOn != nil || requestData.DelayOff != nil
+	if requestData.Brightness == nil && requestData.Trigger == nil && !timer {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+	if timer && (requestData.DelayOn == nil || requestData.DelayOff == nil ||
+		(requestData.Trigger != nil && *requestData.Trigger != leds.TriggerTimer)) {
+		logrus.Debug("Incomplete timer data")
+		server.WriteMessage(wr, http.StatusBadRequest, "delay_on and delay_off require timer trigger")
+		return
+	}
+
+	if requestData.Brightness != nil {
+		err = lh.ctrl.SetBrightness(name, *requestData.Brightness)
+	}
+	if err == nil && timer {
+		err = lh.ctrl.SetTimer(name, time.Duration(*requestData.DelayOn)*time.Millisecond,
+			time.Duration(*requestData.DelayOff)*time.Millisecond)
+	} else if err == nil && requestData.Trigger != nil {
+		err = lh.ctrl.SetTrigger(name, *requestData.Trigger)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to set LED '%s': %v\n", name, err)
+		writeLEDError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func writeLEDError(wr http.ResponseWriter, err error) {
+	switch err {
+	case leds.ErrInvalidLED:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case leds.ErrInvalidValue, leds.ErrInvalidTrigger:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func newLEDState(led leds.LED) ledState {
+	result := ledState{Name: led.Name, Brightness: led.Brightness, MaxBrightness: led.MaxBrightness,
+		Trigger: led.Trigger, Triggers: led.Triggers}
+	if led.Trigger == leds.TriggerTimer {
+		on := int(led.DelayOn / time.Millisecond)
+		off := int(led.DelayOff / time.Millisecond)
+		result.DelayOn = &on
+		result.DelayOff = &off
+	}
+	return result
+}
+
+// ledState describes LED with timer delays given in milliseconds
+type ledState struct {
+	Name          string   `json:"name"`
+	Brightness    int      `json:"brightness"`
+	MaxBrightness int      `json:"max_brightness"`
+	Trigger       string   `json:"trigger"`
+	Triggers      []string `json:"triggers"`
+	DelayOn       *int     `json:"delay_on,omitempty"`
+	DelayOff      *int     `json:"delay_off,omitempty"`
+}
+
+type ledStatePointer struct {
+	Brightness *int    `json:"brightness"`
+	Trigger    *string `json:"trigger"`
+	DelayOn    *int    `json:"delay_on"`
+	DelayOff   *int    `json:"delay_off"`
+}
@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/leds"
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLEDHandlers(t *testing.T) {
+	basePath := t.TempDir()
+	ctrl := leds.CreateController(basePath)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachLEDHandlers(subRtr, ctrl)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+	readFile := func(name string) string {
+		return sysfstest.ReadFile(t, filepath.Join(basePath, "ACT", name))
+	}
+
+	t.Run("list LEDs - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/leds", "").Code)
+	})
+
+	attributes := map[string]string{
+		"brightness":     "0",
+		"max_brightness": "255",
+		"trigger":        "none [mmc0] timer heartbeat",
+		"delay_on":       "500",
+		"delay_off":      "500",
+	}
+	sysfstest.CreateDir(t, filepath.Join(basePath, "ACT"), attributes)
+
+	t.Run("list LEDs", func(t *testing.T) {
+		res := serve("GET", "/v2/leds", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"name":"ACT","brightness":0,"max_brightness":255,"trigger":"mmc0",
+			"triggers":["none","mmc0","timer","heartbeat"]}]`, res.Body.String())
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/leds/PWR", "").Code)
+	})
+
+	t.Run("set LED", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/leds/ACT", `{"brightness":255,"trigger":"heartbeat"}`).Code)
+		assert.Equal(t, "255", readFile("brightness"))
+		assert.Equal(t, "heartbeat", readFile("trigger"))
+
+		// kernel lists all triggers after one of them is written
+		sysfstest.WriteFile(t, filepath.Join(basePath, "ACT", "trigger"), attributes["trigger"])
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/leds/ACT", `{"delay_on":100,"delay_off":900}`).Code)
+		assert.Equal(t, "timer", readFile("trigger"))
+		assert.Equal(t, "100", readFile("delay_on"))
+		assert.Equal(t, "900", readFile("delay_off"))
+	})
+
+	t.Run("set LED - invalid data", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/leds/ACT", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/leds/ACT", `{"brightness":256}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/leds/ACT", `{"trigger":"disk-activity"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/leds/ACT", `{"delay_on":100}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/leds/ACT", `{"trigger":"none","delay_on":1,"delay_off":1}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("PATCH", "/v2/leds/PWR", `{"brightness":1}`).Code)
+	})
+}
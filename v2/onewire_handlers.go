@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/onewire"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type oneWireHandler struct {
+	ctrl onewire.Controller
+}
+
+// getAllDevices returns all devices with readings of temperature sensors
+func (oh *oneWireHandler) getAllDevices(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllDevices() handler")
+	devices, err := oh.ctrl.ListDevices()
+	if err != nil {
+		logrus.Errorln("Failed to list 1-Wire devices:", err)
+		writeOneWireError(wr, err)
+		return
+	}
+	if len(devices) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]oneWireDevice, 0, len(devices))
+	for _, dev := range devices {
+		item := newOneWireDevice(dev)
+		if onewire.IsTemperatureSensor(dev.Family) {
+			reading, err := oh.ctrl.ReadTemperature(dev.ID)
+			if err != nil {
+				logrus.Warnf("Failed to read 1-Wire device '%s': %v\n", dev.ID, err)
+				item.Error = err.Error()
+			} else {
+				item.setReading(reading)
+			}
+		}
+		result = append(result, item)
+	}
+	writeJSON(wr, result)
+}
+
+func (oh *oneWireHandler) getDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getDevice() handler")
+	id := mux.Vars(req)["id"]
+
+	reading, err := oh.ctrl.ReadTemperature(id)
+	if err != nil {
+		logrus.Errorf("Failed to read 1-Wire device '%s': %v\n", id, err)
+		writeOneWireError(wr, err)
+		return
+	}
+	result := newOneWireDevice(reading.Device)
+	result.setReading(reading)
+	writeJSON(wr, result)
+}
+
+func writeOneWireError(wr http.ResponseWriter, err error) {
+	switch err {
+	case onewire.ErrInvalidDevice:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case onewire.ErrNotSupported:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	case onewire.ErrCRC:
+		server.WriteMessage(wr, http.StatusServiceUnavailable, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func newOneWireDevice(dev onewire.Device) oneWireDevice {
+	return oneWireDevice{ID: dev.ID, Family: fmt.Sprintf("%02x", dev.Family), Name: onewire.FamilyToString(dev.Family)}
+}
+
+func (od *oneWireDevice) setReading(reading onewire.Reading) {
+	temperature := reading.Temperature
+	od.Temperature = &temperature
+	od.Time = reading.Time.Format(time.RFC3339Nano)
+}
+
+// oneWireDevice describes device with temperature (in Celsius degrees) and time of reading
+type oneWireDevice struct {
+	ID          string   `json:"id"`
+	Family      string   `json:"family"`
+	Name        string   `json:"name,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	Time        string   `json:"time,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
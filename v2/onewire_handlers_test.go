@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/onewire"
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneWireHandlers(t *testing.T) {
+	basePath := t.TempDir()
+	ctrl := onewire.CreateController(basePath, time.Minute)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachOneWireHandlers(subRtr, ctrl)
+
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(""))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list devices - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/onewire").Code)
+	})
+
+	slaves := map[string]string{
+		"28-0316a2793aff": "72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n",
+		"10-000802b4c3d1": "72 01 4b 46 7f ff 0e 10 57 : crc=ff NO\n72 01 4b 46 7f ff 0e 10 57 t=0\n",
+		"01-00001a2b3c4d": "",
+	}
+	for id, content := range slaves {
+		sysfstest.CreateDir(t, filepath.Join(basePath, id), nil)
+		if content != "" {
+			sysfstest.WriteFile(t, filepath.Join(basePath, id, "w1_slave"), content)
+		}
+	}
+
+	t.Run("list devices", func(t *testing.T) {
+		res := serve("GET", "/v2/onewire")
+		assert.Equal(t, http.StatusOK, res.Code)
+		body := res.Body.String()
+		assert.Contains(t, body, `{"id":"01-00001a2b3c4d","family":"01"}`)
+		assert.Contains(t, body, `{"id":"10-000802b4c3d1","family":"10","name":"DS18S20","error":"CRC check failed"}`)
+		assert.Contains(t, body, `"id":"28-0316a2793aff","family":"28","name":"DS18B20","temperature":23.125`)
+	})
+
+	t.Run("get device", func(t *testing.T) {
+		res := serve("GET", "/v2/onewire/28-0316a2793aff")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"temperature":23.125`)
+
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/onewire/28-000000000000").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("GET", "/v2/onewire/01-00001a2b3c4d").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, serve("GET", "/v2/onewire/10-000802b4c3d1").Code)
+	})
+}
@@ -0,0 +1,264 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/markamdev/repico/pwm"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type pwmHandler struct {
+	ctrl pwm.Controller
+}
+
+func (ph *pwmHandler) addChannel(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addChannel() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var chanDesc pwmChannelPointer
+	err := json.Unmarshal(data, &chanDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if chanDesc.Chip == nil || chanDesc.Channel == nil {
+		logrus.Error("No proper channel description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect channel description")
+		return
+	}
+
+	err = ph.ctrl.ExportChannel(*chanDesc.Chip, *chanDesc.Channel)
+	if err != nil {
+		logrus.Warning("PWM channel exporting error:", err)
+		writePWMError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (ph *pwmHandler) deleteChannel(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteChannel() handler")
+	chip, channel, ok := pwmParams(wr, req)
+	if !ok {
+		return
+	}
+
+	err := ph.ctrl.UnexportChannel(chip, channel)
+	if err != nil {
+		logrus.Errorf("Failed to unexport channel '%d/%d': %v\n", chip, channel, err)
+		writePWMError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (ph *pwmHandler) setChannel(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setChannel() handler")
+	chip, channel, ok := pwmParams(wr, req)
+	if !ok {
+		return
+	}
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	requestData := pwmStatePointer{}
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if requestData.Period == nil && requestData.DutyCycle == nil &&
+		requestData.Polarity == nil && requestData.Enabled == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	err = ph.applyState(chip, channel, requestData)
+	if err != nil {
+		logrus.Errorln("Failed to configure PWM channel:", err)
+		writePWMError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+// applyState sets requested parameters in order accepted by kernel driver
+func (ph *pwmHandler) applyState(chip, channel int, requested pwmStatePointer) error {
+	if requested.Enabled != nil && !*requested.Enabled {
+		err := ph.ctrl.SetEnabled(chip, channel, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if requested.Polarity != nil {
+		err := ph.ctrl.SetPolarity(chip, channel, pwm.StringToPolarity(*requested.Polarity))
+		if err != nil {
+			return err
+		}
+	}
+
+	if requested.Period != nil && requested.DutyCycle != nil {
+		current, err := ph.ctrl.GetState(chip, channel)
+		if err != nil {
+			return err
+		}
+		// period cannot be shorter than duty cycle so order depends on current values
+		if time.Duration(*requested.Period) < current.DutyCycle {
+			err = ph.ctrl.SetDutyCycle(chip, channel, time.Duration(*requested.DutyCycle))
+			if err == nil {
+				err = ph.ctrl.SetPeriod(chip, channel, time.Duration(*requested.Period))
+			}
+		} else {
+			err = ph.ctrl.SetPeriod(chip, channel, time.Duration(*requested.Period))
+			if err == nil {
+				err = ph.ctrl.SetDutyCycle(chip, channel, time.Duration(*requested.DutyCycle))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	} else if requested.Period != nil {
+		err := ph.ctrl.SetPeriod(chip, channel, time.Duration(*requested.Period))
+		if err != nil {
+			return err
+		}
+	} else if requested.DutyCycle != nil {
+		err := ph.ctrl.SetDutyCycle(chip, channel, time.Duration(*requested.DutyCycle))
+		if err != nil {
+			return err
+		}
+	}
+
+	if requested.Enabled != nil && *requested.Enabled {
+		return ph.ctrl.SetEnabled(chip, channel, true)
+	}
+	return nil
+}
+
+func (ph *pwmHandler) getChannel(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getChannel() handler")
+	chip, channel, ok := pwmParams(wr, req)
+	if !ok {
+		return
+	}
+
+	state, err := ph.ctrl.GetState(chip, channel)
+	if err != nil {
+		logrus.Errorf("Failed to get channel '%d/%d' state: %v\n", chip, channel, err)
+		writePWMError(wr, err)
+		return
+	}
+
+	buffer, err := json.Marshal(stateToJSON(state))
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func (ph *pwmHandler) getAllChannels(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllChannels() handler")
+	channels, err := ph.ctrl.ListExportedChannels()
+	if err != nil {
+		logrus.Errorln("Error when listing PWM channels:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	if len(channels) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]pwmState, 0, len(channels))
+	for _, ch := range channels {
+		state, err := ph.ctrl.GetState(ch.Chip, ch.Channel)
+		if err != nil {
+			logrus.Errorln("Error when reading PWM channel state:", err)
+			server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+			return
+		}
+		result = append(result, stateToJSON(state))
+	}
+
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Error when marshalling channel data:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func pwmParams(wr http.ResponseWriter, req *http.Request) (int, int, bool) {
+	chip, ok := intParam(wr, req, "chip")
+	if !ok {
+		return 0, 0, false
+	}
+	channel, ok := intParam(wr, req, "channel")
+	if !ok {
+		return 0, 0, false
+	}
+	return chip, channel, true
+}
+
+func writePWMError(wr http.ResponseWriter, err error) {
+	switch err {
+	case pwm.ErrNotImplemented:
+		server.WriteMessage(wr, http.StatusNotImplemented, "not implemented")
+	case pwm.ErrInvalidChip, pwm.ErrInvalidChannel, pwm.ErrAlreadyExported, pwm.ErrNotExported,
+		pwm.ErrInvalidValue, pwm.ErrInvalidPolarity:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func stateToJSON(state pwm.State) pwmState {
+	return pwmState{
+		Chip:      state.Chip,
+		Channel:   state.Channel.Channel,
+		Period:    int64(state.Period),
+		DutyCycle: int64(state.DutyCycle),
+		Polarity:  pwm.PolarityToString(state.Polarity),
+		Enabled:   state.Enabled,
+	}
+}
+
+type pwmChannelPointer struct {
+	Chip    *int `json:"chip"`
+	Channel *int `json:"channel"`
+}
+
+// pwmState describes PWM channel with period and duty cycle given in nanoseconds
+type pwmState struct {
+	Chip      int    `json:"chip"`
+	Channel   int    `json:"channel"`
+	Period    int64  `json:"period"`
+	DutyCycle int64  `json:"duty_cycle"`
+	Polarity  string `json:"polarity"`
+	Enabled   bool   `json:"enabled"`
+}
+
+type pwmStatePointer struct {
+	Period    *int64  `json:"period"`
+	DutyCycle *int64  `json:"duty_cycle"`
+	Polarity  *string `json:"polarity"`
+	Enabled   *bool   `json:"enabled"`
+}
@@ -0,0 +1,196 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/pwm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPWMHandlers(t *testing.T) {
+	ctrl := &pwmControllerStub{}
+	body := &bodyStub{}
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachPWMHandlers(subRtr, ctrl)
+
+	t.Run("list all channels - no content", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/pwm", body)
+		resRecorder := httptest.NewRecorder()
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusNoContent, resRecorder.Code)
+	})
+
+	t.Run("list all channels - 1 channel returned", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/pwm", body)
+		resRecorder := httptest.NewRecorder()
+		ctrl.channelsToReturn = []pwm.Channel{{Chip: 0, Channel: 1}}
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		list := []pwmState{}
+		json.Unmarshal(resRecorder.Body.Bytes(), &list)
+		assert.Equal(t, 1, len(list))
+	})
+
+	t.Run("add channel - invalid body (no channel)", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/pwm", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{ \"chip\" : 0 }")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("add channel - already exported", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/pwm", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{ \"chip\" : 0, \"channel\" : 1 }")
+		ctrl.errorToReturn = pwm.ErrAlreadyExported
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("add channel - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/pwm", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{ \"chip\" : 0, \"channel\" : 1 }")
+		ctrl.errorToReturn = nil
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+	})
+
+	t.Run("get channel - invalid path", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/pwm/0", body)
+		resRecorder := httptest.NewRecorder()
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusNotFound, resRecorder.Code)
+	})
+
+	t.Run("get channel - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/pwm/0/1", body)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.stateToReturn = pwm.State{Period: time.Millisecond, DutyCycle: 500 * time.Microsecond,
+			Polarity: pwm.Normal, Enabled: true}
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		var respData pwmState
+		assert.NoError(t, json.Unmarshal(resRecorder.Body.Bytes(), &respData))
+		assert.Equal(t, pwmState{Chip: 0, Channel: 1, Period: 1000000, DutyCycle: 500000,
+			Polarity: "normal", Enabled: true}, respData)
+	})
+
+	t.Run("set channel - missing values", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/v2/pwm/0/1", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{}")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("set channel - period shorter than duty cycle", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/v2/pwm/0/1", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"period\" : 100000, \"duty_cycle\" : 50000, \"enabled\" : true}")
+		ctrl.calls = nil
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		assert.Equal(t, []string{"duty", "period", "enable"}, ctrl.calls)
+	})
+
+	t.Run("set channel - not exported", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/v2/pwm/0/1", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"enabled\" : false}")
+		ctrl.errorToReturn = pwm.ErrNotExported
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("delete channel - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/v2/pwm/0/1", body)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.errorToReturn = nil
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+	})
+}
+
+type pwmControllerStub struct {
+	errorToReturn    error
+	stateToReturn    pwm.State
+	channelsToReturn []pwm.Channel
+	calls            []string
+}
+
+func (ps *pwmControllerStub) ExportChannel(chip, channel int) error {
+	return ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) UnexportChannel(chip, channel int) error {
+	return ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) ListExportedChannels() ([]pwm.Channel, error) {
+	return ps.channelsToReturn, ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) GetState(chip, channel int) (pwm.State, error) {
+	state := ps.stateToReturn
+	state.Channel = pwm.Channel{Chip: chip, Channel: channel}
+	return state, ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) SetPeriod(chip, channel int, period time.Duration) error {
+	ps.calls = append(ps.calls, "period")
+	return ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) SetDutyCycle(chip, channel int, duty time.Duration) error {
+	ps.calls = append(ps.calls, "duty")
+	return ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) SetPolarity(chip, channel int, polarity pwm.Polarity) error {
+	ps.calls = append(ps.calls, "polarity")
+	return ps.errorToReturn
+}
+
+func (ps *pwmControllerStub) SetEnabled(chip, channel int, enabled bool) error {
+	ps.calls = append(ps.calls, "enable")
+	return ps.errorToReturn
+}
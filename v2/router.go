@@ -2,19 +2,237 @@ package v2
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/devices"
+	"github.com/markamdev/repico/display"
+	"github.com/markamdev/repico/encoder"
 	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/groups"
+	"github.com/markamdev/repico/i2c"
+	"github.com/markamdev/repico/iio"
+	"github.com/markamdev/repico/leds"
+	"github.com/markamdev/repico/onewire"
+	"github.com/markamdev/repico/pwm"
+	"github.com/markamdev/repico/sensors"
+	"github.com/markamdev/repico/serial"
+	"github.com/markamdev/repico/servo"
+	"github.com/markamdev/repico/spi"
+	"github.com/markamdev/repico/stepper"
 	"github.com/sirupsen/logrus"
 )
 
-func AttachHandlers(handler *mux.Router, controller gpio.Controller) {
+func AttachHandlers(handler *mux.Router, controller gpio.FullController) {
 	logrus.Traceln("v2.AttachHandlers()")
 
 	hndlr := gpioHandler{ctrl: controller}
 
 	handler.HandleFunc("/gpio", hndlr.addPin).Methods("POST")
 	handler.HandleFunc("/gpio", hndlr.getAllPins).Methods("GET")
+	handler.HandleFunc("/gpio", hndlr.setPins).Methods("PATCH")
+	handler.HandleFunc("/gpio/capabilities", hndlr.getCapabilities).Methods("GET")
 
 	handler.HandleFunc("/gpio/{pin:[0-9]+}", hndlr.deletePin).Methods("DELETE")
 	handler.HandleFunc("/gpio/{pin:[0-9]+}", hndlr.setPin).Methods("PATCH")
 	handler.HandleFunc("/gpio/{pin:[0-9]+}", hndlr.getPin).Methods("GET")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/toggle", hndlr.togglePin).Methods("POST")
+
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/claim", hndlr.claimPin).Methods("POST")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/claim", hndlr.releasePin).Methods("DELETE")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/adopt", hndlr.adoptPin).Methods("POST")
+
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/counter", hndlr.startCounter).Methods("POST")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/counter", hndlr.getCounter).Methods("GET")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/counter", hndlr.stopCounter).Methods("DELETE")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/counter/reset", hndlr.resetCounter).Methods("POST")
+
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/capture", hndlr.startCapture).Methods("POST")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/capture", hndlr.getCapture).Methods("GET")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/capture", hndlr.stopCapture).Methods("DELETE")
+	handler.HandleFunc("/gpio/{pin:[0-9]+}/capture/trigger", hndlr.triggerCapture).Methods("POST")
+
+	handler.HandleFunc("/events", hndlr.streamEvents).Methods("GET")
+}
+
+func AttachPWMHandlers(handler *mux.Router, controller pwm.Controller) {
+	logrus.Traceln("v2.AttachPWMHandlers()")
+
+	hndlr := pwmHandler{ctrl: controller}
+
+	handler.HandleFunc("/pwm", hndlr.addChannel).Methods("POST")
+	handler.HandleFunc("/pwm", hndlr.getAllChannels).Methods("GET")
+
+	handler.HandleFunc("/pwm/{chip:[0-9]+}/{channel:[0-9]+}", hndlr.deleteChannel).Methods("DELETE")
+	handler.HandleFunc("/pwm/{chip:[0-9]+}/{channel:[0-9]+}", hndlr.setChannel).Methods("PATCH")
+	handler.HandleFunc("/pwm/{chip:[0-9]+}/{channel:[0-9]+}", hndlr.getChannel).Methods("GET")
+}
+
+func AttachServoHandlers(handler *mux.Router, manager servo.Manager) {
+	logrus.Traceln("v2.AttachServoHandlers()")
+
+	hndlr := servoHandler{mgr: manager}
+
+	handler.HandleFunc("/servos", hndlr.addServo).Methods("POST")
+	handler.HandleFunc("/servos", hndlr.getAllServos).Methods("GET")
+
+	handler.HandleFunc("/servos/{name}", hndlr.deleteServo).Methods("DELETE")
+	handler.HandleFunc("/servos/{name}", hndlr.setServo).Methods("PATCH")
+	handler.HandleFunc("/servos/{name}", hndlr.getServo).Methods("GET")
+}
+
+func AttachStepperHandlers(handler *mux.Router, manager stepper.Manager) {
+	logrus.Traceln("v2.AttachStepperHandlers()")
+
+	hndlr := stepperHandler{mgr: manager}
+
+	handler.HandleFunc("/steppers", hndlr.addStepper).Methods("POST")
+	handler.HandleFunc("/steppers", hndlr.getAllSteppers).Methods("GET")
+
+	handler.HandleFunc("/steppers/{name}", hndlr.deleteStepper).Methods("DELETE")
+	handler.HandleFunc("/steppers/{name}", hndlr.moveStepper).Methods("PATCH")
+	handler.HandleFunc("/steppers/{name}", hndlr.getStepper).Methods("GET")
+
+	handler.HandleFunc("/steppers/{name}/home", hndlr.homeStepper).Methods("POST")
+	handler.HandleFunc("/steppers/{name}/stop", hndlr.stopStepper).Methods("POST")
+}
+
+func AttachEncoderHandlers(handler *mux.Router, manager encoder.Manager) {
+	logrus.Traceln("v2.AttachEncoderHandlers()")
+
+	hndlr := encoderHandler{mgr: manager}
+
+	handler.HandleFunc("/encoders", hndlr.addEncoder).Methods("POST")
+	handler.HandleFunc("/encoders", hndlr.getAllEncoders).Methods("GET")
+
+	handler.HandleFunc("/encoders/{name}", hndlr.deleteEncoder).Methods("DELETE")
+	handler.HandleFunc("/encoders/{name}", hndlr.setEncoder).Methods("PATCH")
+	handler.HandleFunc("/encoders/{name}", hndlr.getEncoder).Methods("GET")
+}
+
+func AttachGroupHandlers(handler *mux.Router, manager groups.Manager) {
+	logrus.Traceln("v2.AttachGroupHandlers()")
+
+	hndlr := groupHandler{mgr: manager}
+
+	handler.HandleFunc("/groups", hndlr.addGroup).Methods("POST")
+	handler.HandleFunc("/groups", hndlr.getAllGroups).Methods("GET")
+
+	handler.HandleFunc("/groups/{name}", hndlr.deleteGroup).Methods("DELETE")
+	handler.HandleFunc("/groups/{name}", hndlr.setGroup).Methods("PATCH")
+	handler.HandleFunc("/groups/{name}", hndlr.getGroup).Methods("GET")
+}
+
+func AttachI2CHandlers(handler *mux.Router, controller i2c.Controller) {
+	logrus.Traceln("v2.AttachI2CHandlers()")
+
+	hndlr := i2cHandler{ctrl: controller}
+
+	handler.HandleFunc("/i2c", hndlr.getAllBuses).Methods("GET")
+	handler.HandleFunc("/i2c/{bus:[0-9]+}", hndlr.scanBus).Methods("GET")
+
+	handler.HandleFunc("/i2c/{bus:[0-9]+}/{addr}", hndlr.readRegister).Methods("GET")
+	handler.HandleFunc("/i2c/{bus:[0-9]+}/{addr}", hndlr.writeRegister).Methods("PATCH")
+	handler.HandleFunc("/i2c/{bus:[0-9]+}/{addr}", hndlr.transfer).Methods("POST")
+}
+
+func AttachSPIHandlers(handler *mux.Router, controller spi.Controller) {
+	logrus.Traceln("v2.AttachSPIHandlers()")
+
+	hndlr := spiHandler{ctrl: controller}
+
+	handler.HandleFunc("/spi", hndlr.getAllDevices).Methods("GET")
+
+	handler.HandleFunc("/spi/{bus:[0-9]+}.{cs:[0-9]+}", hndlr.getDevice).Methods("GET")
+	handler.HandleFunc("/spi/{bus:[0-9]+}.{cs:[0-9]+}", hndlr.setDevice).Methods("PATCH")
+	handler.HandleFunc("/spi/{bus:[0-9]+}.{cs:[0-9]+}/transfer", hndlr.transfer).Methods("POST")
+}
+
+func AttachOneWireHandlers(handler *mux.Router, controller onewire.Controller) {
+	logrus.Traceln("v2.AttachOneWireHandlers()")
+
+	hndlr := oneWireHandler{ctrl: controller}
+
+	handler.HandleFunc("/onewire", hndlr.getAllDevices).Methods("GET")
+	handler.HandleFunc("/onewire/{id}", hndlr.getDevice).Methods("GET")
+}
+
+func AttachAnalogHandlers(handler *mux.Router, controller iio.Controller) {
+	logrus.Traceln("v2.AttachAnalogHandlers()")
+
+	hndlr := analogHandler{ctrl: controller}
+
+	handler.HandleFunc("/analog", hndlr.getAllDevices).Methods("GET")
+	handler.HandleFunc("/analog/{device:[0-9]+}", hndlr.getDevice).Methods("GET")
+	handler.HandleFunc("/analog/{device:[0-9]+}/capture", hndlr.capture).Methods("POST")
+	handler.HandleFunc("/analog/{device:[0-9]+}/{channel}", hndlr.getChannel).Methods("GET")
+}
+
+func AttachSensorsHandlers(handler *mux.Router, controller sensors.Controller) {
+	logrus.Traceln("v2.AttachSensorsHandlers()")
+
+	hndlr := sensorsHandler{ctrl: controller}
+
+	handler.HandleFunc("/thermal", hndlr.getAllZones).Methods("GET")
+	handler.HandleFunc("/thermal/{zone:[0-9]+}", hndlr.getZone).Methods("GET")
+	handler.HandleFunc("/cooling", hndlr.getAllCoolingDevices).Methods("GET")
+
+	handler.HandleFunc("/hwmon", hndlr.getAllChips).Methods("GET")
+	handler.HandleFunc("/hwmon/{chip:[0-9]+}", hndlr.getChip).Methods("GET")
+}
+
+func AttachLEDHandlers(handler *mux.Router, controller leds.Controller) {
+	logrus.Traceln("v2.AttachLEDHandlers()")
+
+	hndlr := ledHandler{ctrl: controller}
+
+	handler.HandleFunc("/leds", hndlr.getAllLEDs).Methods("GET")
+
+	handler.HandleFunc("/leds/{name}", hndlr.setLED).Methods("PATCH")
+	handler.HandleFunc("/leds/{name}", hndlr.getLED).Methods("GET")
+}
+
+func AttachSerialHandlers(handler *mux.Router, controller serial.Controller) {
+	logrus.Traceln("v2.AttachSerialHandlers()")
+
+	hndlr := serialHandler{ctrl: controller}
+
+	handler.HandleFunc("/serial", hndlr.openPort).Methods("POST")
+	handler.HandleFunc("/serial", hndlr.getAllPorts).Methods("GET")
+
+	handler.HandleFunc("/serial/{port}", hndlr.closePort).Methods("DELETE")
+	handler.HandleFunc("/serial/{port}", hndlr.setPort).Methods("PATCH")
+	handler.HandleFunc("/serial/{port}", hndlr.getPort).Methods("GET")
+	handler.HandleFunc("/serial/{port}/request", hndlr.request).Methods("POST")
+	handler.HandleFunc("/serial/{port}/stream", hndlr.streamPort).Methods("GET")
+}
+
+func AttachDisplayHandlers(handler *mux.Router, manager display.Manager) {
+	logrus.Traceln("v2.AttachDisplayHandlers()")
+
+	hndlr := displayHandler{mgr: manager}
+
+	handler.HandleFunc("/displays", hndlr.addDisplay).Methods("POST")
+	handler.HandleFunc("/displays", hndlr.getAllDisplays).Methods("GET")
+
+	handler.HandleFunc("/displays/{name}", hndlr.deleteDisplay).Methods("DELETE")
+	handler.HandleFunc("/displays/{name}", hndlr.getDisplay).Methods("GET")
+
+	handler.HandleFunc("/displays/{name}/text", hndlr.writeText).Methods("POST")
+	handler.HandleFunc("/displays/{name}/cursor", hndlr.setCursor).Methods("POST")
+	handler.HandleFunc("/displays/{name}/clear", hndlr.clearDisplay).Methods("POST")
+	handler.HandleFunc("/displays/{name}/chars/{index:[0-9]+}", hndlr.defineChar).Methods("POST")
+}
+
+func AttachDeviceHandlers(handler *mux.Router, registry devices.Registry) {
+	logrus.Traceln("v2.AttachDeviceHandlers()")
+
+	hndlr := deviceHandler{reg: registry}
+
+	handler.HandleFunc("/devices", hndlr.addDevice).Methods("POST")
+	handler.HandleFunc("/devices", hndlr.getAllDevices).Methods("GET")
+	handler.HandleFunc("/device-types", hndlr.getDeviceTypes).Methods("GET")
+
+	handler.HandleFunc("/devices/{name}", hndlr.deleteDevice).Methods("DELETE")
+	handler.HandleFunc("/devices/{name}", hndlr.configureDevice).Methods("PATCH")
+	handler.HandleFunc("/devices/{name}", hndlr.getDevice).Methods("GET")
+
+	handler.HandleFunc("/devices/{name}/{command}", hndlr.handleCommand).Methods("POST")
 }
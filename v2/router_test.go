@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/markamdev/repico/gpio"
@@ -51,6 +52,72 @@ func TestCreateHandler(t *testing.T) {
 		assert.Equal(t, 2, len(gpioList), "Expected two elements but got %d:", len(gpioList))
 	})
 
+	t.Run("list all pins - with values", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/gpio?values=true", body)
+		resRecorder := httptest.NewRecorder()
+		ctrl.mapToReturn = map[int]gpio.Direction{1: gpio.Input}
+		ctrl.valueToReturn = 1
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		assert.JSONEq(t, `[{"pin":1,"direction":"in","value":1}]`, resRecorder.Body.String())
+	})
+
+	t.Run("list all pins - with values, some failed", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/gpio?values=true", body)
+		resRecorder := httptest.NewRecorder()
+		ctrl.mapToReturn = map[int]gpio.Direction{1: gpio.Input, 2: gpio.Input}
+		ctrl.valueToReturn = 1
+		ctrl.valueErrors = gpio.PinErrors{2: gpio.ErrUnknown}
+
+		hndlr.ServeHTTP(resRecorder, req)
+		ctrl.valueErrors = nil
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		pins := []pinConfig{}
+		json.Unmarshal(resRecorder.Body.Bytes(), &pins)
+		value := 1
+		assert.ElementsMatch(t, []pinConfig{{Pin: 1, Direction: "in", Value: &value},
+			{Pin: 2, Direction: "in", Error: "unknown error"}}, pins)
+	})
+
+	t.Run("set many pins - duplicated pin", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/v2/gpio", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte(`[{"pin" : 1, "value" : 1}, {"pin" : 1, "value" : 0}]`)
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("set many pins - validation failed", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/v2/gpio", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte(`[{"pin" : 1, "value" : 1}, {"pin" : 2, "value" : 0}]`)
+		ctrl.errorToReturn = gpio.PinErrors{2: gpio.ErrNotExported}
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+		assert.JSONEq(t, `[{"pin":1,"value":1},{"pin":2,"value":0,"error":"not exported"}]`, resRecorder.Body.String())
+	})
+
+	t.Run("set many pins - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/v2/gpio", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte(`[{"pin" : 1, "value" : 1}, {"pin" : 2, "value" : 0}]`)
+		ctrl.errorToReturn = nil
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+	})
+
 	t.Run("add pin - empty body", func(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/v2/gpio", body)
 		resRecorder := httptest.NewRecorder()
@@ -95,6 +162,28 @@ func TestCreateHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resRecorder.Code)
 	})
 
+	t.Run("add pin - filter for output pin", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{ \"pin\" : 1, \"direction\" : \"out\", \"debounce\" : 20 }")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("add pin - input with filter", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{ \"pin\" : 1, \"direction\" : \"in\", \"debounce\" : 20, \"min_stable\" : 5 }")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+	})
+
 	t.Run("delete pin - invalid pin number", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/v2/gpio/-10", body)
 		resRecorder := httptest.NewRecorder()
@@ -259,12 +348,203 @@ func TestCreateHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, resRecorder.Code)
 	})
+
+	t.Run("toggle pin - incorrect direction", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/toggle", nil)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.errorToReturn = gpio.ErrInvalidDirection
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("toggle pin - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/toggle", nil)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.errorToReturn = nil
+		ctrl.valueToReturn = 0
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		assert.JSONEq(t, `{"pin":2,"value":0}`, resRecorder.Body.String())
+	})
+
+	t.Run("start counter - missing edge", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/counter", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"window\" : 500}")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("start counter - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/counter", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"edge\" : \"rising\", \"window\" : 500}")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+	})
+
+	t.Run("start counter - pin claimed by other owner", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/counter", body)
+		req.Header.Set(ownerHeader, "bob")
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"edge\" : \"rising\"}")
+		ctrl.errorToReturn = gpio.ErrPinClaimed
+
+		hndlr.ServeHTTP(resRecorder, req)
+		ctrl.errorToReturn = nil
+
+		assert.Equal(t, http.StatusConflict, resRecorder.Code)
+		assert.Equal(t, "bob", ctrl.ownerGiven)
+	})
+
+	t.Run("stop counter, reset counter and stop capture - pin claimed by other owner", func(t *testing.T) {
+		for _, call := range [][2]string{{"DELETE", "/v2/gpio/2/counter"}, {"POST", "/v2/gpio/2/counter/reset"},
+			{"DELETE", "/v2/gpio/2/capture"}} {
+			req, _ := http.NewRequest(call[0], call[1], body)
+			req.Header.Set(ownerHeader, "bob")
+			resRecorder := httptest.NewRecorder()
+
+			ctrl.ownerGiven = ""
+			ctrl.errorToReturn = gpio.ErrPinClaimed
+
+			hndlr.ServeHTTP(resRecorder, req)
+			ctrl.errorToReturn = nil
+
+			assert.Equal(t, http.StatusConflict, resRecorder.Code, call[1])
+			assert.Equal(t, "bob", ctrl.ownerGiven, call[1])
+		}
+	})
+
+	t.Run("get counter - not running", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/gpio/2/counter", body)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.errorToReturn = gpio.ErrNotRunning
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusNotFound, resRecorder.Code)
+	})
+
+	t.Run("get counter - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/gpio/2/counter", body)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.errorToReturn = nil
+		ctrl.counterToReturn = gpio.CounterState{Pin: 2, Edge: gpio.Rising, Window: 500 * time.Millisecond,
+			Count: 42, Rate: 12.5}
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		assert.JSONEq(t, `{"pin":2,"edge":"rising","window":500,"count":42,"rate":12.5}`, resRecorder.Body.String())
+	})
+
+	t.Run("event stream - invalid pin", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/events?pin=x", body)
+		resRecorder := httptest.NewRecorder()
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("event stream - pin and device events", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v2/events?pin=2", body)
+		resRecorder := httptest.NewRecorder()
+
+		ctrl.errorToReturn = nil
+		at := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+		ctrl.eventsToReturn = make(chan gpio.Event, 3)
+		ctrl.eventsToReturn <- gpio.Event{Pin: 2, Value: 1, Time: at}
+		ctrl.eventsToReturn <- gpio.Event{Pin: 3, Value: 1, Time: at}
+		ctrl.eventsToReturn <- gpio.Event{Pin: gpio.NoPin, Value: 7, Time: at, Device: "knob"}
+		close(ctrl.eventsToReturn)
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		assert.Equal(t, "text/event-stream", resRecorder.Header().Get("Content-Type"))
+		assert.Equal(t, "data: {\"pin\":2,\"value\":1,\"time\":\"2021-05-01T12:00:00Z\"}\n\n"+
+			"data: {\"device\":\"knob\",\"value\":7,\"time\":\"2021-05-01T12:00:00Z\"}\n\n", resRecorder.Body.String())
+	})
+
+	t.Run("trigger capture - missing trigger pin", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/capture/trigger", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"pulse\" : 10}")
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, resRecorder.Code)
+	})
+
+	t.Run("trigger capture - no echo", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/capture/trigger", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"trigger\" : 3}")
+		ctrl.errorToReturn = gpio.ErrTimeout
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, resRecorder.Code)
+	})
+
+	t.Run("trigger capture - trigger claimed by other owner", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/capture/trigger", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"trigger\" : 3}")
+		ctrl.errorToReturn = gpio.ErrPinClaimed
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusConflict, resRecorder.Code)
+	})
+
+	t.Run("trigger capture - correct case", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v2/gpio/2/capture/trigger", body)
+		resRecorder := httptest.NewRecorder()
+
+		body.dataToReturn = []byte("{\"trigger\" : 3, \"pulse\" : 10, \"timeout\" : 50}")
+		ctrl.errorToReturn = nil
+		ctrl.widthToReturn = 580 * time.Microsecond
+
+		hndlr.ServeHTTP(resRecorder, req)
+
+		assert.Equal(t, http.StatusOK, resRecorder.Code)
+		assert.JSONEq(t, `{"pin":2,"trigger":3,"width":580}`, resRecorder.Body.String())
+	})
 }
 
 type controllerStub struct {
-	valueToReturn int
-	errorToReturn error
-	mapToReturn   map[int]gpio.Direction
+	valueToReturn  int
+	errorToReturn  error
+	mapToReturn    map[int]gpio.Direction
+	claimsToReturn map[int]gpio.Claim
+	ownerGiven     string
+	externalPins   []int
+	linesToReturn  []gpio.LineInfo
+	valueErrors    gpio.PinErrors
+
+	counterToReturn gpio.CounterState
+	widthToReturn   time.Duration
+	eventsToReturn  chan gpio.Event
 }
 
 func (cs *controllerStub) SetValue(pin, value int) error {
@@ -279,6 +559,10 @@ func (cs *controllerStub) ExportPin(pin int, mode gpio.Direction) error {
 	return cs.errorToReturn
 }
 
+func (cs *controllerStub) SetDirection(pin int, mode gpio.Direction) error {
+	return cs.errorToReturn
+}
+
 func (cs *controllerStub) UnexportPin(pin int) error {
 	return cs.errorToReturn
 }
@@ -287,6 +571,109 @@ func (cs *controllerStub) ListExportedPins() (map[int]gpio.Direction, error) {
 	return cs.mapToReturn, cs.errorToReturn
 }
 
+func (cs *controllerStub) SetFilter(pin int, filter gpio.Filter) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) Subscribe(pin int) (<-chan gpio.Event, error) {
+	return make(chan gpio.Event), cs.errorToReturn
+}
+
+func (cs *controllerStub) Unsubscribe(pin int, events <-chan gpio.Event) {
+}
+
+func (cs *controllerStub) StartCounter(pin int, edge gpio.Edge, window time.Duration) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) StopCounter(pin int) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) ResetCounter(pin int) (gpio.CounterState, error) {
+	return cs.counterToReturn, cs.errorToReturn
+}
+
+func (cs *controllerStub) GetCounter(pin int) (gpio.CounterState, error) {
+	return cs.counterToReturn, cs.errorToReturn
+}
+
+func (cs *controllerStub) StartCapture(pin int) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) StopCapture(pin int) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) GetCapture(pin int) (gpio.CaptureState, error) {
+	return gpio.CaptureState{Pin: pin}, cs.errorToReturn
+}
+
+func (cs *controllerStub) TriggerAndMeasure(trigger, echo int, pulse, timeout time.Duration) (time.Duration, error) {
+	return cs.widthToReturn, cs.errorToReturn
+}
+
+func (cs *controllerStub) SubscribeAll() <-chan gpio.Event {
+	return cs.eventsToReturn
+}
+
+func (cs *controllerStub) UnsubscribeAll(events <-chan gpio.Event) {
+}
+
+func (cs *controllerStub) Publish(event gpio.Event) {
+}
+
+func (cs *controllerStub) SetValues(values map[int]int) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) GetValues(pins []int) (map[int]int, error) {
+	result := map[int]int{}
+	for _, pin := range pins {
+		if _, exists := cs.valueErrors[pin]; !exists {
+			result[pin] = cs.valueToReturn
+		}
+	}
+	if len(cs.valueErrors) > 0 {
+		return result, cs.valueErrors
+	}
+	return result, cs.errorToReturn
+}
+
+func (cs *controllerStub) Toggle(pin int) (int, error) {
+	return cs.valueToReturn, cs.errorToReturn
+}
+
+func (cs *controllerStub) WithOwner(owner string) gpio.Owned {
+	cs.ownerGiven = owner
+	return cs
+}
+
+func (cs *controllerStub) Claim(pin int, exclusive bool) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) Release(pin int) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) ListClaims() map[int]gpio.Claim {
+	return cs.claimsToReturn
+}
+
+func (cs *controllerStub) Adopt(pin int) error {
+	return cs.errorToReturn
+}
+
+func (cs *controllerStub) ListExternalPins() ([]int, error) {
+	return cs.externalPins, nil
+}
+
+func (cs *controllerStub) Capabilities() ([]gpio.LineInfo, error) {
+	return cs.linesToReturn, cs.errorToReturn
+}
+
 type bodyStub struct {
 	dataToReturn []byte
 }
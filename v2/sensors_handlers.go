@@ -0,0 +1,170 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/markamdev/repico/sensors"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+const thermalUnit = "°C"
+
+type sensorsHandler struct {
+	ctrl sensors.Controller
+}
+
+func (sh *sensorsHandler) getAllZones(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllZones() handler")
+	zones, err := sh.ctrl.ListThermalZones()
+	if err != nil {
+		logrus.Errorln("Failed to list thermal zones:", err)
+		writeSensorsError(wr, err)
+		return
+	}
+	if len(zones) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]thermalZone, 0, len(zones))
+	for _, zone := range zones {
+		result = append(result, newThermalZone(zone))
+	}
+	writeJSON(wr, result)
+}
+
+func (sh *sensorsHandler) getZone(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getZone() handler")
+	number, ok := intParam(wr, req, "zone")
+	if !ok {
+		return
+	}
+
+	zone, err := sh.ctrl.GetThermalZone(number)
+	if err != nil {
+		logrus.Errorf("Failed to read thermal zone '%d': %v\n", number, err)
+		writeSensorsError(wr, err)
+		return
+	}
+	writeJSON(wr, newThermalZone(zone))
+}
+
+func (sh *sensorsHandler) getAllCoolingDevices(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllCoolingDevices() handler")
+	devices, err := sh.ctrl.ListCoolingDevices()
+	if err != nil {
+		logrus.Errorln("Failed to list cooling devices:", err)
+		writeSensorsError(wr, err)
+		return
+	}
+	if len(devices) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]coolingDevice, 0, len(devices))
+	for _, dev := range devices {
+		result = append(result, coolingDevice{Device: dev.Number, Type: dev.Type, State: dev.State, MaxState: dev.MaxState})
+	}
+	writeJSON(wr, result)
+}
+
+func (sh *sensorsHandler) getAllChips(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllChips() handler")
+	chips, err := sh.ctrl.ListChips()
+	if err != nil {
+		logrus.Errorln("Failed to list hwmon chips:", err)
+		writeSensorsError(wr, err)
+		return
+	}
+	if len(chips) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]hwmonChip, 0, len(chips))
+	for _, chip := range chips {
+		result = append(result, newHwmonChip(chip))
+	}
+	writeJSON(wr, result)
+}
+
+func (sh *sensorsHandler) getChip(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getChip() handler")
+	number, ok := intParam(wr, req, "chip")
+	if !ok {
+		return
+	}
+
+	chip, err := sh.ctrl.GetChip(number)
+	if err != nil {
+		logrus.Errorf("Failed to read hwmon chip '%d': %v\n", number, err)
+		writeSensorsError(wr, err)
+		return
+	}
+	writeJSON(wr, newHwmonChip(chip))
+}
+
+func writeSensorsError(wr http.ResponseWriter, err error) {
+	switch err {
+	case sensors.ErrInvalidZone, sensors.ErrInvalidChip:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func newThermalZone(zone sensors.ThermalZone) thermalZone {
+	result := thermalZone{Zone: zone.Number, Type: zone.Type, Temperature: zone.Temperature, Unit: thermalUnit,
+		Trips: make([]tripPoint, 0, len(zone.Trips))}
+	for _, trip := range zone.Trips {
+		result.Trips = append(result.Trips, tripPoint{Type: trip.Type, Temperature: trip.Temperature, Hysteresis: trip.Hysteresis})
+	}
+	return result
+}
+
+func newHwmonChip(chip sensors.Chip) hwmonChip {
+	result := hwmonChip{Chip: chip.Number, Name: chip.Name, Sensors: make([]hwmonSensor, 0, len(chip.Sensors))}
+	for _, sensor := range chip.Sensors {
+		result.Sensors = append(result.Sensors, hwmonSensor{Name: sensor.Name, Label: sensor.Label, Type: sensor.Type,
+			Unit: sensor.Unit, Value: sensor.Value, Thresholds: sensor.Thresholds})
+	}
+	return result
+}
+
+type tripPoint struct {
+	Type        string   `json:"type"`
+	Temperature float64  `json:"temperature"`
+	Hysteresis  *float64 `json:"hysteresis,omitempty"`
+}
+
+type thermalZone struct {
+	Zone        int         `json:"zone"`
+	Type        string      `json:"type"`
+	Temperature float64     `json:"temperature"`
+	Unit        string      `json:"unit"`
+	Trips       []tripPoint `json:"trips"`
+}
+
+type coolingDevice struct {
+	Device   int    `json:"device"`
+	Type     string `json:"type"`
+	State    int    `json:"state"`
+	MaxState int    `json:"max_state"`
+}
+
+type hwmonSensor struct {
+	Name       string             `json:"name"`
+	Label      string             `json:"label,omitempty"`
+	Type       string             `json:"type"`
+	Unit       string             `json:"unit"`
+	Value      float64            `json:"value"`
+	Thresholds map[string]float64 `json:"thresholds,omitempty"`
+}
+
+type hwmonChip struct {
+	Chip    int           `json:"chip"`
+	Name    string        `json:"name"`
+	Sensors []hwmonSensor `json:"sensors"`
+}
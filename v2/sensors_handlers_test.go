@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/sensors"
+	"github.com/markamdev/repico/sysfs/sysfstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensorsHandlers(t *testing.T) {
+	thermalPath := t.TempDir()
+	hwmonPath := t.TempDir()
+	ctrl := sensors.CreateController(thermalPath, hwmonPath)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachSensorsHandlers(subRtr, ctrl)
+
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(""))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/thermal").Code)
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/cooling").Code)
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/hwmon").Code)
+	})
+
+	sysfstest.CreateDir(t, thermalPath, map[string]string{
+		"thermal_zone0/type":              "cpu-thermal",
+		"thermal_zone0/temp":              "51540",
+		"thermal_zone0/trip_point_0_type": "critical",
+		"thermal_zone0/trip_point_0_temp": "90000",
+		"cooling_device0/type":            "cpufreq-cpu0",
+		"cooling_device0/cur_state":       "0",
+		"cooling_device0/max_state":       "2",
+	})
+	sysfstest.CreateDir(t, hwmonPath, map[string]string{
+		"hwmon0/name":            "rpi_volt",
+		"hwmon0/in0_lcrit_alarm": "0",
+		"hwmon1/name":            "cpu_thermal",
+		"hwmon1/temp1_input":     "51540",
+		"hwmon1/temp1_crit":      "90000",
+	})
+
+	t.Run("thermal zones", func(t *testing.T) {
+		zone := `{"zone":0,"type":"cpu-thermal","temperature":51.54,"unit":"°C","trips":[{"type":"critical","temperature":90}]}`
+		res := serve("GET", "/v2/thermal")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, "["+zone+"]", res.Body.String())
+
+		res = serve("GET", "/v2/thermal/0")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, zone, res.Body.String())
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/thermal/1").Code)
+	})
+
+	t.Run("cooling devices", func(t *testing.T) {
+		res := serve("GET", "/v2/cooling")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"device":0,"type":"cpufreq-cpu0","state":0,"max_state":2}]`, res.Body.String())
+	})
+
+	t.Run("hwmon chips", func(t *testing.T) {
+		chip := `{"chip":1,"name":"cpu_thermal","sensors":[{"name":"temp1","type":"temp","unit":"°C","value":51.54,"thresholds":{"crit":90}}]}`
+		res := serve("GET", "/v2/hwmon")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"chip":0,"name":"rpi_volt","sensors":[]},`+chip+`]`, res.Body.String())
+
+		res = serve("GET", "/v2/hwmon/1")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, chip, res.Body.String())
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/hwmon/2").Code)
+	})
+}
@@ -0,0 +1,336 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/markamdev/repico/serial"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+type serialHandler struct {
+	ctrl     serial.Controller
+	upgrader websocket.Upgrader
+}
+
+// getAllPorts returns available ports together with opened ones (with their configuration)
+func (sh *serialHandler) getAllPorts(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllPorts() handler")
+	ports, err := sh.ctrl.ListPorts()
+	if err != nil {
+		logrus.Errorln("Failed to list serial ports:", err)
+		writeSerialError(wr, err)
+		return
+	}
+	opened, err := sh.ctrl.ListOpenedPorts()
+	if err != nil {
+		logrus.Errorln("Failed to list opened serial ports:", err)
+		writeSerialError(wr, err)
+		return
+	}
+	for name := range opened {
+		found := false
+		for _, port := range ports {
+			found = found || port == name
+		}
+		if !found {
+			ports = append(ports, name)
+		}
+	}
+	if len(ports) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	sort.Strings(ports)
+	result := make([]serialPort, 0, len(ports))
+	for _, name := range ports {
+		port := serialPort{Port: name}
+		if cfg, exists := opened[name]; exists {
+			port.setConfig(cfg)
+		}
+		result = append(result, port)
+	}
+	writeJSON(wr, result)
+}
+
+func (sh *serialHandler) openPort(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("openPort() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData serialPortPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Port == nil {
+		logrus.Error("No proper port description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect port description")
+		return
+	}
+
+	err = sh.ctrl.OpenPort(*requestData.Port, requestData.config(serial.DefaultConfig))
+	if err != nil {
+		logrus.Warnf("Failed to open serial port '%s': %v\n", *requestData.Port, err)
+		writeSerialError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *serialHandler) closePort(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("closePort() handler")
+	name := mux.Vars(req)["port"]
+
+	err := sh.ctrl.ClosePort(name)
+	if err != nil {
+		logrus.Errorf("Failed to close serial port '%s': %v\n", name, err)
+		writeSerialError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *serialHandler) getPort(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getPort() handler")
+	name := mux.Vars(req)["port"]
+
+	cfg, err := sh.config(name)
+	if err != nil {
+		logrus.Errorf("Failed to get serial port '%s': %v\n", name, err)
+		writeSerialError(wr, err)
+		return
+	}
+	result := serialPort{Port: name}
+	result.setConfig(cfg)
+	writeJSON(wr, result)
+}
+
+// setPort changes configuration of opened port, fields not given in request are left unchanged
+func (sh *serialHandler) setPort(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setPort() handler")
+	name := mux.Vars(req)["port"]
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData serialPortPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	cfg, err := sh.config(name)
+	if err == nil {
+		err = sh.ctrl.Configure(name, requestData.config(cfg))
+	}
+	if err != nil {
+		logrus.Errorf("Failed to configure serial port '%s': %v\n", name, err)
+		writeSerialError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *serialHandler) request(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("request() handler")
+	name := mux.Vars(req)["port"]
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData serialRequestPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Data == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+	encoding := encodingHex
+	if requestData.Encoding != nil {
+		encoding = *requestData.Encoding
+	}
+	payload, err := decodePayload(*requestData.Data, encoding)
+	if err != nil {
+		logrus.Errorln("Invalid data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid data")
+		return
+	}
+	resp := serial.Response{}
+	if requestData.Delimiter != nil {
+		resp.Delimiter, err = decodePayload(*requestData.Delimiter, encoding)
+		if err != nil {
+			logrus.Errorln("Invalid delimiter:", err)
+			server.WriteMessage(wr, http.StatusBadRequest, "invalid delimiter")
+			return
+		}
+	}
+	if requestData.Timeout != nil {
+		resp.Timeout = time.Duration(*requestData.Timeout) * time.Millisecond
+	}
+	if requestData.MaxLength != nil {
+		resp.MaxLength = *requestData.MaxLength
+	}
+
+	received, err := sh.ctrl.Request(name, payload, resp)
+	if err != nil {
+		logrus.Errorf("Request to serial port '%s' failed: %v\n", name, err)
+		writeSerialError(wr, err)
+		return
+	}
+	result, _ := encodePayload(received, encoding)
+	writeJSON(wr, serialResponse{Port: name, Data: result, Encoding: encoding})
+}
+
+// streamPort upgrades connection to WebSocket, data read from port is sent as binary messages
+// and content of all messages received from client is written to port
+func (sh *serialHandler) streamPort(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("streamPort() handler")
+	name := mux.Vars(req)["port"]
+
+	received, err := sh.ctrl.Subscribe(name)
+	if err != nil {
+		logrus.Errorf("Failed to subscribe serial port '%s': %v\n", name, err)
+		writeSerialError(wr, err)
+		return
+	}
+	defer sh.ctrl.Unsubscribe(name, received)
+
+	conn, err := sh.upgrader.Upgrade(wr, req, nil)
+	if err != nil {
+		// error response is already sent by upgrader
+		logrus.Errorln("WebSocket upgrade failed:", err)
+		return
+	}
+	defer conn.Close()
+
+	go func() {
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				logrus.Debugln("Serial stream closed by client:", err)
+				// closes 'received' channel so sending loop finishes
+				sh.ctrl.Unsubscribe(name, received)
+				return
+			}
+			if len(message) == 0 {
+				continue
+			}
+			err = sh.ctrl.Write(name, message)
+			if err != nil {
+				logrus.Warnf("Failed to write serial port '%s': %v\n", name, err)
+			}
+		}
+	}()
+
+	for data := range received {
+		err = conn.WriteMessage(websocket.BinaryMessage, data)
+		if err != nil {
+			logrus.Debugln("Serial stream writing failed:", err)
+			return
+		}
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "port closed"))
+}
+
+// config returns configuration of opened port
+func (sh *serialHandler) config(name string) (serial.Config, error) {
+	opened, err := sh.ctrl.ListOpenedPorts()
+	if err != nil {
+		return serial.Config{}, err
+	}
+	cfg, exists := opened[name]
+	if !exists {
+		return serial.Config{}, serial.ErrNotOpened
+	}
+	return cfg, nil
+}
+
+func writeSerialError(wr http.ResponseWriter, err error) {
+	switch err {
+	case serial.ErrInvalidPort, serial.ErrInvalidConfig, serial.ErrInvalidValue, serial.ErrAlreadyOpened, serial.ErrNotOpened:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	case serial.ErrTimeout:
+		server.WriteMessage(wr, http.StatusGatewayTimeout, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+// serialPort describes port, configuration is given only for opened ports
+type serialPort struct {
+	Port     string `json:"port"`
+	Opened   bool   `json:"opened"`
+	Baud     int    `json:"baud,omitempty"`
+	DataBits int    `json:"data_bits,omitempty"`
+	Parity   string `json:"parity,omitempty"`
+	StopBits int    `json:"stop_bits,omitempty"`
+}
+
+func (sp *serialPort) setConfig(cfg serial.Config) {
+	sp.Opened = true
+	sp.Baud = cfg.Baud
+	sp.DataBits = cfg.DataBits
+	sp.Parity = serial.ParityToString(cfg.Parity)
+	sp.StopBits = cfg.StopBits
+}
+
+type serialPortPointer struct {
+	Port     *string `json:"port"`
+	Baud     *int    `json:"baud"`
+	DataBits *int    `json:"data_bits"`
+	Parity   *string `json:"parity"`
+	StopBits *int    `json:"stop_bits"`
+}
+
+// config returns configuration with fields given in request replaced
+func (sp serialPortPointer) config(cfg serial.Config) serial.Config {
+	if sp.Baud != nil {
+		cfg.Baud = *sp.Baud
+	}
+	if sp.DataBits != nil {
+		cfg.DataBits = *sp.DataBits
+	}
+	if sp.Parity != nil {
+		cfg.Parity = serial.StringToParity(*sp.Parity)
+	}
+	if sp.StopBits != nil {
+		cfg.StopBits = *sp.StopBits
+	}
+	return cfg
+}
+
+// serialRequestPointer describes data sent to port and expected response, data and delimiter are
+// encoded as hex (default) or base64 string, timeout is given in milliseconds
+type serialRequestPointer struct {
+	Data      *string `json:"data"`
+	Encoding  *string `json:"encoding"`
+	Delimiter *string `json:"delimiter"`
+	Timeout   *int    `json:"timeout"`
+	MaxLength *int    `json:"max_length"`
+}
+
+type serialResponse struct {
+	Port     string `json:"port"`
+	Data     string `json:"data"`
+	Encoding string `json:"encoding"`
+}
@@ -0,0 +1,132 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/markamdev/repico/serial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialHandlers(t *testing.T) {
+	master, ptyName, err := serial.OpenPty()
+	if err != nil {
+		t.Skip("pseudoterminals not available:", err)
+	}
+	defer master.Close()
+	devPath := t.TempDir()
+	name := "ttyUSB0"
+	require.NoError(t, os.Symlink(filepath.Join(serial.PtyPath, ptyName), filepath.Join(devPath, name)))
+	ctrl := serial.CreateController(devPath)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachSerialHandlers(subRtr, ctrl)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list ports", func(t *testing.T) {
+		res := serve("GET", "/v2/serial", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"port":"`+name+`","opened":false}]`, res.Body.String())
+	})
+
+	t.Run("open port", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial", `{"baud":9600}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial", `{"port":"`+name+`","parity":"mark"}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/serial", `{"port":"`+name+`","baud":9600}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial", `{"port":"`+name+`"}`).Code)
+
+		res := serve("GET", "/v2/serial", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"port":"`+name+`","opened":true,"baud":9600,"data_bits":8,"parity":"none","stop_bits":1}]`, res.Body.String())
+	})
+
+	t.Run("configure port", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/serial/"+name, `{"parity":"even","stop_bits":2}`).Code)
+		res := serve("GET", "/v2/serial/"+name, "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"port":"`+name+`","opened":true,"baud":9600,"data_bits":8,"parity":"even","stop_bits":2}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/serial/"+name, `{"baud":1234}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("GET", "/v2/serial/ttyS9", "").Code)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		go func() {
+			buffer := make([]byte, 3)
+			master.Read(buffer)
+			master.Write([]byte("OK\r\nrest"))
+		}()
+		res := serve("POST", "/v2/serial/"+name+"/request", `{"data":"41540d","delimiter":"0d0a","timeout":500}`)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"port":"`+name+`","data":"4f4b0d0a","encoding":"hex"}`, res.Body.String())
+
+		res = serve("POST", "/v2/serial/"+name+"/request", `{"data":"QVQN","encoding":"base64","delimiter":"DQo=","timeout":50}`)
+		assert.Equal(t, http.StatusGatewayTimeout, res.Code)
+		// request without response is still sent to device
+		buffer := make([]byte, 3)
+		_, err = master.Read(buffer)
+		assert.NoError(t, err)
+		assert.Equal(t, "AT\r", string(buffer))
+
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial/"+name+"/request", `{"data":"xyz"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial/"+name+"/request", `{"data":"00","delimiter":"xyz"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial/"+name+"/request", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/serial/ttyS9/request", `{"data":"00"}`).Code)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		srv := httptest.NewServer(hndlr)
+		defer srv.Close()
+
+		_, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v2/serial/ttyS9/stream", nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v2/serial/"+name+"/stream", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// data written by client goes to port
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("ping")))
+		buffer := make([]byte, 4)
+		_, err = master.Read(buffer)
+		assert.NoError(t, err)
+		assert.Equal(t, "ping", string(buffer))
+
+		// data received from port goes to client
+		master.Write([]byte("pong"))
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		received := []byte{}
+		for len(received) < 4 {
+			msgType, data, err := conn.ReadMessage()
+			require.NoError(t, err)
+			assert.Equal(t, websocket.BinaryMessage, msgType)
+			received = append(received, data...)
+		}
+		assert.Equal(t, "pong", string(received))
+
+		// closing port finishes stream
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/serial/"+name, "").Code)
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	})
+
+	t.Run("close port - not opened", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("DELETE", "/v2/serial/"+name, "").Code)
+	})
+}
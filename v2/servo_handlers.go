@@ -0,0 +1,206 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/server"
+	"github.com/markamdev/repico/servo"
+	"github.com/sirupsen/logrus"
+)
+
+type servoHandler struct {
+	mgr servo.Manager
+}
+
+func (sh *servoHandler) addServo(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addServo() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var servoDesc servoConfigPointer
+	err := json.Unmarshal(data, &servoDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if servoDesc.Name == nil || servoDesc.Chip == nil || servoDesc.Channel == nil {
+		logrus.Error("No proper servo description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect servo description")
+		return
+	}
+
+	err = sh.mgr.Add(*servoDesc.Name, servoDesc.toConfig())
+	if err != nil {
+		logrus.Warning("Servo creation error:", err)
+		writeServoError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *servoHandler) deleteServo(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteServo() handler")
+	name := mux.Vars(req)["name"]
+
+	err := sh.mgr.Remove(name)
+	if err != nil {
+		logrus.Errorf("Failed to remove servo '%s': %v\n", name, err)
+		writeServoError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *servoHandler) setServo(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setServo() handler")
+	name := mux.Vars(req)["name"]
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData servoAnglePointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Angle == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	srv, err := sh.mgr.Get(name)
+	if err == nil {
+		err = srv.SetAngle(*requestData.Angle)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to set servo '%s' angle: %v\n", name, err)
+		writeServoError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *servoHandler) getServo(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getServo() handler")
+	name := mux.Vars(req)["name"]
+
+	srv, err := sh.mgr.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to get servo '%s': %v\n", name, err)
+		writeServoError(wr, err)
+		return
+	}
+
+	buffer, err := json.Marshal(servoStatusToJSON(srv.Status()))
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func (sh *servoHandler) getAllServos(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllServos() handler")
+	servos := sh.mgr.List()
+	if len(servos) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]servoStatus, 0, len(servos))
+	for _, st := range servos {
+		result = append(result, servoStatusToJSON(st))
+	}
+
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Error when marshalling servo data:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func writeServoError(wr http.ResponseWriter, err error) {
+	switch err {
+	case servo.ErrNotFound:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case servo.ErrInvalidConfig, servo.ErrInvalidName, servo.ErrInvalidAngle, servo.ErrAlreadyExists:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		writePWMError(wr, err)
+	}
+}
+
+func servoStatusToJSON(st servo.Status) servoStatus {
+	return servoStatus{
+		Name:     st.Name,
+		Chip:     st.Config.Chip,
+		Channel:  st.Config.Channel,
+		MinPulse: st.Config.MinPulse.Microseconds(),
+		MaxPulse: st.Config.MaxPulse.Microseconds(),
+		Travel:   st.Config.Travel,
+		Speed:    st.Config.Speed,
+		Angle:    st.Angle,
+		Target:   st.Target,
+		Moving:   st.Moving,
+	}
+}
+
+// servoConfigPointer describes servo with pulse widths given in microseconds
+type servoConfigPointer struct {
+	Name     *string  `json:"name"`
+	Chip     *int     `json:"chip"`
+	Channel  *int     `json:"channel"`
+	MinPulse *int64   `json:"min_pulse"`
+	MaxPulse *int64   `json:"max_pulse"`
+	Travel   *float64 `json:"travel"`
+	Speed    *float64 `json:"speed"`
+}
+
+func (sc servoConfigPointer) toConfig() servo.Config {
+	cfg := servo.Config{Chip: *sc.Chip, Channel: *sc.Channel}
+	if sc.MinPulse != nil {
+		cfg.MinPulse = time.Duration(*sc.MinPulse) * time.Microsecond
+	}
+	if sc.MaxPulse != nil {
+		cfg.MaxPulse = time.Duration(*sc.MaxPulse) * time.Microsecond
+	}
+	if sc.Travel != nil {
+		cfg.Travel = *sc.Travel
+	}
+	if sc.Speed != nil {
+		cfg.Speed = *sc.Speed
+	}
+	return cfg
+}
+
+type servoStatus struct {
+	Name     string  `json:"name"`
+	Chip     int     `json:"chip"`
+	Channel  int     `json:"channel"`
+	MinPulse int64   `json:"min_pulse"`
+	MaxPulse int64   `json:"max_pulse"`
+	Travel   float64 `json:"travel"`
+	Speed    float64 `json:"speed"`
+	Angle    float64 `json:"angle"`
+	Target   float64 `json:"target"`
+	Moving   bool    `json:"moving"`
+}
+
+type servoAnglePointer struct {
+	Angle *float64 `json:"angle"`
+}
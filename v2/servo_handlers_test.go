@@ -0,0 +1,65 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/servo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServoHandlers(t *testing.T) {
+	ctrl := &pwmControllerStub{}
+	mgr := servo.CreateManager(ctrl)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachServoHandlers(subRtr, mgr)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list servos - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/servos", "").Code)
+	})
+
+	t.Run("add servo", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/servos", ``).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/servos", `{"name":"pan","chip":0}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/servos",
+			`{"name":"pan","chip":0,"channel":0,"min_pulse":1000,"max_pulse":30000}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/servos", `{"name":"pan","chip":0,"channel":0,"travel":90}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/servos", `{"name":"pan","chip":0,"channel":1}`).Code)
+	})
+
+	t.Run("set angle", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/servos/pan", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/servos/pan", `{"angle":91}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("PATCH", "/v2/servos/tilt", `{"angle":10}`).Code)
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/servos/pan", `{"angle":45}`).Code)
+
+		res := serve("GET", "/v2/servos/pan", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"name":"pan","chip":0,"channel":0,"min_pulse":1000,"max_pulse":2000,"travel":90,
+			"speed":0,"angle":45,"target":45,"moving":false}`, res.Body.String())
+	})
+
+	t.Run("list servos", func(t *testing.T) {
+		res := serve("GET", "/v2/servos", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"name":"pan"`)
+	})
+
+	t.Run("delete servo", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/servos/pan", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/v2/servos/pan", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/servos/pan", "").Code)
+	})
+}
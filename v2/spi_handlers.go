@@ -0,0 +1,202 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/markamdev/repico/bus"
+	"github.com/markamdev/repico/server"
+	"github.com/markamdev/repico/spi"
+	"github.com/sirupsen/logrus"
+)
+
+type spiHandler struct {
+	ctrl spi.Controller
+}
+
+func (sh *spiHandler) getAllDevices(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllDevices() handler")
+	devices, err := sh.ctrl.ListDevices()
+	if err != nil {
+		logrus.Errorln("Failed to list SPI devices:", err)
+		writeSPIError(wr, err)
+		return
+	}
+	if len(devices) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]spiDevice, 0, len(devices))
+	for _, dev := range devices {
+		result = append(result, spiDevice{Bus: dev.Bus, ChipSelect: dev.ChipSelect})
+	}
+	writeJSON(wr, result)
+}
+
+func (sh *spiHandler) getDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getDevice() handler")
+	dev, ok := spiParams(wr, req)
+	if !ok {
+		return
+	}
+
+	cfg, err := sh.ctrl.GetConfig(dev)
+	if err != nil {
+		logrus.Errorf("Failed to get configuration of SPI device '%s': %v\n", spi.DeviceToString(dev), err)
+		writeSPIError(wr, err)
+		return
+	}
+	writeJSON(wr, spiConfig{Bus: dev.Bus, ChipSelect: dev.ChipSelect, Mode: int(cfg.Mode), BitsPerWord: cfg.BitsPerWord, SpeedHz: cfg.SpeedHz})
+}
+
+func (sh *spiHandler) setDevice(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("setDevice() handler")
+	dev, ok := spiParams(wr, req)
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData spiConfigPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Mode == nil && requestData.BitsPerWord == nil && requestData.SpeedHz == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+
+	// fields not given in request are left unchanged
+	cfg, err := sh.ctrl.GetConfig(dev)
+	if err != nil {
+		logrus.Errorf("Failed to get configuration of SPI device '%s': %v\n", spi.DeviceToString(dev), err)
+		writeSPIError(wr, err)
+		return
+	}
+	if requestData.Mode != nil {
+		cfg.Mode = bus.SPIMode(*requestData.Mode)
+	}
+	if requestData.BitsPerWord != nil {
+		cfg.BitsPerWord = *requestData.BitsPerWord
+	}
+	if requestData.SpeedHz != nil {
+		cfg.SpeedHz = *requestData.SpeedHz
+	}
+
+	err = sh.ctrl.Configure(dev, cfg)
+	if err != nil {
+		logrus.Errorf("Failed to configure SPI device '%s': %v\n", spi.DeviceToString(dev), err)
+		writeSPIError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *spiHandler) transfer(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("transfer() handler")
+	dev, ok := spiParams(wr, req)
+	if !ok {
+		return
+	}
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData spiTransferPointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if requestData.Data == nil {
+		logrus.Debug("Incomplete request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid incomplete request data")
+		return
+	}
+	encoding := encodingHex
+	if requestData.Encoding != nil {
+		encoding = *requestData.Encoding
+	}
+	payload, err := decodePayload(*requestData.Data, encoding)
+	if err != nil {
+		logrus.Errorln("Invalid data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid data")
+		return
+	}
+
+	received, err := sh.ctrl.Transfer(dev, payload)
+	if err != nil {
+		logrus.Errorf("Failed to transfer data with SPI device '%s': %v\n", spi.DeviceToString(dev), err)
+		writeSPIError(wr, err)
+		return
+	}
+	result, _ := encodePayload(received, encoding)
+	writeJSON(wr, spiTransfer{Bus: dev.Bus, ChipSelect: dev.ChipSelect, Data: result, Encoding: encoding})
+}
+
+// spiParams returns device selected in path as "bus.cs"
+func spiParams(wr http.ResponseWriter, req *http.Request) (spi.Device, bool) {
+	number, ok := intParam(wr, req, "bus")
+	if !ok {
+		return spi.Device{}, false
+	}
+	cs, ok := intParam(wr, req, "cs")
+	if !ok {
+		return spi.Device{}, false
+	}
+	return spi.Device{Bus: number, ChipSelect: cs}, true
+}
+
+func writeSPIError(wr http.ResponseWriter, err error) {
+	switch err {
+	case spi.ErrInvalidDevice:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case spi.ErrInvalidValue:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+type spiDevice struct {
+	Bus        int `json:"bus"`
+	ChipSelect int `json:"chip_select"`
+}
+
+type spiConfig struct {
+	Bus         int    `json:"bus"`
+	ChipSelect  int    `json:"chip_select"`
+	Mode        int    `json:"mode"`
+	BitsPerWord int    `json:"bits_per_word"`
+	SpeedHz     uint32 `json:"speed_hz"`
+}
+
+type spiConfigPointer struct {
+	Mode        *int    `json:"mode"`
+	BitsPerWord *int    `json:"bits_per_word"`
+	SpeedHz     *uint32 `json:"speed_hz"`
+}
+
+// spiTransfer describes data received during transfer, encoded the same way as sent data
+type spiTransfer struct {
+	Bus        int    `json:"bus"`
+	ChipSelect int    `json:"chip_select"`
+	Data       string `json:"data"`
+	Encoding   string `json:"encoding"`
+}
+
+// spiTransferPointer describes data to be sent, encoded as hex (default) or base64 string
+type spiTransferPointer struct {
+	Data     *string `json:"data"`
+	Encoding *string `json:"encoding"`
+}
@@ -0,0 +1,74 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/spi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPIHandlers(t *testing.T) {
+	ctrl := spi.CreateController(t.TempDir())
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachSPIHandlers(subRtr, ctrl)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list devices - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/spi", "").Code)
+	})
+
+	ctrl.AddDevice(spi.Device{Bus: 0, ChipSelect: 1}, spi.CreateLoopback())
+
+	t.Run("list devices", func(t *testing.T) {
+		res := serve("GET", "/v2/spi", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `[{"bus":0,"chip_select":1}]`, res.Body.String())
+	})
+
+	t.Run("configure device", func(t *testing.T) {
+		res := serve("GET", "/v2/spi/0.1", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"bus":0,"chip_select":1,"mode":0,"bits_per_word":8,"speed_hz":1000000}`, res.Body.String())
+
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/spi/0.1", `{"mode":3,"speed_hz":500000}`).Code)
+		res = serve("GET", "/v2/spi/0.1", "")
+		assert.JSONEq(t, `{"bus":0,"chip_select":1,"mode":3,"bits_per_word":8,"speed_hz":500000}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/spi/0.1", `{"mode":4}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/spi/0.1", `{}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/v2/spi/0.0", "").Code)
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		res := serve("POST", "/v2/spi/0.1/transfer", `{"data":"9f0000"}`)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"bus":0,"chip_select":1,"data":"9f0000","encoding":"hex"}`, res.Body.String())
+
+		res = serve("POST", "/v2/spi/0.1/transfer", `{"data":"3q2+7w==","encoding":"base64"}`)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"bus":0,"chip_select":1,"data":"3q2+7w==","encoding":"base64"}`, res.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/spi/0.1/transfer", `{"data":"xyz"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/spi/0.1/transfer", `{"data":"00","encoding":"ascii"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/spi/0.1/transfer", `{"data":""}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("POST", "/v2/spi/1.0/transfer", `{"data":"00"}`).Code)
+	})
+
+	t.Run("transfer - partial word", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("PATCH", "/v2/spi/0.1", `{"bits_per_word":16}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/spi/0.1/transfer", `{"data":"9f0000"}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/spi/0.1/transfer", `{"data":"9f000000"}`).Code)
+	})
+}
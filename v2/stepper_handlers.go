@@ -0,0 +1,276 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/gpio"
+	"github.com/markamdev/repico/server"
+	"github.com/markamdev/repico/stepper"
+	"github.com/sirupsen/logrus"
+)
+
+type stepperHandler struct {
+	mgr stepper.Manager
+}
+
+func (sh *stepperHandler) addStepper(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("addStepper() handler")
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var stepperDesc stepperConfigPointer
+	err := json.Unmarshal(data, &stepperDesc)
+	if err != nil {
+		logrus.Error("Unable to unmarshal request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if stepperDesc.Name == nil || stepperDesc.Driver == nil || stepperDesc.MaxSpeed == nil {
+		logrus.Error("No proper stepper description in body")
+		server.WriteMessage(wr, http.StatusBadRequest, "incorrect stepper description")
+		return
+	}
+
+	err = sh.mgr.Add(*stepperDesc.Name, stepperDesc.toConfig())
+	if err != nil {
+		logrus.Warning("Stepper creation error:", err)
+		writeStepperError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *stepperHandler) deleteStepper(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("deleteStepper() handler")
+	name := mux.Vars(req)["name"]
+
+	err := sh.mgr.Remove(name)
+	if err != nil {
+		logrus.Errorf("Failed to remove stepper '%s': %v\n", name, err)
+		writeStepperError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *stepperHandler) moveStepper(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("moveStepper() handler")
+	name := mux.Vars(req)["name"]
+
+	data, ok := readBody(wr, req)
+	if !ok {
+		return
+	}
+
+	var requestData stepperMovePointer
+	err := json.Unmarshal(data, &requestData)
+	if err != nil {
+		logrus.Errorln("Failed to unmarshall request data:", err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if (requestData.Position == nil) == (requestData.Steps == nil) {
+		logrus.Debug("Incorrect request data")
+		server.WriteMessage(wr, http.StatusBadRequest, "either position or steps has to be given")
+		return
+	}
+
+	stp, err := sh.mgr.Get(name)
+	if err == nil {
+		if requestData.Position != nil {
+			err = stp.MoveTo(*requestData.Position)
+		} else {
+			err = stp.Move(*requestData.Steps)
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Failed to move stepper '%s': %v\n", name, err)
+		writeStepperError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusAccepted)
+}
+
+func (sh *stepperHandler) homeStepper(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("homeStepper() handler")
+	name := mux.Vars(req)["name"]
+
+	stp, err := sh.mgr.Get(name)
+	if err == nil {
+		err = stp.Home()
+	}
+	if err != nil {
+		logrus.Errorf("Failed to home stepper '%s': %v\n", name, err)
+		writeStepperError(wr, err)
+		return
+	}
+	wr.WriteHeader(http.StatusAccepted)
+}
+
+func (sh *stepperHandler) stopStepper(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("stopStepper() handler")
+	name := mux.Vars(req)["name"]
+
+	stp, err := sh.mgr.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to stop stepper '%s': %v\n", name, err)
+		writeStepperError(wr, err)
+		return
+	}
+	stp.Stop()
+	wr.WriteHeader(http.StatusOK)
+}
+
+func (sh *stepperHandler) getStepper(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getStepper() handler")
+	name := mux.Vars(req)["name"]
+
+	stp, err := sh.mgr.Get(name)
+	if err != nil {
+		logrus.Errorf("Failed to get stepper '%s': %v\n", name, err)
+		writeStepperError(wr, err)
+		return
+	}
+
+	buffer, err := json.Marshal(stepperStatusToJSON(stp.Status()))
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func (sh *stepperHandler) getAllSteppers(wr http.ResponseWriter, req *http.Request) {
+	logrus.Debugln("getAllSteppers() handler")
+	steppers := sh.mgr.List()
+	if len(steppers) == 0 {
+		wr.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := make([]stepperStatus, 0, len(steppers))
+	for _, st := range steppers {
+		result = append(result, stepperStatusToJSON(st))
+	}
+
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Error when marshalling stepper data:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+func writeStepperError(wr http.ResponseWriter, err error) {
+	switch err {
+	case stepper.ErrNotFound:
+		server.WriteMessage(wr, http.StatusNotFound, err.Error())
+	case stepper.ErrBusy, gpio.ErrExternalPin, gpio.ErrPinClaimed:
+		server.WriteMessage(wr, http.StatusConflict, err.Error())
+	case stepper.ErrInvalidConfig, stepper.ErrInvalidName, stepper.ErrAlreadyExists, stepper.ErrNoLimitSwitch,
+		gpio.ErrInvalidPin, gpio.ErrInvalidDirection:
+		server.WriteMessage(wr, http.StatusBadRequest, err.Error())
+	default:
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+	}
+}
+
+func stepperStatusToJSON(st stepper.Status) stepperStatus {
+	result := stepperStatus{
+		Name:         st.Name,
+		Driver:       stepper.DriverToString(st.Config.Driver),
+		MaxSpeed:     st.Config.MaxSpeed,
+		Acceleration: st.Config.Acceleration,
+		Position:     st.Position,
+		Target:       st.Target,
+		Moving:       st.Moving,
+		Homing:       st.Homing,
+		Homed:        st.Homed,
+	}
+	if st.Config.Driver == stepper.StepDir {
+		result.StepPin = &st.Config.StepPin
+		result.DirPin = &st.Config.DirPin
+	} else {
+		result.PhasePins = st.Config.PhasePins
+	}
+	if st.Config.LimitPin != stepper.NoPin {
+		result.LimitPin = &st.Config.LimitPin
+	}
+	if st.LastError != nil {
+		result.LastError = st.LastError.Error()
+	}
+	return result
+}
+
+// stepperConfigPointer describes stepper with speeds in steps per second
+type stepperConfigPointer struct {
+	Name         *string  `json:"name"`
+	Driver       *string  `json:"driver"`
+	StepPin      *int     `json:"step_pin"`
+	DirPin       *int     `json:"dir_pin"`
+	PhasePins    []int    `json:"phase_pins"`
+	LimitPin     *int     `json:"limit_pin"`
+	LimitActive  *int     `json:"limit_active"`
+	MaxSpeed     *float64 `json:"max_speed"`
+	Acceleration *float64 `json:"acceleration"`
+	HomeSpeed    *float64 `json:"home_speed"`
+}
+
+func (sc stepperConfigPointer) toConfig() stepper.Config {
+	cfg := stepper.Config{
+		Driver:    stepper.StringToDriver(*sc.Driver),
+		StepPin:   stepper.NoPin,
+		DirPin:    stepper.NoPin,
+		PhasePins: sc.PhasePins,
+		LimitPin:  stepper.NoPin,
+		MaxSpeed:  *sc.MaxSpeed,
+	}
+	if sc.StepPin != nil {
+		cfg.StepPin = *sc.StepPin
+	}
+	if sc.DirPin != nil {
+		cfg.DirPin = *sc.DirPin
+	}
+	if sc.LimitPin != nil {
+		cfg.LimitPin = *sc.LimitPin
+	}
+	if sc.LimitActive != nil {
+		cfg.LimitActive = *sc.LimitActive
+	}
+	if sc.Acceleration != nil {
+		cfg.Acceleration = *sc.Acceleration
+	}
+	if sc.HomeSpeed != nil {
+		cfg.HomeSpeed = *sc.HomeSpeed
+	}
+	return cfg
+}
+
+type stepperStatus struct {
+	Name         string  `json:"name"`
+	Driver       string  `json:"driver"`
+	StepPin      *int    `json:"step_pin,omitempty"`
+	DirPin       *int    `json:"dir_pin,omitempty"`
+	PhasePins    []int   `json:"phase_pins,omitempty"`
+	LimitPin     *int    `json:"limit_pin,omitempty"`
+	MaxSpeed     float64 `json:"max_speed"`
+	Acceleration float64 `json:"acceleration"`
+	Position     int     `json:"position"`
+	Target       int     `json:"target"`
+	Moving       bool    `json:"moving"`
+	Homing       bool    `json:"homing"`
+	Homed        bool    `json:"homed"`
+	LastError    string  `json:"last_error,omitempty"`
+}
+
+type stepperMovePointer struct {
+	Position *int `json:"position"`
+	Steps    *int `json:"steps"`
+}
@@ -0,0 +1,83 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/stepper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepperHandlers(t *testing.T) {
+	ctrl := &controllerStub{}
+	mgr := stepper.CreateManager(ctrl)
+
+	hndlr := mux.NewRouter()
+	subRtr := hndlr.PathPrefix("/v2").Subrouter()
+	AttachStepperHandlers(subRtr, mgr)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		resRecorder := httptest.NewRecorder()
+		hndlr.ServeHTTP(resRecorder, req)
+		return resRecorder
+	}
+
+	t.Run("list steppers - no content", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve("GET", "/v2/steppers", "").Code)
+	})
+
+	t.Run("add stepper", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/steppers", ``).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/steppers", `{"name":"x","driver":"step-dir"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/steppers",
+			`{"name":"x","driver":"step-dir","step_pin":1,"dir_pin":1,"max_speed":100}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/steppers",
+			`{"name":"x","driver":"step-dir","step_pin":1,"dir_pin":2,"max_speed":100000,"acceleration":1000000}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/steppers",
+			`{"name":"x","driver":"step-dir","step_pin":3,"dir_pin":4,"max_speed":100}`).Code)
+	})
+
+	t.Run("move stepper", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/steppers/x", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("PATCH", "/v2/steppers/x", `{"position":1,"steps":1}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("PATCH", "/v2/steppers/y", `{"steps":1}`).Code)
+		assert.Equal(t, http.StatusAccepted, serve("PATCH", "/v2/steppers/x", `{"position":10}`).Code)
+
+		stp, err := mgr.Get("x")
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return !stp.Status().Moving }, time.Second, time.Millisecond)
+
+		res := serve("GET", "/v2/steppers/x", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"name":"x","driver":"step-dir","step_pin":1,"dir_pin":2,"max_speed":100000,
+			"acceleration":1000000,"position":10,"target":10,"moving":false,"homing":false,"homed":false}`, res.Body.String())
+	})
+
+	t.Run("home without limit switch", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/v2/steppers/x/home", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("POST", "/v2/steppers/y/home", "").Code)
+	})
+
+	t.Run("stop stepper", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, serve("PATCH", "/v2/steppers/x", `{"steps":100000000}`).Code)
+		assert.Equal(t, http.StatusConflict, serve("PATCH", "/v2/steppers/x", `{"steps":1}`).Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/steppers/x/stop", "").Code)
+		assert.Equal(t, http.StatusOK, serve("POST", "/v2/steppers/x/stop", "").Code, "repeated stop should be harmless")
+		assert.Equal(t, http.StatusNotFound, serve("POST", "/v2/steppers/y/stop", "").Code)
+
+		res := serve("GET", "/v2/steppers", "")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"last_error":"stopped"`)
+	})
+
+	t.Run("delete stepper", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/v2/steppers/x", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/v2/steppers/x", "").Code)
+	})
+}
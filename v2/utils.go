@@ -0,0 +1,85 @@
+package v2
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/markamdev/repico/server"
+	"github.com/sirupsen/logrus"
+)
+
+const maxBodySize = 64 * 1024
+
+// encodings of binary payloads in JSON strings
+const (
+	encodingHex    = "hex"
+	encodingBase64 = "base64"
+)
+
+var errInvalidEncoding = errors.New("invalid encoding")
+
+// readBody returns request body content or writes error response and returns false
+func readBody(wr http.ResponseWriter, req *http.Request) ([]byte, bool) {
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil && err != io.EOF {
+		logrus.Errorln("Failed to read request body:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "body reading error")
+		return nil, false
+	}
+	if len(data) == 0 {
+		logrus.Warnln("Empty request body")
+		server.WriteMessage(wr, http.StatusBadRequest, "empty request body")
+		return nil, false
+	}
+	return data, true
+}
+
+// intParam returns non-negative integer path parameter or writes error response and returns false
+func intParam(wr http.ResponseWriter, req *http.Request, name string) (int, bool) {
+	value, err := strconv.Atoi(mux.Vars(req)[name])
+	if err != nil || value < 0 {
+		logrus.Errorf("Invalid '%s' parameter: %v\n", name, err)
+		server.WriteMessage(wr, http.StatusBadRequest, "invalid "+name+" selection")
+		return 0, false
+	}
+	return value, true
+}
+
+// writeJSON marshals result and writes it with 200 status
+func writeJSON(wr http.ResponseWriter, result interface{}) {
+	buffer, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorln("Failed to marshal result:", err)
+		server.WriteMessage(wr, http.StatusInternalServerError, "unexpected internal error")
+		return
+	}
+	server.WriteResponse(wr, http.StatusOK, buffer)
+}
+
+// decodePayload returns data given as hex or base64 string
+func decodePayload(data, encoding string) ([]byte, error) {
+	switch encoding {
+	case encodingHex:
+		return hex.DecodeString(data)
+	case encodingBase64:
+		return base64.StdEncoding.DecodeString(data)
+	}
+	return nil, errInvalidEncoding
+}
+
+// encodePayload returns data as hex or base64 string
+func encodePayload(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case encodingHex:
+		return hex.EncodeToString(data), nil
+	case encodingBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return "", errInvalidEncoding
+}